@@ -1,13 +1,11 @@
 package bayesian
 
 import (
-	"encoding/gob"
 	"errors"
-	"io"
-	"math"
-	"os"
-	"path/filepath"
+	"fmt"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // defaultProb is the tiny non-zero probability that a word
@@ -17,6 +15,32 @@ const defaultProb = 0.00000000001
 // ErrUnderflow is returned when an underflow is detected.
 var ErrUnderflow = errors.New("possible underflow detected")
 
+// ErrTooFewClasses is returned when creating a classifier with
+// fewer than two classes.
+var ErrTooFewClasses = errors.New("provide at least two classes")
+
+// ErrDuplicateClass is returned when creating a classifier with
+// classes that are not unique.
+var ErrDuplicateClass = errors.New("classes must be unique")
+
+// ErrClassNotFound is returned when referring to a class the
+// classifier does not have.
+var ErrClassNotFound = errors.New("class not found")
+
+// ErrNotLearned is returned when unlearning a document that the
+// classifier cannot have learned for the class.
+var ErrNotLearned = errors.New("document was not learned for class")
+
+// ErrConverted is returned when modifying the counts of a TF-IDF
+// classifier that was already converted with
+// ConvertTermsFreqToTfIdf.
+var ErrConverted = errors.New("TF-IDF classifier already converted")
+
+// ErrNoEligibleClass is returned when classifying a document
+// while no class can be scored, because none is eligible, see
+// WithMinClassDocs, or none has learned anything.
+var ErrNoEligibleClass = errors.New("no class is eligible for scoring")
+
 // Class defines a class that the classifier will filter:
 // C = {C_1, ..., C_n}. You should define your classes as a
 // set of constants, for example as follows:
@@ -32,95 +56,87 @@ type Class string
 // Classifier implements the Naive Bayesian Classifier.
 type Classifier struct {
 	Classes         []Class
-	learned         int   // docs learned
-	seen            int32 // docs seen
+	learned         int // docs learned
+	stats           *UsageStats
 	datas           map[Class]*classData
 	tfIdf           bool
 	DidConvertTfIdf bool // we can't classify a TF-IDF classifier if we haven't yet
 	// called ConverTermsFreqToTfIdf
-}
-
-// serializableClassifier represents a container for
-// Classifier objects whose fields are modifiable by
-// reflection and are therefore writeable by gob.
-type serializableClassifier struct {
-	Classes         []Class
-	Learned         int
-	Seen            int
-	Datas           map[Class]*classData
-	TfIdf           bool
-	DidConvertTfIdf bool
-}
-
-// classData holds the frequency data for words in a
-// particular class. In the future, we may replace this
-// structure with a trie-like structure for more
-// efficient storage.
-type classData struct {
-	Freqs   map[string]float64
-	FreqTfs map[string][]float64
-	Total   int
-}
-
-// newClassData creates a new empty classData node.
-func newClassData() *classData {
-	return &classData{
-		Freqs:   make(map[string]float64),
-		FreqTfs: make(map[string][]float64),
-	}
-}
-
-// getWordProb returns P(W|C_j) -- the probability of seeing
-// a particular word W in a document of this class.
-func (d *classData) getWordProb(word string) float64 {
-	value, ok := d.Freqs[word]
-	if !ok {
-		return defaultProb
-	}
-	return float64(value) / float64(d.Total)
-}
-
-// getWordsProb returns P(D|C_j) -- the probability of seeing
-// this set of words in a document of this class.
-//
-// Note that words should not be empty, and this method of
-// calulation is prone to underflow if there are many words
-// and their individual probabilties are small.
-func (d *classData) getWordsProb(words []string) (prob float64) {
-	prob = 1
-	for _, word := range words {
-		prob *= d.getWordProb(word)
-	}
-	return
+	incrementalTfIdf    bool
+	tfIdfTf             TfWeighting
+	tfIdfIdf            IdfWeighting
+	maxTermCount        int  // max occurrences of a term per learned doc, 0 is unlimited
+	binarized           bool // count each word once per scored doc
+	lengthModel         bool
+	bm25                bool
+	bm25K1              float64
+	bm25B               float64
+	bm25Words           float64 // total length of the docs learned with BM25
+	bm25Docs            int     // number of docs learned with BM25
+	ngramMin            int
+	ngramMax            int     // 0 is words only
+	smoothing           float64 // additive smoothing alpha, 0 is none
+	complementNB        bool
+	jmLambda            float64 // weight of the class in Jelinek-Mercer smoothing, 0 is none
+	dirichletMu         float64 // pseudo-count of Dirichlet smoothing, 0 is none
+	minWordLength       int
+	stopwords           map[string]bool
+	maxWordContribution float64 // max |log P| contribution per unique word, 0 is unlimited
+	namespaceSep        string
+	namespaceCaps       map[string]float64 // max |log P| contribution per namespace
+	blocklists          map[Class]map[string]bool
+	allowlists          map[Class]map[string]bool
+	adaptiveDefaultProb bool
+	expander            Expander
+	estimator           Estimator
+	minClassDocs        int
+	idfWeighting        bool
+	docFreqs            map[string]int // number of learned docs containing each word
+	idfDocs             int            // number of learned docs counted in docFreqs
+	wordTimestamps      bool
+	autoDecay           float64 // decay factor applied before each learned doc
+	decayScale          float64 // multiplies the stored word counts and class totals, 0 is 1
+	windowed            bool
+	windowSize          int           // max remembered docs, 0 is unlimited
+	windowTTL           time.Duration // max age of remembered docs, 0 is unlimited
+	window              []windowEntry // remembered docs, oldest first
+	minDocFreq          int           // min docs containing a TF-IDF term
+	maxDocFreq          float64       // max fraction of docs containing a TF-IDF term
+	priorCounts         map[Class]int // population doc counts for priors
+	priors              map[Class]float64
+	uniformPriors       bool
+	documentPriors      bool
+	samplingWeights     map[Class]float64 // sampling rate of each class in training
+	classWeights        map[Class]float64
+	biases              map[Class]float64  // added to log scores
+	featureWeights      map[string]float64 // multiply word log probabilities
+	archived            map[Class]bool
+	autoCreateClasses   bool
+	minProbMargin       float64
+	skipOOV             bool
+	temperature         float64 // divides log scores before normalization, 0 is 1
+	tieBreak            TieBreak
+	tieEpsilon          float64     // max difference of tied scores
+	tieBreaker          *tieBreaker // random source of TieBreakRandom
+	mismatchMonitor     *MismatchMonitor
+	monitored           int64            // docs counted for mismatch sampling
+	now                 func() time.Time // clock for word timestamps
+	corpus              atomic.Value     // cached *corpus
+	shardVocab          int              // vocabulary size of the sharded model, 0 if not a shard
+	float32Counts       bool             // write counts and snapshot at float32 precision
+	maxMistakes         int              // size of the misclassification reservoir
+	mistakesMu          sync.Mutex
+	mistakes            []Mistake // ring buffer of retained mistakes
+	mistakesNext        int       // index of the oldest mistake once the ring is full
 }
 
 // NewClassifierTfIdf returns a new classifier. The classes provided
 // should be at least 2 in number and unique, or this method will
 // panic.
 func NewClassifierTfIdf(classes ...Class) (c *Classifier) {
-	n := len(classes)
-
-	// check size
-	if n < 2 {
-		panic("provide at least two classes")
-	}
-
-	// check uniqueness
-	check := make(map[Class]bool, n)
-	for _, class := range classes {
-		check[class] = true
-	}
-	if len(check) != n {
-		panic("classes must be unique")
-	}
-	// create the classifier
-	c = &Classifier{
-		Classes: classes,
-		datas:   make(map[Class]*classData, n),
-		tfIdf:   true,
-	}
-	for _, class := range classes {
-		c.datas[class] = newClassData()
+	c, err := NewClassifierTfIdfSafe(classes...)
+	if err != nil {
+		panic(err.Error())
 	}
 	return
 }
@@ -129,20 +145,42 @@ func NewClassifierTfIdf(classes ...Class) (c *Classifier) {
 // should be at least 2 in number and unique, or this method will
 // panic.
 func NewClassifier(classes ...Class) (c *Classifier) {
-	n := len(classes)
-
-	// check size
-	if n < 2 {
-		panic("provide at least two classes")
+	c, err := NewClassifierSafe(classes...)
+	if err != nil {
+		panic(err.Error())
 	}
+	return
+}
 
-	// check uniqueness
-	check := make(map[Class]bool, n)
-	for _, class := range classes {
-		check[class] = true
-	}
-	if len(check) != n {
-		panic("classes must be unique")
+// NewClassifierTfIdfSafe works the same as NewClassifierTfIdf,
+// but returns an error instead of panicking if the classes are
+// invalid, see NewClassifierOpts.
+func NewClassifierTfIdfSafe(classes ...Class) (c *Classifier, err error) {
+	return NewClassifierOpts(classes, WithTfIdf())
+}
+
+// NewClassifierSafe works the same as NewClassifier, but returns
+// an error instead of panicking if the classes are invalid, see
+// NewClassifierOpts, which is more convenient when they come
+// from configuration.
+func NewClassifierSafe(classes ...Class) (c *Classifier, err error) {
+	return NewClassifierOpts(classes)
+}
+
+// NewClassifierOpts returns a new classifier of the classes,
+// configured with the options, for instance
+//
+//    NewClassifierOpts(classes, WithTfIdf(), WithStopwords(words))
+//
+// It returns an error wrapping ErrTooFewClasses if there are
+// fewer than two classes, and one wrapping ErrDuplicateClass,
+// naming the class, if they are not unique; test for them with
+// errors.Is. It also returns an error if an option has an
+// invalid value, as documented by the option.
+func NewClassifierOpts(classes []Class, opts ...Option) (c *Classifier, err error) {
+	n := len(classes)
+	if err = checkClasses(classes); err != nil {
+		return nil, err
 	}
 	// create the classifier
 	c = &Classifier{
@@ -150,55 +188,53 @@ func NewClassifier(classes ...Class) (c *Classifier) {
 		datas:           make(map[Class]*classData, n),
 		tfIdf:           false,
 		DidConvertTfIdf: false,
+		stats:           new(UsageStats),
+		now:             time.Now,
 	}
 	for _, class := range classes {
 		c.datas[class] = newClassData()
 	}
+	c.SetOptions(opts...)
+	if err = c.checkOptions(); err != nil {
+		return nil, err
+	}
 	return
 }
 
-// NewClassifierFromFile loads an existing classifier from
-// file. The classifier was previously saved with a call
-// to c.WriteToFile(string).
-func NewClassifierFromFile(name string) (c *Classifier, err error) {
-	file, err := os.Open(name)
-	if err != nil {
-		return nil, err
+// checkOptions returns an error if the options set on the
+// classifier have invalid values.
+func (c *Classifier) checkOptions() error {
+	if c.maxMistakes < 0 {
+		return fmt.Errorf("mistake reservoir size must not be negative, got %d", c.maxMistakes)
 	}
-	defer file.Close()
-
-	return NewClassifierFromReader(file)
+	if c.bm25 && !(c.bm25K1 >= 0) {
+		return fmt.Errorf("BM25 k1 must not be negative, got %v", c.bm25K1)
+	}
+	if c.bm25 && !(c.bm25B >= 0 && c.bm25B <= 1) {
+		return fmt.Errorf("BM25 b must be in [0, 1], got %v", c.bm25B)
+	}
+	return nil
 }
 
-// NewClassifierFromReader: This actually does the deserializing of a Gob encoded classifier
-func NewClassifierFromReader(r io.Reader) (c *Classifier, err error) {
-	dec := gob.NewDecoder(r)
-	w := new(serializableClassifier)
-	err = dec.Decode(w)
-
-	return &Classifier{w.Classes, w.Learned, int32(w.Seen), w.Datas, w.TfIdf, w.DidConvertTfIdf}, err
-}
+// checkClasses returns an error unless there are at least two
+// classes and they are unique.
+func checkClasses(classes []Class) error {
+	n := len(classes)
 
-// getPriors returns the prior probabilities for the
-// classes provided -- P(C_j).
-//
-// TODO: There is a way to smooth priors, currently
-// not implemented here.
-func (c *Classifier) getPriors() (priors []float64) {
-	n := len(c.Classes)
-	priors = make([]float64, n, n)
-	sum := 0
-	for index, class := range c.Classes {
-		total := c.datas[class].Total
-		priors[index] = float64(total)
-		sum += total
+	// check size
+	if n < 2 {
+		return fmt.Errorf("%w, got %d", ErrTooFewClasses, n)
 	}
-	if sum != 0 {
-		for i := 0; i < n; i++ {
-			priors[i] /= float64(sum)
+
+	// check uniqueness
+	check := make(map[Class]bool, n)
+	for _, class := range classes {
+		if check[class] {
+			return fmt.Errorf("%w: %q given more than once", ErrDuplicateClass, class)
 		}
+		check[class] = true
 	}
-	return
+	return nil
 }
 
 // Learned returns the number of documents ever learned
@@ -210,9 +246,15 @@ func (c *Classifier) Learned() int {
 // Seen returns the number of documents ever classified
 // in the lifetime of this classifier.
 func (c *Classifier) Seen() int {
-	return int(atomic.LoadInt32(&c.seen))
+	return c.stats.Seen()
 }
 
+// SetOptions applies the given options to the classifier.
+func (c *Classifier) SetOptions(opts ...Option) {
+	for _, opt := range opts {
+		opt(c)
+	}
+}
 
 // IsTfIdf returns true if we are a classifier of type TfIdf
 func (c *Classifier) IsTfIdf() bool {
@@ -225,319 +267,11 @@ func (c *Classifier) WordCount() (result []int) {
 	result = make([]int, len(c.Classes))
 	for inx, class := range c.Classes {
 		data := c.datas[class]
-		result[inx] = data.Total
-	}
-	return
-}
-
-// Observe should be used when word-frequencies have been already been learned
-// externally (e.g., hadoop)
-func (c *Classifier) Observe(word string, count int, which Class) {
-	data := c.datas[which]
-	data.Freqs[word] += float64(count)
-	data.Total += count
-}
-
-// Learn will accept new training documents for
-// supervised learning.
-func (c *Classifier) Learn(document []string, which Class) {
-
-	// If we are a tfidf classifier we first need to get terms as
-	// terms frequency and store that to work out the idf part later
-	// in ConvertToIDF().
-	if c.tfIdf {
-		if c.DidConvertTfIdf {
-			panic("Cannot call ConvertTermsFreqToTfIdf more than once. Reset and relearn to reconvert.")
-		}
-
-		// Term Frequency: word count in document / document length
-		docTf := make(map[string]float64)
-		for _, word := range document {
-			docTf[word]++
-		}
-
-		docLen := float64(len(document))
-
-		for wIndex, wCount := range docTf {
-			docTf[wIndex] = wCount / docLen
-			// add the TF sample, after training we can get IDF values.
-			c.datas[which].FreqTfs[wIndex] = append(c.datas[which].FreqTfs[wIndex], docTf[wIndex])
-		}
-
-	}
-
-	data := c.datas[which]
-	for _, word := range document {
-		data.Freqs[word]++
-		data.Total++
-	}
-	c.learned++
-}
-
-// ConvertTermsFreqToTfIdf uses all the TF samples for the class and converts
-// them to TF-IDF https://en.wikipedia.org/wiki/Tf%E2%80%93idf
-// once we have finished learning all the classes and have the totals.
-func (c *Classifier) ConvertTermsFreqToTfIdf() {
-
-	if c.DidConvertTfIdf {
-		panic("Cannot call ConvertTermsFreqToTfIdf more than once. Reset and relearn to reconvert.")
-	}
-
-	for className := range c.datas {
-
-		for wIndex := range c.datas[className].FreqTfs {
-			tfIdfAdder := float64(0)
-
-			for tfSampleIndex := range c.datas[className].FreqTfs[wIndex] {
-
-				// we always want a possitive TF-IDF score.
-				tf := c.datas[className].FreqTfs[wIndex][tfSampleIndex]
-				c.datas[className].FreqTfs[wIndex][tfSampleIndex] = math.Log1p(tf) * math.Log1p(float64(c.learned)/float64(c.datas[className].Total))
-				tfIdfAdder += c.datas[className].FreqTfs[wIndex][tfSampleIndex]
-			}
-			// convert the 'counts' to TF-IDF's
-			c.datas[className].Freqs[wIndex] = tfIdfAdder
-		}
-
-	}
-
-	// sanity check
-	c.DidConvertTfIdf = true
-
-}
-
-// LogScores produces "log-likelihood"-like scores that can
-// be used to classify documents into classes.
-//
-// The value of the score is proportional to the likelihood,
-// as determined by the classifier, that the given document
-// belongs to the given class. This is true even when scores
-// returned are negative, which they will be (since we are
-// taking logs of probabilities).
-//
-// The index j of the score corresponds to the class given
-// by c.Classes[j].
-//
-// Additionally returned are "inx" and "strict" values. The
-// inx corresponds to the maximum score in the array. If more
-// than one of the scores holds the maximum values, then
-// strict is false.
-//
-// Unlike c.Probabilities(), this function is not prone to
-// floating point underflow and is relatively safe to use.
-func (c *Classifier) LogScores(document []string) (scores []float64, inx int, strict bool) {
-	if c.tfIdf && !c.DidConvertTfIdf {
-		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling LogScores.")
-	}
-
-	n := len(c.Classes)
-	scores = make([]float64, n, n)
-	priors := c.getPriors()
-
-	// calculate the score for each class
-	for index, class := range c.Classes {
-		data := c.datas[class]
-		// c is the sum of the logarithms
-		// as outlined in the refresher
-		score := math.Log(priors[index])
-		for _, word := range document {
-			score += math.Log(data.getWordProb(word))
-		}
-		scores[index] = score
-	}
-	inx, strict = findMax(scores)
-	atomic.AddInt32(&c.seen, 1)
-	return scores, inx, strict
-}
-
-// ProbScores works the same as LogScores, but delivers
-// actual probabilities as discussed above. Note that float64
-// underflow is possible if the word list contains too
-// many words that have probabilities very close to 0.
-//
-// Notes on underflow: underflow is going to occur when you're
-// trying to assess large numbers of words that you have
-// never seen before. Depending on the application, this
-// may or may not be a concern. Consider using SafeProbScores()
-// instead.
-func (c *Classifier) ProbScores(doc []string) (scores []float64, inx int, strict bool) {
-	if c.tfIdf && !c.DidConvertTfIdf {
-		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling ProbScores.")
-	}
-	n := len(c.Classes)
-	scores = make([]float64, n, n)
-	priors := c.getPriors()
-	sum := float64(0)
-	// calculate the score for each class
-	for index, class := range c.Classes {
-		data := c.datas[class]
-		// c is the sum of the logarithms
-		// as outlined in the refresher
-		score := priors[index]
-		for _, word := range doc {
-			score *= data.getWordProb(word)
-		}
-		scores[index] = score
-		sum += score
-	}
-	for i := 0; i < n; i++ {
-		scores[i] /= sum
-	}
-	inx, strict = findMax(scores)
-	atomic.AddInt32(&c.seen, 1)
-	return scores, inx, strict
-}
-
-// SafeProbScores works the same as ProbScores, but is
-// able to detect underflow in those cases where underflow
-// results in the reverse classification. If an underflow is detected,
-// this method returns an ErrUnderflow, allowing the user to deal with it as
-// necessary. Note that underflow, under certain rare circumstances,
-// may still result in incorrect probabilities being returned,
-// but this method guarantees that all error-less invokations
-// are properly classified.
-//
-// Underflow detection is more costly because it also
-// has to make additional log score calculations.
-func (c *Classifier) SafeProbScores(doc []string) (scores []float64, inx int, strict bool, err error) {
-	if c.tfIdf && !c.DidConvertTfIdf {
-		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling SafeProbScores.")
-	}
-
-	n := len(c.Classes)
-	scores = make([]float64, n, n)
-	logScores := make([]float64, n, n)
-	priors := c.getPriors()
-	sum := float64(0)
-	// calculate the score for each class
-	for index, class := range c.Classes {
-		data := c.datas[class]
-		// c is the sum of the logarithms
-		// as outlined in the refresher
-		score := priors[index]
-		logScore := math.Log(priors[index])
-		for _, word := range doc {
-			p := data.getWordProb(word)
-			score *= p
-			logScore += math.Log(p)
-		}
-		scores[index] = score
-		logScores[index] = logScore
-		sum += score
-	}
-	for i := 0; i < n; i++ {
-		scores[i] /= sum
-	}
-	inx, strict = findMax(scores)
-	logInx, logStrict := findMax(logScores)
-
-	// detect underflow -- the size
-	// relation between scores and logScores
-	// must be preserved or something is wrong
-	if inx != logInx || strict != logStrict {
-		err = ErrUnderflow
-	}
-	atomic.AddInt32(&c.seen, 1)
-	return scores, inx, strict, err
-}
-
-// WordFrequencies returns a matrix of word frequencies that currently
-// exist in the classifier for each class state for the given input
-// words. In other words, if you obtain the frequencies
-//
-//    freqs := c.WordFrequencies(/* [j]string */)
-//
-// then the expression freq[i][j] represents the frequency of the j-th
-// word within the i-th class.
-func (c *Classifier) WordFrequencies(words []string) (freqMatrix [][]float64) {
-	n, l := len(c.Classes), len(words)
-	freqMatrix = make([][]float64, n)
-	for i := range freqMatrix {
-		arr := make([]float64, l)
-		data := c.datas[c.Classes[i]]
-		for j := range arr {
-			arr[j] = data.getWordProb(words[j])
-		}
-		freqMatrix[i] = arr
+		result[inx] = int(data.Total * c.scale())
 	}
 	return
 }
 
-// WordsByClass returns a map of words and their probability of
-// appearing in the given class.
-func (c *Classifier) WordsByClass(class Class) (freqMap map[string]float64) {
-	freqMap = make(map[string]float64)
-	for word, cnt := range c.datas[class].Freqs {
-		freqMap[word] = float64(cnt) / float64(c.datas[class].Total)
-	}
-
-	return freqMap
-}
-
-
-// WriteToFile serializes this classifier to a file.
-func (c *Classifier) WriteToFile(name string) (err error) {
-	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	return c.WriteTo(file)
-}
-
-// WriteClassesToFile writes all classes to files.
-func (c *Classifier) WriteClassesToFile(rootPath string) (err error) {
-	for name := range c.datas {
-		c.WriteClassToFile(name, rootPath)
-	}
-	return
-}
-
-// WriteClassToFile writes a single class to file.
-func (c *Classifier) WriteClassToFile(name Class, rootPath string) (err error) {
-	data := c.datas[name]
-	fileName := filepath.Join(rootPath, string(name))
-	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	enc := gob.NewEncoder(file)
-	err = enc.Encode(data)
-	return
-}
-
-
-// WriteTo serializes this classifier to GOB and write to Writer.
-func (c *Classifier) WriteTo(w io.Writer) (err error) {
-	enc := gob.NewEncoder(w)
-	err = enc.Encode(&serializableClassifier{c.Classes, c.learned, int(c.seen), c.datas, c.tfIdf, c.DidConvertTfIdf})
-
-	return
-}
-
-// ReadClassFromFile loads existing class data from a
-// file.
-func (c *Classifier) ReadClassFromFile(class Class, location string) (err error) {
-	fileName := filepath.Join(location, string(class))
-	file, err := os.Open(fileName)
-
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	dec := gob.NewDecoder(file)
-	w := new(classData)
-	err = dec.Decode(w)
-
-	c.learned++
-	c.datas[class] = w
-	return
-}
-
 // findMax finds the maximum of a set of scores; if the
 // maximum is strict -- that is, it is the single unique
 // maximum from the set -- then strict has return value
@@ -2,7 +2,7 @@ package bayesian
 
 import "testing"
 import "fmt"
-import "os"
+import "errors"
 
 const (
 	Good Class = "good"
@@ -11,7 +11,7 @@ const (
 
 func Assert(t *testing.T, condition bool, args ...interface{}) {
 	if !condition {
-		t.Fatal(args)
+		t.Fatal(args...)
 	}
 }
 
@@ -180,59 +180,6 @@ func TestLogScores(t *testing.T) {
 	Assert(t, c.WordCount()[0] == 3)
 }
 
-func TestGobs(t *testing.T) {
-	c := NewClassifier(Good, Bad)
-	c.Learn([]string{"tall", "handsome", "rich"}, Good)
-	err := c.WriteToFile("test.ser")
-	Assert(t, err == nil, "could not write:", err)
-	d, err := NewClassifierFromFile("test.ser")
-	Assert(t, err == nil, "could not read:", err)
-	fmt.Printf("%v\n", d)
-	scores, _, _ := d.LogScores([]string{"a", "b", "c"})
-	println(scores)
-	data := d.datas[Good]
-	Assert(t, data.Total == 3)
-	Assert(t, data.getWordProb("tall") == float64(1)/float64(3), "tall")
-	Assert(t, data.getWordProb("rich") == float64(1)/float64(3), "rich")
-	Assert(t, d.Learned() == 1)
-	count := d.WordCount()
-	Assert(t, count[0] == 3)
-	Assert(t, count[1] == 0)
-	Assert(t, d.Seen() == 1)
-	// remove the file
-	err = os.Remove("test.ser")
-	Assert(t, err == nil, "could not remove test file:", err)
-}
-
-func TestClassByFile(t *testing.T) {
-	c := NewClassifier(Good, Bad)
-	c.Learn([]string{"tall", "handsome", "rich"}, Good)
-	err := c.WriteClassesToFile(".")
-	Assert(t, err == nil, "could not write class:", err)
-
-	d := NewClassifier(Good, Bad)
-	err = d.ReadClassFromFile(Good, ".")
-	Assert(t, err == nil, "could not read:", err)
-	fmt.Printf("%v\n", d)
-	scores, _, _ := d.LogScores([]string{"a", "b", "c"})
-	println(scores)
-	data := d.datas[Good]
-	Assert(t, data.Total == 3)
-	Assert(t, data.getWordProb("tall") == float64(1)/float64(3), "tall")
-	Assert(t, data.getWordProb("rich") == float64(1)/float64(3), "rich")
-	Assert(t, d.Learned() == 1, "learned")
-	count := d.WordCount()
-
-	Assert(t, count[0] == 3)
-	Assert(t, count[1] == 0)
-	Assert(t, d.Seen() == 1)
-	// remove the file
-	err = os.Remove("good")
-	Assert(t, err == nil, "could not remove test file:", err)
-	err = os.Remove("bad")
-	Assert(t, err == nil, "could not remove test file:", err)
-}
-
 func TestFreqMatrixConstruction(t *testing.T) {
 	c := NewClassifier(Good, Bad)
 	freqs := c.WordFrequencies([]string{"a", "b"})
@@ -244,114 +191,30 @@ func TestFreqMatrixConstruction(t *testing.T) {
 	}
 }
 
-func TestTfIdClassifier_SanityChecks(t *testing.T) {
-	c := NewClassifierTfIdf(Good, Bad)
-	Assert(t, c.IsTfIdf() == true)
-
-	c.Learn([]string{"tall", "handsome", "rich"}, Good)
-
-	defer func() {
-		if err := recover(); err != nil {
-			// we are good
-		}
-	}()
-	c.LogScores([]string{"a", "b", "c"})
-	Assert(t, false, "Should have panicked:Need to run ConvertTermsFreqToTfIdf() first..", c)
-
-}
-
-func TestTfIdClassifier_Tf_Checks(t *testing.T) {
-	c := NewClassifierTfIdf(Good, Bad)
-	Assert(t, c.IsTfIdf() == true)
-
-	c.Learn([]string{"tall", "handsome", "rich"}, Good)
-	c.Learn([]string{"tall", "blonde"}, Good)
-	c.Learn([]string{"tall"}, Good)
-
-	data := c.datas[Good]
-	// Total words seen in training.
-	Assert(t, data.Total == 6)
-
-	// Plain old counts for words.
-	Assert(t, data.Freqs["tall"] == 3)
-	Assert(t, data.Freqs["blonde"] == 1)
-
-	// Check for term frequency's per 'document' (tall)
-	Assert(t, data.FreqTfs["tall"][0] == float64(0.3333333333333333))
-	Assert(t, data.FreqTfs["tall"][1] == float64(0.5))
-	Assert(t, data.FreqTfs["tall"][2] == float64(1))
-
-	// Check for term frequency's per 'document' (blonde)
-	Assert(t, data.FreqTfs["blonde"][0] == float64(0.5))
-
-}
-
-func TestTfIdClassifier_ConvertToTfIdf(t *testing.T) {
-	c := NewClassifierTfIdf(Good, Bad)
-	Assert(t, c.IsTfIdf() == true)
-
-	c.Learn([]string{"tall", "handsome", "rich"}, Good)
-	c.Learn([]string{"tall", "blonde"}, Good)
-	c.Learn([]string{"tall"}, Good)
-
-	// Now we convert the TF's to Tf/Idf
-	// We can only this after we have learned all the documents and classes.
-	// We can add more learning afterwards but need to call ConvertToTfIdf() again before
-	// we can predict classes.
-	c.ConvertTermsFreqToTfIdf()
-
-	data := c.datas[Good]
-
-	// Tf-Idf after we have converted the tf's
-	Assert(t, data.Freqs["tall"] == float64(0.5620939930012151))
-	Assert(t, data.Freqs["blonde"] == float64(0.16440195389316542))
-	Assert(t, data.Freqs["notseen"] == float64(0))
-	Assert(t, data.FreqTfs["tall"][0] == float64(0.11664504260744213))
-	Assert(t, data.FreqTfs["tall"][1] == float64(0.16440195389316542))
-	Assert(t, data.FreqTfs["tall"][2] == float64(0.28104699650060755))
-
-}
-
-func TestTfIdClassifier_CheckForDoubleConvert(t *testing.T) {
-
-	c := NewClassifierTfIdf(Good, Bad)
-	Assert(t, c.IsTfIdf() == true)
-
-	c.Learn([]string{"tall", "handsome", "rich"}, Good)
-	c.Learn([]string{"tall", "blonde"}, Good)
-	c.Learn([]string{"tall"}, Good)
-
-	// We can only call ConverToTdfIdf once per learning cycle (cumulative counts).
-	c.ConvertTermsFreqToTfIdf()
-
-	defer func() {
-		if err := recover(); err != nil {
-			// we are good
-		}
-	}()
-	c.ConvertTermsFreqToTfIdf()
-	Assert(t, false, "Should have panicked:Can only run ConvertTermsFreqToTfIdf() once after a learning cycle.", c)
-
-}
-
-func TestTfIdClassifier_LogScore(t *testing.T) {
-	c := NewClassifierTfIdf(Good, Bad)
-	Assert(t, c.IsTfIdf() == true)
-
-	c.Learn([]string{"tall", "handsome", "rich"}, Good)
-	c.Learn([]string{"tall", "blonde"}, Good)
-	c.Learn([]string{"tall"}, Good)
-	c.Learn([]string{"fat"}, Bad)
-	c.Learn([]string{"short", "poor"}, Bad)
-
-	c.ConvertTermsFreqToTfIdf()
-
-	score, likely, strict := c.LogScores([]string{"the", "tall", "man"})
-
-	Assert(t, score[0] == float64(-53.028113582945196))
-	Assert(t, score[0] > score[1], "Class 'Good' should be closer to 0 than Class 'Bad' - both will be negative") // this is good
-	Assert(t, likely == 0, "Class should be 'Good'")
-	Assert(t, strict == true, "No tie's")
-	fmt.Printf("%#v", score)
-
+func TestNewClassifierSafe(t *testing.T) {
+	_, err := NewClassifierSafe(Good)
+	Assert(t, errors.Is(err, ErrTooFewClasses), err)
+	Assert(t, err.Error() == "provide at least two classes, got 1", err)
+	_, err = NewClassifierTfIdfSafe(Good, Bad, Good)
+	Assert(t, errors.Is(err, ErrDuplicateClass), err)
+	Assert(t, err.Error() == `classes must be unique: "good" given more than once`, err)
+	c, err := NewClassifierTfIdfSafe(Good, Bad)
+	Assert(t, err == nil && c.IsTfIdf(), err)
+	c, err = NewClassifierSafe(Good, Bad)
+	Assert(t, err == nil && !c.IsTfIdf() && len(c.Classes) == 2, err)
+}
+
+func TestNewClassifierOpts(t *testing.T) {
+	_, err := NewClassifierOpts([]Class{Good}, WithTfIdf())
+	Assert(t, errors.Is(err, ErrTooFewClasses), err)
+	c, err := NewClassifierOpts([]Class{Good, Bad}, WithTfIdf(), WithMinWordLength(3), WithStopwords([]string{"the"}))
+	Assert(t, err == nil && c.IsTfIdf(), err)
+
+	c, _ = NewClassifierOpts([]Class{Good, Bad}, WithMinWordLength(3), WithStopwords([]string{"the"}))
+	c.Learn([]string{"the", "tall", "man", "is", "rich"}, Good)
+	c.Learn([]string{"a", "poor", "man"}, Bad)
+	Assert(t, c.datas[Good].Total == 3 && c.datas[Bad].Total == 2, c.datas[Good].Freqs)
+	with, _, _ := c.LogScores([]string{"the", "tall", "is"})
+	without, _, _ := c.LogScores([]string{"tall"})
+	Assert(t, with[0] == without[0] && with[1] == without[1], with, without)
 }
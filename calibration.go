@@ -0,0 +1,118 @@
+package bayesian
+
+import "math"
+
+// Calibration maps the probabilities of a classifier to
+// calibrated ones, with a separate one-vs-rest calibration
+// curve for each class, so that minority classes are calibrated
+// as well as the majority ones. Each curve is a histogram of
+// Bins equal-width bins of the predicted probability of the
+// class, holding the fraction of the documents in the bin that
+// actually were of the class.
+type Calibration struct {
+	Classes []Class
+	Bins    int
+	Curves  [][]float64 // Curves[j][b] is the rate of class j in bin b
+}
+
+// Calibrate fits a Calibration with the given number of bins
+// from held-out documents and their expected classes, given by
+// expected at the same index. The rates are smoothed towards
+// the probabilities predicted in each bin, so that sparsely
+// populated bins are not extreme.
+func (c *Classifier) Calibrate(docs [][]string, expected []Class, bins int) *Calibration {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling Calibrate.")
+	}
+	if len(docs) != len(expected) {
+		panic("provide a class for each document")
+	}
+	if bins < 1 {
+		panic("provide at least one bin")
+	}
+	n := len(c.Classes)
+	hits := make([][]float64, n)
+	sums := make([][]float64, n)
+	counts := make([][]float64, n)
+	for j := range c.Classes {
+		hits[j] = make([]float64, bins)
+		sums[j] = make([]float64, bins)
+		counts[j] = make([]float64, bins)
+	}
+	for k, doc := range docs {
+		for j, p := range softmax(c.tempered(c.logScores(doc))) {
+			b := bin(p, bins)
+			counts[j][b]++
+			sums[j][b] += p
+			if c.Classes[j] == expected[k] {
+				hits[j][b]++
+			}
+		}
+	}
+	cal := &Calibration{
+		Classes: append([]Class(nil), c.Classes...),
+		Bins:    bins,
+		Curves:  make([][]float64, n),
+	}
+	for j := range c.Classes {
+		cal.Curves[j] = make([]float64, bins)
+		for b := 0; b < bins; b++ {
+			// one pseudo-document at the mean predicted probability
+			prior := (float64(b) + 0.5) / float64(bins)
+			if counts[j][b] > 0 {
+				prior = sums[j][b] / counts[j][b]
+			}
+			cal.Curves[j][b] = (hits[j][b] + prior) / (counts[j][b] + 1)
+		}
+	}
+	return cal
+}
+
+// Apply returns the calibrated probabilities of the classes for
+// the probabilities predicted by the classifier, as returned by
+// ProbScores, normalized to sum to 1.
+func (cal *Calibration) Apply(probs []float64) []float64 {
+	result := make([]float64, len(probs))
+	sum := float64(0)
+	for j, p := range probs {
+		result[j] = cal.Curves[j][bin(p, cal.Bins)]
+		sum += result[j]
+	}
+	if sum > 0 {
+		for j := range result {
+			result[j] /= sum
+		}
+	}
+	return result
+}
+
+// bin returns the bin of the probability among n equal-width
+// bins.
+func bin(p float64, n int) int {
+	b := int(p * float64(n))
+	if b >= n {
+		b = n - 1
+	}
+	if b < 0 {
+		b = 0
+	}
+	return b
+}
+
+// softmax converts log scores into probabilities like probs,
+// but shifts them by their maximum first to avoid underflow.
+func softmax(logScores []float64) []float64 {
+	max := math.Inf(-1)
+	for _, s := range logScores {
+		max = math.Max(max, s)
+	}
+	if math.IsInf(max, -1) {
+		// no class was scored
+		return make([]float64, len(logScores))
+	}
+	shifted := make([]float64, len(logScores))
+	for i, s := range logScores {
+		shifted[i] = s - max
+	}
+	return probs(shifted)
+}
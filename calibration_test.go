@@ -0,0 +1,22 @@
+package bayesian
+
+import "testing"
+import "math"
+
+func TestCalibrate(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+
+	// "tall" documents are confidently Good, but half are Bad
+	docs := [][]string{{"tall"}, {"tall"}, {"tall"}, {"tall"}, {"poor"}, {"poor"}}
+	expected := []Class{Good, Bad, Good, Bad, Bad, Bad}
+	cal := c.Calibrate(docs, expected, 4)
+	Assert(t, len(cal.Curves) == 2 && len(cal.Curves[0]) == 4)
+
+	probs, _, _ := c.ProbScores([]string{"tall"})
+	Assert(t, probs[0] > 0.99, probs)
+	calibrated := cal.Apply(probs)
+	Assert(t, math.Abs(calibrated[0]+calibrated[1]-1) < 1e-12, calibrated)
+	Assert(t, calibrated[0] < 0.7 && calibrated[0] > 0.4, calibrated)
+}
@@ -0,0 +1,186 @@
+package bayesian
+
+import (
+	"fmt"
+	"math"
+)
+
+// AddClass adds a new class to the classifier, without training
+// data. It returns ErrDuplicateClass if there already is such a
+// class.
+func (c *Classifier) AddClass(class Class) error {
+	if _, ok := c.datas[class]; ok {
+		return ErrDuplicateClass
+	}
+	// copy, as the classes may share an array with the caller
+	c.Classes = append(c.Classes[:len(c.Classes):len(c.Classes)], class)
+	c.datas[class] = newClassData()
+	c.invalidate()
+	return nil
+}
+
+// checkClass returns ErrClassNotFound if the classifier does
+// not have the class, unless it adds it as configured with
+// WithAutoCreateClasses.
+func (c *Classifier) checkClass(class Class) error {
+	if _, ok := c.datas[class]; ok {
+		return nil
+	}
+	if c.autoCreateClasses {
+		return c.AddClass(class)
+	}
+	return ErrClassNotFound
+}
+
+// ensureClass works like checkClass, but panics if the class is
+// not found.
+func (c *Classifier) ensureClass(class Class) {
+	if err := c.checkClass(class); err != nil {
+		panic(fmt.Sprintf("%v: %q", err, class))
+	}
+}
+
+// RenameClass renames a class, keeping its training data, its
+// configuration, its usage statistics and its position in
+// c.Classes. It returns ErrClassNotFound if there is no class
+// old, and ErrDuplicateClass if there already is a class new.
+func (c *Classifier) RenameClass(old, new Class) error {
+	data, ok := c.datas[old]
+	if !ok {
+		return ErrClassNotFound
+	}
+	if _, ok := c.datas[new]; ok {
+		return ErrDuplicateClass
+	}
+	// copy, as the classes may share an array with the caller
+	c.Classes = append([]Class(nil), c.Classes...)
+	for i, class := range c.Classes {
+		if class == old {
+			c.Classes[i] = new
+		}
+	}
+	delete(c.datas, old)
+	c.datas[new] = data
+	for i := range c.window {
+		if c.window[i].class == old {
+			c.window[i].class = new
+		}
+	}
+	renameList(c.blocklists, old, new)
+	renameList(c.allowlists, old, new)
+	if prior, ok := c.priors[old]; ok {
+		delete(c.priors, old)
+		c.priors[new] = prior
+	}
+	if cnt, ok := c.priorCounts[old]; ok {
+		delete(c.priorCounts, old)
+		c.priorCounts[new] = cnt
+	}
+	if rate, ok := c.samplingWeights[old]; ok {
+		delete(c.samplingWeights, old)
+		c.samplingWeights[new] = rate
+	}
+	if c.archived[old] {
+		delete(c.archived, old)
+		c.archived[new] = true
+	}
+	if weight, ok := c.classWeights[old]; ok {
+		delete(c.classWeights, old)
+		c.classWeights[new] = weight
+	}
+	if bias, ok := c.biases[old]; ok {
+		delete(c.biases, old)
+		c.biases[new] = bias
+	}
+	c.stats.renameClass(old, new)
+	return nil
+}
+
+// renameList moves the word list of the class old in lists to
+// the class new.
+func renameList(lists map[Class]map[string]bool, old, new Class) {
+	if list, ok := lists[old]; ok {
+		delete(lists, old)
+		lists[new] = list
+	}
+}
+
+// Reset discards all the training data of the classifier,
+// keeping its classes and configuration, so that it can be
+// retrained from scratch. This also makes a TF-IDF classifier
+// learnable again. Usage statistics are kept.
+func (c *Classifier) Reset() {
+	for _, class := range c.Classes {
+		c.datas[class] = newClassData()
+	}
+	c.learned = 0
+	c.window = nil
+	c.decayScale = 0
+	c.DidConvertTfIdf = false
+	c.docFreqs = nil
+	c.idfDocs = 0
+	c.bm25Words = 0
+	c.bm25Docs = 0
+	c.invalidate()
+}
+
+// ResetClass discards the training data of a single class. The
+// documents learned for the class are no longer counted as
+// learned, nor in the average document length of WithBM25. The
+// document frequencies of WithIdfWeighting are not kept per
+// class, so they still count the documents of the class until
+// the classifier is Reset. It returns ErrClassNotFound if there
+// is no such class.
+func (c *Classifier) ResetClass(class Class) error {
+	data, ok := c.datas[class]
+	if !ok {
+		return ErrClassNotFound
+	}
+	c.learned -= data.Docs
+	if c.bm25 {
+		c.bm25Docs -= data.Docs
+		c.bm25Words = math.Max(c.bm25Words-data.Length, 0)
+		if c.bm25Docs <= 0 {
+			c.bm25Docs = 0
+			c.bm25Words = 0
+		}
+	}
+	c.datas[class] = newClassData()
+	c.forgetClass(class)
+	c.invalidate()
+	return nil
+}
+
+// ArchiveClass removes the class from classification, keeping
+// its training data, until it is restored with
+// c.UnarchiveClass, for instance for seasonal categories. While
+// archived, the class scores -Inf (and a probability of 0), and
+// takes no share of the prior probabilities. It returns
+// ErrClassNotFound if the classifier does not have the class.
+func (c *Classifier) ArchiveClass(class Class) error {
+	if _, ok := c.datas[class]; !ok {
+		return ErrClassNotFound
+	}
+	if c.archived == nil {
+		c.archived = make(map[Class]bool)
+	}
+	c.archived[class] = true
+	return nil
+}
+
+// UnarchiveClass restores a class archived with c.ArchiveClass.
+// It returns ErrClassNotFound if the classifier does not have
+// the class.
+func (c *Classifier) UnarchiveClass(class Class) error {
+	if _, ok := c.datas[class]; !ok {
+		return ErrClassNotFound
+	}
+	delete(c.archived, class)
+	return nil
+}
+
+// IsArchived returns true if the class is archived, see
+// c.ArchiveClass.
+func (c *Classifier) IsArchived(class Class) bool {
+	return c.archived[class]
+}
@@ -0,0 +1,111 @@
+package bayesian
+
+import "testing"
+
+func TestRenameClass(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.SetClassBlocklist(Bad, "poor")
+
+	err := c.RenameClass("Ugly", "Worse")
+	Assert(t, err == ErrClassNotFound, err)
+	err = c.RenameClass(Bad, Good)
+	Assert(t, err == ErrDuplicateClass, err)
+
+	err = c.RenameClass(Bad, "worse")
+	Assert(t, err == nil, err)
+	Assert(t, c.Classes[1] == "worse" && c.datas["worse"].Total == 3)
+	Assert(t, c.blocklists["worse"]["poor"])
+	_, likely, _ := c.LogScores([]string{"ugly"})
+	Assert(t, likely == 1)
+	Assert(t, c.Stats().Predictions()["worse"] == 1 && c.Stats().Predictions()[Bad] == 0)
+
+	// the classes given to the classifier are not changed
+	classes := []Class{Good, Bad}
+	d, _ := NewClassifierOpts(classes)
+	d.SetPriorCounts(map[Class]int{Good: 1, Bad: 3})
+	Assert(t, d.RenameClass(Bad, "worse") == nil)
+	Assert(t, classes[1] == Bad && d.Classes[1] == "worse")
+	Assert(t, d.priorCounts["worse"] == 3 && d.getPriors()[1] == 0.75, d.priorCounts)
+}
+
+func TestReset(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.Learn([]string{"poor"}, Bad)
+	c.ConvertTermsFreqToTfIdf()
+
+	err := c.ResetClass("Ugly")
+	Assert(t, err == ErrClassNotFound, err)
+	err = c.ResetClass(Bad)
+	Assert(t, err == nil, err)
+	Assert(t, c.Learned() == 1 && c.datas[Bad].Total == 0 && c.datas[Good].Total == 3)
+
+	c.Reset()
+	Assert(t, c.Learned() == 0 && c.datas[Good].Total == 0 && !c.DidConvertTfIdf)
+	c.Learn([]string{"tall"}, Good)
+	c.Learn([]string{"poor"}, Bad)
+	c.ConvertTermsFreqToTfIdf()
+	_, likely, _ := c.LogScores([]string{"poor"})
+	Assert(t, likely == 1)
+
+	d, _ := NewClassifierOpts([]Class{Good, Bad}, WithBM25(1.2, 0.75))
+	d.Learn([]string{"tall", "rich"}, Good)
+	d.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	Assert(t, d.ResetClass(Bad) == nil)
+	Assert(t, d.bm25Docs == 1 && d.bm25Words == 2, d.bm25Docs, d.bm25Words)
+}
+
+func TestArchiveClass(t *testing.T) {
+	c := NewClassifier(Good, Bad, "Seasonal")
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.Learn([]string{"santa", "snow"}, "Seasonal")
+	Assert(t, c.ArchiveClass("Ugly") == ErrClassNotFound)
+	Assert(t, c.ArchiveClass("Seasonal") == nil && c.IsArchived("Seasonal"))
+
+	priors := c.getPriors()
+	Assert(t, priors[0] == 0.5 && priors[2] == 0, priors)
+	probs, likely, _ := c.ProbScores([]string{"santa", "snow"})
+	Assert(t, likely != 2 && probs[2] == 0, probs)
+	Assert(t, c.datas["Seasonal"].Total == 2)
+
+	Assert(t, c.UnarchiveClass("Seasonal") == nil && !c.IsArchived("Seasonal"))
+	_, likely, _ = c.ProbScores([]string{"santa", "snow"})
+	Assert(t, likely == 2)
+}
+
+func TestLearnErr(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	Assert(t, c.LearnErr([]string{"tall"}, Good) == nil && c.Learned() == 1)
+	Assert(t, c.LearnErr([]string{"tall"}, "Typo") == ErrClassNotFound)
+	Assert(t, c.ObserveErr("tall", 2, "Typo") == ErrClassNotFound)
+	Assert(t, c.ObserveDocumentErr(map[string]int{"tall": 2}, 1, "Typo") == ErrClassNotFound)
+	Assert(t, c.Learned() == 1 && len(c.Classes) == 2)
+
+	c.SetOptions(WithAutoCreateClasses())
+	Assert(t, c.LearnErr([]string{"santa"}, "Seasonal") == nil)
+	c.Learn([]string{"snow"}, "Winter")
+	Assert(t, len(c.Classes) == 4 && c.Classes[3] == "Winter", c.Classes)
+	Assert(t, c.datas["Seasonal"].Total == 1 && c.Learned() == 3)
+	c.ObserveDocument(map[string]int{"sun": 2}, 1, "Summer")
+	Assert(t, len(c.Classes) == 5 && c.datas["Summer"].Total == 2 && c.Learned() == 4)
+	Assert(t, c.AddClass(Good) == ErrDuplicateClass)
+
+	tfIdf := NewClassifierTfIdf(Good, Bad)
+	tfIdf.Learn([]string{"tall"}, Good)
+	tfIdf.ConvertTermsFreqToTfIdf()
+	Assert(t, tfIdf.LearnErr([]string{"tall"}, Good) == ErrConverted)
+}
+
+func TestLearnUnknownClass(t *testing.T) {
+	defer func() {
+		err := recover()
+		Assert(t, err == `class not found: "Typo"`, err)
+	}()
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall"}, "Typo")
+	Assert(t, false, "should have panicked")
+}
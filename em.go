@@ -0,0 +1,160 @@
+package bayesian
+
+import "math"
+
+// LearnUnlabeled improves the classifier with documents whose
+// classes are unknown, by expectation-maximization: each
+// iteration classifies the documents with the current model and
+// adds their words to the counts of each class, weighted by the
+// probability of the class, in place of the counts added by the
+// previous iteration. The words are counted the way Learn
+// counts them, after forming n-grams, dropping ignored words and
+// capping repeated words. The classifier should have learned
+// some labeled documents first. The unlabeled documents do not
+// count as learned documents. LearnUnlabeled cannot be used with a
+// TF-IDF classifier.
+func (c *Classifier) LearnUnlabeled(docs [][]string, iterations int) {
+	c.applyDecay()
+	if c.tfIdf {
+		panic("Cannot learn unlabeled documents with a TF-IDF classifier.")
+	}
+	if iterations < 1 {
+		panic("provide at least one iteration")
+	}
+	// the counts learned from labeled documents
+	baseFreqs := make(map[Class]map[string]float64, len(c.datas))
+	baseTotals := make(map[Class]float64, len(c.datas))
+	for class, data := range c.datas {
+		baseFreqs[class] = copyFreqs(data.Freqs)
+		baseTotals[class] = data.Total
+	}
+	// the counts of the words of each document, as learned
+	counts := make([]map[string]int, len(docs))
+	lengths := make([]int, len(docs))
+	for k, doc := range docs {
+		counts[k] = make(map[string]int)
+		for _, word := range c.ngrams(doc) {
+			counts[k][word]++
+		}
+		lengths[k] = c.filterCounts(counts[k])
+	}
+	for i := 0; i < iterations; i++ {
+		posteriors := make([][]float64, len(docs))
+		for k, doc := range docs {
+			posteriors[k] = softmax(c.logScores(doc))
+		}
+		for class, data := range c.datas {
+			data.Freqs = copyFreqs(baseFreqs[class])
+			data.Total = baseTotals[class]
+		}
+		for k := range docs {
+			for index, class := range c.Classes {
+				p := posteriors[k][index]
+				if p == 0 {
+					continue
+				}
+				data := c.datas[class]
+				for word, cnt := range counts[k] {
+					data.Freqs[word] += p * float64(cnt)
+				}
+				data.Total += p * float64(lengths[k])
+			}
+		}
+		c.invalidate()
+	}
+}
+
+// PriorAdaptation configures the re-estimation of the prior
+// probabilities from unlabeled documents, see c.AdaptPriors.
+type PriorAdaptation struct {
+	Iterations int     // maximum EM iterations, 100 if 0
+	Tolerance  float64 // stop once no prior changes more, 1e-6 if 0
+	MinPrior   float64 // floor of each prior, 1e-4 if 0
+}
+
+// EstimatePriors estimates the prior probabilities of the
+// classes in the population the unlabeled documents were drawn
+// from, typically recent serving traffic, by the EM procedure
+// of Saerens et al. (2002): the posteriors of the documents
+// are recomputed with the estimated priors, which are then
+// re-estimated as the mean posteriors, until they converge. The
+// posteriors are those of the scoring methods, including the
+// length model and biases, with the estimated priors.
+// The priors are floored at cfg.MinPrior before they are
+// normalized, so that no class can be ruled out by a skewed
+// sample. The classifier is not
+// changed.
+func (c *Classifier) EstimatePriors(docs [][]string, cfg PriorAdaptation) map[Class]float64 {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling EstimatePriors.")
+	}
+	if cfg.Iterations <= 0 {
+		cfg.Iterations = 100
+	}
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = 1e-6
+	}
+	if cfg.MinPrior <= 0 {
+		cfg.MinPrior = 1e-4
+	}
+	n := len(c.Classes)
+	trained := c.getPriors()
+	priors := append([]float64(nil), trained...)
+	// log likelihood of each document for each class, scored as
+	// when serving without the priors, leaving out the documents
+	// that no class can be scored for
+	ones := make([]float64, n)
+	for index := range ones {
+		ones[index] = 1
+	}
+	var likelihoods [][]float64
+	for _, doc := range docs {
+		likelihood := c.logScoresExpanded(c.expand(doc), ones)
+		if anyScored(likelihood) {
+			likelihoods = append(likelihoods, likelihood)
+		}
+	}
+	for i := 0; i < cfg.Iterations && len(likelihoods) > 0; i++ {
+		next := make([]float64, n)
+		scores := make([]float64, n)
+		for _, likelihood := range likelihoods {
+			for index := range scores {
+				scores[index] = likelihood[index] + math.Log(priors[index])
+			}
+			for index, p := range softmax(scores) {
+				next[index] += p / float64(len(likelihoods))
+			}
+		}
+		sum := float64(0)
+		for index := range next {
+			next[index] = math.Max(next[index], cfg.MinPrior)
+			sum += next[index]
+		}
+		change := float64(0)
+		for index := range next {
+			next[index] /= sum
+			change = math.Max(change, math.Abs(next[index]-priors[index]))
+		}
+		priors = next
+		if change < cfg.Tolerance {
+			break
+		}
+	}
+	result := make(map[Class]float64, n)
+	for index, class := range c.Classes {
+		result[class] = priors[index]
+	}
+	return result
+}
+
+// AdaptPriors sets the priors of the classifier, with
+// c.SetPriors, to the ones estimated from the unlabeled
+// documents with c.EstimatePriors. It returns the priors set
+// before, nil if they were estimated from the training data,
+// so that the adaptation can be rolled back with
+// c.SetPriors(previous).
+func (c *Classifier) AdaptPriors(docs [][]string, cfg PriorAdaptation) (previous map[Class]float64) {
+	previous = c.priors
+	c.SetPriors(c.EstimatePriors(docs, cfg))
+	return previous
+}
@@ -0,0 +1,60 @@
+package bayesian
+
+import "testing"
+import "math"
+
+func TestLearnUnlabeled(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome"}, Good)
+	c.Learn([]string{"bald", "poor"}, Bad)
+	// "rich" only ever co-occurs with Bad words
+	unlabeled := [][]string{{"poor", "rich"}, {"bald", "rich"}, {"poor", "bald", "rich"}}
+	c.LearnUnlabeled(unlabeled, 3)
+	scores, likely, _ := c.LogScores([]string{"rich"})
+	Assert(t, likely == 1, scores)
+	Assert(t, c.Learned() == 2 && c.datas[Bad].Docs == 1)
+	Assert(t, c.datas[Bad].Total > 8.9 && c.datas[Bad].Total <= 9, c.datas[Bad].Total)
+
+	// unlabeled words are counted like labeled ones
+	d, _ := NewClassifierOpts([]Class{Good, Bad}, WithStopwords([]string{"the"}), WithBinarized())
+	d.Learn([]string{"tall", "handsome"}, Good)
+	d.Learn([]string{"bald", "poor"}, Bad)
+	d.LearnUnlabeled([][]string{{"the", "poor", "poor", "rich"}}, 1)
+	_, ok := d.datas[Bad].Freqs["the"]
+	Assert(t, !ok, "stopwords should be dropped")
+	Assert(t, d.datas[Bad].Freqs["poor"] < 2, d.datas[Bad].Freqs["poor"])
+	Assert(t, math.Abs(d.datas[Good].Total+d.datas[Bad].Total-6) < 1e-9, d.WordCount())
+}
+
+func TestAdaptPriors(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome"}, Good)
+	c.Learn([]string{"bald", "poor"}, Bad)
+
+	var traffic [][]string
+	for i := 0; i < 90; i++ {
+		traffic = append(traffic, []string{"poor"})
+	}
+	for i := 0; i < 10; i++ {
+		traffic = append(traffic, []string{"tall"})
+	}
+	previous := c.AdaptPriors(traffic, PriorAdaptation{})
+	Assert(t, previous == nil)
+	priors := c.getPriors()
+	Assert(t, math.Abs(priors[1]-0.9) < 1e-3, priors)
+
+	c.SetPriors(previous)
+	priors = c.getPriors()
+	Assert(t, priors[0] == 0.5, priors)
+
+	estimated := c.EstimatePriors([][]string{{"poor"}}, PriorAdaptation{MinPrior: 0.05})
+	Assert(t, math.Abs(estimated[Good]-0.05/1.05) < 1e-3, estimated)
+
+	// the posteriors include the length model, as when serving
+	c, _ = NewClassifierOpts([]Class{Good, Bad}, WithLengthModel())
+	c.Learn([]string{"a"}, Good)
+	c.Learn([]string{"a", "a", "a", "a", "a"}, Bad)
+	traffic = [][]string{{"a", "a", "a", "a", "a"}, {"a", "a", "a", "a", "a"}}
+	estimated = c.EstimatePriors(traffic, PriorAdaptation{})
+	Assert(t, estimated[Bad] > 0.9, estimated)
+}
@@ -0,0 +1,141 @@
+package bayesian
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Member is a classifier taking part in an Ensemble.
+type Member struct {
+	Classifier *Classifier
+	// Weight scales the log probabilities of the classifier in
+	// the combined scores; it must be positive.
+	Weight float64
+	// Features maps a document to the words the classifier is
+	// trained on, for instance its character n-grams. Nil means
+	// the document itself.
+	Features func(document []string) []string
+}
+
+// Ensemble classifies documents by soft voting: the score of
+// each class is the weighted sum of its log probabilities under
+// each member classifier, so that models trained on different
+// features of the same documents can be combined.
+type Ensemble struct {
+	Classes []Class
+	Members []Member
+}
+
+// NewEnsemble returns an ensemble of the members, with the
+// classes of the first of them. It returns an error if there
+// are no members, if a weight is not positive, or one wrapping
+// ErrClassNotFound if the members do not all have the same
+// classes, in any order.
+func NewEnsemble(members ...Member) (*Ensemble, error) {
+	if len(members) == 0 {
+		return nil, errors.New("provide at least one member")
+	}
+	classes := members[0].Classifier.Classes
+	for i, m := range members {
+		if m.Weight <= 0 {
+			return nil, fmt.Errorf("weight of member %d must be positive, got %v", i, m.Weight)
+		}
+		if len(m.Classifier.Classes) != len(classes) {
+			return nil, fmt.Errorf("%w: member %d has %d classes, want %d", ErrClassNotFound, i, len(m.Classifier.Classes), len(classes))
+		}
+		for _, class := range classes {
+			if _, ok := m.Classifier.datas[class]; !ok {
+				return nil, fmt.Errorf("%w: %q in member %d", ErrClassNotFound, class, i)
+			}
+		}
+	}
+	return &Ensemble{
+		Classes: append([]Class(nil), classes...),
+		Members: append([]Member(nil), members...),
+	}, nil
+}
+
+// LogScores returns the combined score of the document for each
+// class of the ensemble, and the index of the most likely one.
+// Ties are detected and broken as configured for the first
+// member, see WithTieBreak and WithTieEpsilon. Members with no
+// eligible class, see WithMinClassDocs, are left out; if no
+// member is left, the scores are 0 and the index is -1. Usage
+// statistics are not recorded by the members.
+func (e *Ensemble) LogScores(document []string) (scores []float64, inx int, strict bool) {
+	scores = make([]float64, len(e.Classes))
+	scored := false
+	for _, m := range e.Members {
+		c := m.Classifier
+		if c.tfIdf && !c.DidConvertTfIdf {
+			panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling LogScores.")
+		}
+		doc := document
+		if m.Features != nil {
+			doc = m.Features(document)
+		}
+		logScores := c.logScores(doc)
+		if !anyScored(logScores) {
+			continue
+		}
+		scored = true
+		logProbs := logNormalize(logScores)
+		index := make(map[Class]float64, len(c.Classes))
+		for i, class := range c.Classes {
+			index[class] = logProbs[i]
+		}
+		for i, class := range e.Classes {
+			scores[i] += m.Weight * index[class]
+		}
+	}
+	if !scored {
+		return scores, -1, false
+	}
+	inx, strict = e.pickMax(scores)
+	return scores, inx, strict
+}
+
+// pickMax picks the most likely class of the scores with the tie
+// policy of the first member.
+func (e *Ensemble) pickMax(scores []float64) (inx int, strict bool) {
+	p := e.Members[0].Classifier.tiePolicy()
+	p.classes = e.Classes
+	return p.pickMax(scores)
+}
+
+// ProbScores works the same as LogScores, but returns the
+// probabilities of the combined scores, computed without
+// underflow.
+func (e *Ensemble) ProbScores(document []string) (scores []float64, inx int, strict bool) {
+	scores, inx, _ = e.LogScores(document)
+	if inx < 0 {
+		return scores, inx, false
+	}
+	scores = softmax(scores)
+	inx, strict = e.pickMax(scores)
+	return scores, inx, strict
+}
+
+// logNormalize converts log scores into log probabilities, by
+// subtracting the log of the sum of their exponentials. Scores
+// that are all -Inf are returned as they are.
+func logNormalize(logScores []float64) []float64 {
+	max := math.Inf(-1)
+	for _, s := range logScores {
+		max = math.Max(max, s)
+	}
+	if math.IsInf(max, -1) {
+		return append([]float64(nil), logScores...)
+	}
+	sum := 0.0
+	for _, s := range logScores {
+		sum += math.Exp(s - max)
+	}
+	norm := max + math.Log(sum)
+	normalized := make([]float64, len(logScores))
+	for i, s := range logScores {
+		normalized[i] = s - norm
+	}
+	return normalized
+}
@@ -0,0 +1,65 @@
+package bayesian
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEnsemble(t *testing.T) {
+	words := NewClassifier(Good, Bad)
+	words.Learn([]string{"tall", "handsome", "rich"}, Good)
+	words.Learn([]string{"bald", "poor", "ugly"}, Bad)
+
+	// the second model is trained on the first letters of the words
+	initials := func(document []string) []string {
+		letters := make([]string, len(document))
+		for i, word := range document {
+			letters[i] = word[:1]
+		}
+		return letters
+	}
+	letters := NewClassifier(Bad, Good)
+	letters.Learn(initials([]string{"tall", "handsome", "rich"}), Good)
+	letters.Learn(initials([]string{"bald", "poor", "ugly"}), Bad)
+
+	e, err := NewEnsemble(Member{Classifier: words, Weight: 1}, Member{Classifier: letters, Weight: 0.5, Features: initials})
+	Assert(t, err == nil, err)
+	doc := []string{"rich", "pretty"}
+	scores, inx, strict := e.LogScores(doc)
+	Assert(t, inx == 0 && strict, scores)
+
+	p, _, _ := words.ProbScores(doc)
+	q, _, _ := letters.ProbScores(initials(doc))
+	want := math.Log(p[0]) + 0.5*math.Log(q[1])
+	Assert(t, math.Abs(scores[0]-want) < 1e-9, scores[0], want)
+
+	probs, _, _ := e.ProbScores(doc)
+	Assert(t, math.Abs(probs[0]+probs[1]-1) < 1e-9 && probs[0] > 0.5, probs)
+	Assert(t, words.Seen() == 1 && letters.Seen() == 1)
+
+	_, err = NewEnsemble(Member{Classifier: words, Weight: 1}, Member{Classifier: NewClassifier(Good, "Ugly"), Weight: 1})
+	Assert(t, errors.Is(err, ErrClassNotFound), err)
+	_, err = NewEnsemble(Member{Classifier: words})
+	Assert(t, err != nil, "zero weight accepted")
+}
+
+func TestEnsembleNoEligibleClass(t *testing.T) {
+	words := NewClassifier(Good, Bad)
+	words.Learn([]string{"tall", "handsome", "rich"}, Good)
+	words.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	empty, err := NewClassifierOpts([]Class{Good, Bad}, WithMinClassDocs(1))
+	Assert(t, err == nil, err)
+
+	e, err := NewEnsemble(Member{Classifier: words, Weight: 1}, Member{Classifier: empty, Weight: 1})
+	Assert(t, err == nil, err)
+	doc := []string{"rich"}
+	scores, inx, _ := e.LogScores(doc)
+	p, _, _ := words.ProbScores(doc)
+	Assert(t, inx == 0 && math.Abs(scores[0]-math.Log(p[0])) < 1e-9, scores)
+
+	e, err = NewEnsemble(Member{Classifier: empty, Weight: 1})
+	Assert(t, err == nil, err)
+	scores, inx, strict := e.ProbScores(doc)
+	Assert(t, inx == -1 && !strict && !math.IsNaN(scores[0]), scores)
+}
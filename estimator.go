@@ -0,0 +1,48 @@
+package bayesian
+
+// Estimator estimates the probabilities a classifier scores
+// documents with, so that alternative estimators, for instance
+// Good-Turing or shrinkage, can be used without changing the
+// scoring code. Custom estimators can wrap the built-in one,
+// returned by c.DefaultEstimator().
+type Estimator interface {
+	// WordProb returns P(W|C_j), the probability of the word
+	// in a document of the class.
+	WordProb(class Class, word string) float64
+	// Prior returns the prior probability of the class, P(C_j).
+	// The priors are normalized to sum to 1 over the classes.
+	Prior(class Class) float64
+}
+
+// WithEstimator makes the classifier score documents with the
+// probabilities of the Estimator e, instead of its own; nil
+// restores them. The Estimator is not serialized with the
+// classifier; snapshots taken with c.Snapshot() ask it about the
+// words outside the vocabulary.
+func WithEstimator(e Estimator) Option {
+	return func(c *Classifier) {
+		c.estimator = e
+	}
+}
+
+// DefaultEstimator returns the Estimator built into the
+// classifier, which estimates the probabilities from the counts
+// as configured with the options, regardless of WithEstimator.
+func (c *Classifier) DefaultEstimator() Estimator {
+	return defaultEstimator{c}
+}
+
+// defaultEstimator is the Estimator built into a classifier.
+type defaultEstimator struct {
+	c *Classifier
+}
+
+// WordProb implements Estimator.
+func (e defaultEstimator) WordProb(class Class, word string) float64 {
+	return e.c.builtinWordProb(class, word)
+}
+
+// Prior implements Estimator.
+func (e defaultEstimator) Prior(class Class) float64 {
+	return e.c.builtinPriors()[e.c.classIndex(class)]
+}
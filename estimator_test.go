@@ -0,0 +1,44 @@
+package bayesian
+
+import (
+	"math"
+	"testing"
+)
+
+// flatEstimator mixes the word probabilities of the default
+// estimator with a flat distribution, and gives uniform priors.
+type flatEstimator struct {
+	Estimator
+}
+
+func (e flatEstimator) WordProb(class Class, word string) float64 {
+	return 0.5*e.Estimator.WordProb(class, word) + 0.5*0.01
+}
+
+func (e flatEstimator) Prior(class Class) float64 {
+	return 1
+}
+
+func TestEstimator(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor"}, Bad)
+	doc := []string{"tall", "poor"}
+	before, _, _ := c.LogScores(doc)
+
+	c.SetOptions(WithEstimator(flatEstimator{c.DefaultEstimator()}))
+	Assert(t, c.DefaultEstimator().Prior(Good) == 0.6)
+	scores, _, _ := c.LogScores(doc)
+	want := math.Log(0.5) + math.Log(0.5/3+0.005) + math.Log(0.5*defaultProb+0.005)
+	Assert(t, math.Abs(scores[0]-want) < 1e-9, scores, want)
+	frozen, _, _ := c.Snapshot().LogScores(doc)
+	Assert(t, math.Abs(frozen[0]-want) < 1e-9, frozen, want)
+	oov := []string{"tall", "girl"}
+	scores, _, _ = c.LogScores(oov)
+	frozen, _, _ = c.Snapshot().LogScores(oov)
+	Assert(t, math.Abs(frozen[0]-scores[0]) < 1e-9 && math.Abs(frozen[1]-scores[1]) < 1e-9, frozen, scores)
+
+	c.SetOptions(WithEstimator(nil))
+	scores, _, _ = c.LogScores(doc)
+	Assert(t, scores[0] == before[0] && scores[1] == before[1], scores, before)
+}
@@ -0,0 +1,266 @@
+package bayesian
+
+import "fmt"
+
+// Mistake is a document the classifier was told it classified
+// wrongly, as retained by c.Feedback.
+type Mistake struct {
+	Document  []string
+	Predicted Class
+	Actual    Class
+}
+
+// Feedback reports the actual class of a document that the
+// classifier predicted to be of the given class. It is recorded
+// in the usage statistics and, if the prediction was wrong and
+// a mistake reservoir is configured, a copy of the document is
+// retained.
+func (c *Classifier) Feedback(document []string, predicted, actual Class) {
+	c.stats.RecordFeedback(predicted, actual)
+	if predicted == actual {
+		return
+	}
+	c.mistakesMu.Lock()
+	defer c.mistakesMu.Unlock()
+	if c.maxMistakes <= 0 {
+		return
+	}
+	mistake := Mistake{append([]string(nil), document...), predicted, actual}
+	if len(c.mistakes) < c.maxMistakes {
+		c.mistakes = append(c.mistakes, mistake)
+		return
+	}
+	// replace the oldest mistake
+	c.mistakes[c.mistakesNext] = mistake
+	c.mistakesNext = (c.mistakesNext + 1) % len(c.mistakes)
+}
+
+// RecentMistakes returns the retained misclassified documents,
+// oldest first.
+func (c *Classifier) RecentMistakes() []Mistake {
+	c.mistakesMu.Lock()
+	defer c.mistakesMu.Unlock()
+	return c.orderedMistakes()
+}
+
+// orderedMistakes returns a copy of the retained mistakes,
+// oldest first. The caller must hold mistakesMu.
+func (c *Classifier) orderedMistakes() []Mistake {
+	result := make([]Mistake, 0, len(c.mistakes))
+	result = append(result, c.mistakes[c.mistakesNext:]...)
+	return append(result, c.mistakes[:c.mistakesNext]...)
+}
+
+// ConfusionMatrix tallies classification outcomes: Counts[i][j]
+// is the number of documents of class Classes[i] that were
+// classified as Classes[j], and Unclassified[i] the number of
+// those that could not be classified because no class was
+// eligible, see WithMinClassDocs.
+type ConfusionMatrix struct {
+	Classes      []Class
+	Counts       [][]int
+	Unclassified []int
+}
+
+// Confusion classifies each of the documents and tallies the
+// outcome against the expected class of the document, which
+// is given by expected at the same index. The documents are not
+// counted in the usage statistics. It returns an error if there
+// is not one expected class for each document, and one wrapping
+// ErrClassNotFound if an expected class is not a class of the
+// classifier.
+func (c *Classifier) Confusion(docs [][]string, expected []Class) (cm ConfusionMatrix, err error) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling Confusion.")
+	}
+	if len(expected) != len(docs) {
+		return cm, fmt.Errorf("got %d expected classes for %d documents", len(expected), len(docs))
+	}
+	n := len(c.Classes)
+	index := make(map[Class]int, n)
+	for i, class := range c.Classes {
+		index[class] = i
+	}
+	for _, class := range expected {
+		if _, ok := index[class]; !ok {
+			return cm, fmt.Errorf("%w: %q", ErrClassNotFound, class)
+		}
+	}
+	cm.Classes = append([]Class(nil), c.Classes...)
+	cm.Counts = make([][]int, n)
+	for i := range cm.Counts {
+		cm.Counts[i] = make([]int, n)
+	}
+	cm.Unclassified = make([]int, n)
+	for k, doc := range docs {
+		scores := c.logScores(doc)
+		if !anyScored(scores) {
+			cm.Unclassified[index[expected[k]]]++
+			continue
+		}
+		inx, _ := c.pickMax(scores)
+		cm.Counts[index[expected[k]]][inx]++
+	}
+	return cm, nil
+}
+
+// SuggestionKind identifies the change recommended by a
+// Suggestion.
+type SuggestionKind int
+
+const (
+	// SuggestMerge indicates that the classes are so often
+	// mistaken for one another that they may be one class.
+	SuggestMerge SuggestionKind = iota
+	// SuggestSplit indicates that the documents of the class
+	// are mistaken for several different classes, or fall into
+	// two groups with little vocabulary in common, so the class
+	// may be covering more than one topic.
+	SuggestSplit
+)
+
+// Suggestion is a recommended change to the set of classes.
+type Suggestion struct {
+	Kind    SuggestionKind
+	Classes []Class // the class pair to merge, or the class to split
+	Rate    float64 // the rate that triggered the suggestion
+}
+
+// SuggestTaxonomyChanges inspects a confusion matrix and flags
+// pairs of classes whose mutual confusion rate -- the fraction
+// of the documents of either class classified as the other --
+// exceeds the threshold, as well as classes whose documents are
+// classified as two or more other classes, each at a rate
+// exceeding the threshold. Classes with a bimodal vocabulary
+// cannot be told from the confusion matrix; see
+// Classifier.SuggestSplits.
+func SuggestTaxonomyChanges(cm ConfusionMatrix, threshold float64) (suggestions []Suggestion) {
+	n := len(cm.Classes)
+	totals := make([]int, n)
+	for i, row := range cm.Counts {
+		for _, cnt := range row {
+			totals[i] += cnt
+		}
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			total := totals[i] + totals[j]
+			if total == 0 {
+				continue
+			}
+			rate := float64(cm.Counts[i][j]+cm.Counts[j][i]) / float64(total)
+			if rate > threshold {
+				suggestions = append(suggestions, Suggestion{SuggestMerge, []Class{cm.Classes[i], cm.Classes[j]}, rate})
+			}
+		}
+	}
+	for i, row := range cm.Counts {
+		if totals[i] == 0 {
+			continue
+		}
+		targets, missed := 0, 0
+		for j, cnt := range row {
+			if j != i && float64(cnt)/float64(totals[i]) > threshold {
+				targets++
+				missed += cnt
+			}
+		}
+		if targets >= 2 {
+			suggestions = append(suggestions, Suggestion{SuggestSplit, []Class{cm.Classes[i]}, float64(missed) / float64(totals[i])})
+		}
+	}
+	return
+}
+
+// SuggestSplits flags the classes whose vocabulary is bimodal,
+// for taxonomy maintainers to review along with the output of
+// SuggestTaxonomyChanges. The documents, labeled with their
+// class by classes at the same index, are read as the classifier
+// reads them, and the documents of each class are divided into
+// two groups around its two most dissimilar documents. A class
+// is flagged if each group has at least two documents, and the
+// rate of words that are not shared by the vocabularies of the
+// groups, one minus their Jaccard similarity, exceeds the
+// threshold. It returns an error if there is not one class for
+// each document, and one wrapping ErrClassNotFound if a class is
+// not a class of the classifier.
+func (c *Classifier) SuggestSplits(docs [][]string, classes []Class, threshold float64) (suggestions []Suggestion, err error) {
+	if len(classes) != len(docs) {
+		return nil, fmt.Errorf("got %d classes for %d documents", len(classes), len(docs))
+	}
+	vocabs := make(map[Class][]map[string]bool, len(c.Classes))
+	for k, doc := range docs {
+		if _, ok := c.datas[classes[k]]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrClassNotFound, classes[k])
+		}
+		vocab := make(map[string]bool)
+		for _, word := range c.expand(doc) {
+			vocab[word] = true
+		}
+		if len(vocab) > 0 {
+			vocabs[classes[k]] = append(vocabs[classes[k]], vocab)
+		}
+	}
+	for _, class := range c.Classes {
+		if rate, ok := splitRate(vocabs[class]); ok && rate > threshold {
+			suggestions = append(suggestions, Suggestion{SuggestSplit, []Class{class}, rate})
+		}
+	}
+	return suggestions, nil
+}
+
+// splitRate divides the vocabularies of documents into two
+// groups around the two most dissimilar ones, and returns the
+// rate of words not shared by the groups, or false if either
+// group has fewer than two documents.
+func splitRate(vocabs []map[string]bool) (float64, bool) {
+	if len(vocabs) < 4 {
+		return 0, false
+	}
+	// find two documents far apart, starting from the first
+	farthest := func(from map[string]bool) map[string]bool {
+		far, min := from, 2.0
+		for _, vocab := range vocabs {
+			if s := jaccard(from, vocab); s < min {
+				far, min = vocab, s
+			}
+		}
+		return far
+	}
+	b := farthest(vocabs[0])
+	a := farthest(b)
+	groupA, groupB := make(map[string]bool), make(map[string]bool)
+	sizeA, sizeB := 0, 0
+	for _, vocab := range vocabs {
+		group := groupA
+		if jaccard(vocab, b) > jaccard(vocab, a) {
+			group = groupB
+			sizeB++
+		} else {
+			sizeA++
+		}
+		for word := range vocab {
+			group[word] = true
+		}
+	}
+	if sizeA < 2 || sizeB < 2 {
+		return 0, false
+	}
+	return 1 - jaccard(groupA, groupB), true
+}
+
+// jaccard returns the number of words in both sets divided by
+// the number of words in either.
+func jaccard(a, b map[string]bool) float64 {
+	both := 0
+	for word := range a {
+		if b[word] {
+			both++
+		}
+	}
+	either := len(a) + len(b) - both
+	if either == 0 {
+		return 1
+	}
+	return float64(both) / float64(either)
+}
@@ -0,0 +1,103 @@
+package bayesian
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSuggestTaxonomyChanges(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	cm, err := c.Confusion([][]string{{"tall"}, {"poor"}, {"rich", "ugly", "bald"}}, []Class{Good, Bad, Good})
+	Assert(t, err == nil, err)
+	Assert(t, cm.Counts[0][0] == 1 && cm.Counts[0][1] == 1 && cm.Counts[1][1] == 1, cm.Counts)
+	Assert(t, c.Seen() == 0, "confusion documents should not be counted as seen")
+	cm.Classes[0] = "changed"
+	Assert(t, c.Classes[0] == Good, "confusion classes should be a copy")
+	_, err = c.Confusion([][]string{{"tall"}, {"poor"}}, []Class{Good})
+	Assert(t, err != nil, "expected an error for missing expected classes")
+	_, err = c.Confusion([][]string{{"tall"}}, []Class{"Ugly"})
+	Assert(t, errors.Is(err, ErrClassNotFound), err)
+
+	cm = ConfusionMatrix{
+		Classes: []Class{"a", "b", "c", "d"},
+		Counts: [][]int{
+			{5, 5, 0, 0},
+			{4, 6, 0, 0},
+			{0, 0, 10, 0},
+			{0, 3, 3, 4},
+		},
+	}
+	s := SuggestTaxonomyChanges(cm, 0.25)
+	Assert(t, len(s) == 2, s)
+	Assert(t, s[0].Kind == SuggestMerge && s[0].Classes[0] == "a" && s[0].Classes[1] == "b", s)
+	Assert(t, s[1].Kind == SuggestSplit && s[1].Classes[0] == "d", s)
+
+	// documents that cannot be classified are tallied apart
+	c.SetOptions(WithMinClassDocs(2))
+	cm, err = c.Confusion([][]string{{"tall"}, {"poor"}}, []Class{Good, Bad})
+	Assert(t, err == nil, err)
+	Assert(t, cm.Unclassified[0] == 1 && cm.Unclassified[1] == 1 && cm.Counts[0][0] == 0 && cm.Counts[1][0] == 0, cm)
+}
+
+func TestSuggestSplits(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	docs := [][]string{
+		{"tall", "rich"}, {"poor", "ugly"}, {"tall", "handsome"}, {"poor", "bald"},
+		{"cat", "dog"}, {"poor", "ugly", "bald"}, {"cat", "mouse"}, {"ugly", "bald"},
+	}
+	classes := []Class{Good, Bad, Good, Bad, Good, Bad, Good, Bad}
+	s, err := c.SuggestSplits(docs, classes, 0.5)
+	Assert(t, err == nil, err)
+	Assert(t, len(s) == 1 && s[0].Kind == SuggestSplit && s[0].Classes[0] == Good && s[0].Rate == 1, s)
+	_, err = c.SuggestSplits(docs, classes[1:], 0.5)
+	Assert(t, err != nil, "expected an error for missing classes")
+	_, err = c.SuggestSplits(docs[:1], []Class{"Ugly"}, 0.5)
+	Assert(t, errors.Is(err, ErrClassNotFound), err)
+}
+
+func TestRecentMistakes(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Feedback([]string{"a"}, Good, Bad)
+	Assert(t, len(c.RecentMistakes()) == 0, "reservoir should be disabled")
+
+	c.SetOptions(WithMistakeReservoir(2))
+	c.Feedback([]string{"a"}, Good, Bad)
+	c.Feedback([]string{"b"}, Good, Good)
+	c.Feedback([]string{"c"}, Bad, Good)
+	c.Feedback([]string{"d"}, Good, Bad)
+	mistakes := c.RecentMistakes()
+	Assert(t, len(mistakes) == 2, mistakes)
+	Assert(t, mistakes[0].Document[0] == "c" && mistakes[0].Actual == Good, mistakes)
+	Assert(t, mistakes[1].Document[0] == "d" && mistakes[1].Predicted == Good, mistakes)
+
+	// the ring keeps the order, and the documents are copies
+	doc := []string{"e"}
+	c.Feedback(doc, Bad, Good)
+	doc[0] = "changed"
+	mistakes = c.RecentMistakes()
+	Assert(t, mistakes[0].Document[0] == "d" && mistakes[1].Document[0] == "e", mistakes)
+	c.SetOptions(WithMistakeReservoir(1))
+	mistakes = c.RecentMistakes()
+	Assert(t, len(mistakes) == 1 && mistakes[0].Document[0] == "e", mistakes)
+	c.SetOptions(WithMistakeReservoir(3))
+	c.Feedback([]string{"f"}, Bad, Good)
+	mistakes = c.Clone().RecentMistakes()
+	Assert(t, len(mistakes) == 2 && mistakes[0].Document[0] == "e" && mistakes[1].Document[0] == "f", mistakes)
+	correct, incorrect := c.Stats().Feedback()
+	Assert(t, correct == 1 && incorrect == 6)
+
+	// the size of the reservoir is serialized
+	var buf bytes.Buffer
+	Assert(t, c.WriteTo(&buf) == nil)
+	d, err := NewClassifierFromReader(&buf)
+	Assert(t, err == nil && d.maxMistakes == 3, err)
+
+	_, err = NewClassifierOpts([]Class{Good, Bad}, WithMistakeReservoir(-1))
+	Assert(t, err != nil, "negative reservoir size accepted")
+	c.SetOptions(WithMistakeReservoir(-1))
+	c.Feedback([]string{"g"}, Bad, Good)
+	Assert(t, len(c.RecentMistakes()) == 0)
+}
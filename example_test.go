@@ -0,0 +1,94 @@
+package bayesian_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jbrukh/bayesian"
+)
+
+const (
+	Good bayesian.Class = "Good"
+	Bad  bayesian.Class = "Bad"
+)
+
+func train() *bayesian.Classifier {
+	classifier := bayesian.NewClassifier(Good, Bad)
+	classifier.Learn([]string{"tall", "rich", "handsome"}, Good)
+	classifier.Learn([]string{"poor", "smelly", "ugly"}, Bad)
+	return classifier
+}
+
+func Example_classify() {
+	classifier := train()
+
+	// the scores are in the order of classifier.Classes
+	result := classifier.Classify([]string{"tall", "girl"})
+	fmt.Printf("%.2f %.2f\n", result.Scores[0], result.Scores[1])
+	fmt.Printf("%.2f %.2f\n", result.Probabilities[0], result.Probabilities[1])
+	fmt.Println(result.Class, result.Strict)
+	// Output:
+	// -27.12 -51.35
+	// 1.00 0.00
+	// Good true
+}
+
+func Example_tfidf() {
+	classifier := bayesian.NewClassifierTfIdf(Good, Bad)
+	classifier.Learn([]string{"tall", "tall", "rich"}, Good)
+	classifier.Learn([]string{"poor", "smelly", "ugly"}, Bad)
+
+	// all documents must be learned before the conversion
+	classifier.ConvertTermsFreqToTfIdf()
+
+	fmt.Println(classifier.Classify([]string{"tall", "man"}).Class)
+	// Output:
+	// Good
+}
+
+func Example_persistence() {
+	classifier := train()
+
+	var buf bytes.Buffer
+	if err := classifier.WriteTo(&buf); err != nil {
+		panic(err)
+	}
+	loaded, err := bayesian.NewClassifierFromReader(&buf)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(loaded.Classify([]string{"ugly", "man"}).Class, loaded.Learned())
+	// Output:
+	// Bad 2
+}
+
+func Example_threshold() {
+	classifier := train()
+
+	// refuse to classify documents the model has no opinion on
+	for _, doc := range [][]string{{"rich"}, {"unknown", "words"}} {
+		class, ok := classifier.ClassifyWithThreshold(doc, 0.9)
+		if !ok {
+			fmt.Println(doc, "unknown")
+			continue
+		}
+		fmt.Println(doc, class)
+	}
+	// Output:
+	// [rich] Good
+	// [unknown words] unknown
+}
+
+func Example_evaluation() {
+	classifier := train()
+
+	docs := [][]string{{"tall"}, {"ugly"}, {"rich", "smelly", "poor"}}
+	expected := []bayesian.Class{Good, Bad, Good}
+	cm, _ := classifier.Confusion(docs, expected)
+	for i, row := range cm.Counts {
+		fmt.Println(cm.Classes[i], row)
+	}
+	// Output:
+	// Good [1 1]
+	// Bad [0 1]
+}
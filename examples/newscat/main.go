@@ -0,0 +1,244 @@
+// Command newscat trains a news categorizer with the bayesian
+// package, end to end: it loads a dataset, preprocesses and
+// tokenizes the articles, cross-validates the model, prunes it,
+// calibrates its probabilities on held-out articles, persists
+// it, and serves a snapshot of it. It exits with a non-zero
+// status if any step fails, so it doubles as an integration
+// test of how the parts of the package compose; go test runs
+// it on the built-in sample.
+//
+// The dataset is read in the CSV format of the ag_news_csv
+// distribution of the public AG News corpus, one article per
+// line as its class index, from 1 (world) to 4 (sci/tech), its
+// title and its description:
+//
+//	newscat -data ag_news_csv/train.csv
+//
+// Without -data, a built-in sample in the same format is used.
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jbrukh/bayesian"
+)
+
+const (
+	World    bayesian.Class = "world"
+	Sports   bayesian.Class = "sports"
+	Business bayesian.Class = "business"
+	SciTech  bayesian.Class = "scitech"
+)
+
+// classes are the classes of AG News, in the order of their
+// class indices.
+var classes = []bayesian.Class{World, Sports, Business, SciTech}
+
+type article struct {
+	text  string
+	class bayesian.Class
+}
+
+// sample is a sample of articles in the format of AG News.
+const sample = `"1","Leaders meet at the summit","Leaders meet to discuss the <b>peace</b> talks"
+"1","Election results","Election results spark protests in the capital"
+"1","Cease-fire agreed","The government and rebels agree to a cease-fire"
+"1","Border talks","Ministers hold talks on the border dispute"
+"1","Troops withdraw","<div>Troops withdraw after the peace agreement</div>"
+"1","President visits allies","The president visits allies ahead of the election"
+"1","Refugees cross the border","Refugees flee the fighting as the border reopens"
+"1","Peace envoy arrives","The envoy arrives for talks with the government"
+"2","Team wins championship","The team wins the championship final in overtime"
+"2","Striker scores twice","<p>Striker scores twice as the team beats its rivals</p>"
+"2","Coach praises players","The coach praises the players after the season opener"
+"2","Star injured","Injury rules the star out of the championship game"
+"2","Fans celebrate title","Fans celebrate as the team clinches the title"
+"2","Player suspended","The league suspends the player for two games"
+"2","Goalkeeper saves penalty","The goalkeeper saves a penalty as the team wins the game"
+"2","Season ends","The team ends the season with a win over its rivals"
+"3","Shares fall","Shares fall as the company reports lower profits"
+"3","Bank raises rates","<p>The bank raises interest rates to curb inflation</p>"
+"3","Merger cheered","Investors cheer the merger of the two companies"
+"3","Oil prices climb","Oil prices climb as markets react to the supply cut"
+"3","Record earnings","The retailer posts record quarterly earnings"
+"3","Stocks rally","Stocks rally as inflation eases and profits rise"
+"3","Company cuts jobs","The company cuts jobs as profits fall"
+"3","Markets steady","Markets steady as investors await the bank rates decision"
+"4","Faster chip unveiled","Researchers unveil a faster chip for mobile devices"
+"4","Probe launched","<span>The space agency launches a probe to Mars</span>"
+"4","Security flaw fixed","The software update fixes a security flaw"
+"4","New species found","Scientists discover a new species in the rainforest"
+"4","Database released","The startup releases an open source database"
+"4","Distant galaxy","New telescope images reveal a distant galaxy"
+"4","Mobile software","The update brings new software features to mobile devices"
+"4","Space telescope","Scientists point the space telescope at a distant star"
+`
+
+var preprocess = bayesian.Chain(bayesian.StripHTML, strings.ToLower)
+
+func main() {
+	data := flag.String("data", "", "AG News CSV file, the built-in sample by default")
+	flag.Parse()
+
+	r := io.Reader(strings.NewReader(sample))
+	if *data != "" {
+		file, err := os.Open(*data)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "newscat:", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		r = file
+	}
+	if err := run(r); err != nil {
+		fmt.Fprintln(os.Stderr, "newscat:", err)
+		os.Exit(1)
+	}
+}
+
+// loadAGNews reads articles in the CSV format of AG News.
+func loadAGNews(r io.Reader) ([]article, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	var articles []article
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return articles, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		index, err := strconv.Atoi(record[0])
+		if err != nil || index < 1 || index > len(classes) {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: bad class index %q", line, record[0])
+		}
+		articles = append(articles, article{record[1] + " " + record[2], classes[index-1]})
+	}
+}
+
+func run(r io.Reader) error {
+	articles, err := loadAGNews(r)
+	if err != nil {
+		return err
+	}
+	if len(articles) == 0 {
+		return errors.New("no articles")
+	}
+
+	// hold out every fourth article to calibrate the final model
+	var pool, heldOut []article
+	for i, a := range articles {
+		if i%4 == 3 {
+			heldOut = append(heldOut, a)
+		} else {
+			pool = append(pool, a)
+		}
+	}
+
+	// cross-validation
+	const folds = 3
+	correct := 0
+	for fold := 0; fold < folds; fold++ {
+		var training, test []article
+		for i, a := range pool {
+			if i%folds == fold {
+				test = append(test, a)
+			} else {
+				training = append(training, a)
+			}
+		}
+		c, err := train(training)
+		if err != nil {
+			return err
+		}
+		for _, a := range test {
+			if c.Classify(tokenize(a.text)).Class == a.class {
+				correct++
+			}
+		}
+	}
+	accuracy := float64(correct) / float64(len(pool))
+	fmt.Printf("cross-validated accuracy: %.2f\n", accuracy)
+	if accuracy < 0.5 {
+		return fmt.Errorf("accuracy %.2f is too low", accuracy)
+	}
+
+	// final model, pruned of the words seen once
+	c, err := train(pool)
+	if err != nil {
+		return err
+	}
+	before := c.VocabularySize()
+	c.Prune(2)
+	fmt.Printf("pruned vocabulary: %d of %d words\n", c.VocabularySize(), before)
+
+	// calibration on the held-out articles
+	docs := make([][]string, len(heldOut))
+	expected := make([]bayesian.Class, len(heldOut))
+	for i, a := range heldOut {
+		docs[i], expected[i] = tokenize(a.text), a.class
+	}
+	cal := c.Calibrate(docs, expected, 5)
+
+	// persistence
+	dir, err := os.MkdirTemp("", "newscat")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "newscat.gob")
+	if err := c.WriteToFile(name); err != nil {
+		return err
+	}
+	loaded, err := bayesian.NewClassifierFromFile(name)
+	if err != nil {
+		return err
+	}
+
+	// serving
+	frozen := loaded.Snapshot()
+	headline := "<h1>The team wins the final</h1>"
+	probs, inx, _ := frozen.ProbScores(tokenize(headline))
+	calibrated := cal.Apply(probs)
+	fmt.Printf("%q: %s (%.2f)\n", headline, frozen.Classes[inx], calibrated[inx])
+	if frozen.Classes[inx] != Sports {
+		return fmt.Errorf("%q classified as %s", headline, frozen.Classes[inx])
+	}
+	return nil
+}
+
+// train returns a classifier trained on the articles.
+func train(articles []article) (*bayesian.Classifier, error) {
+	c, err := bayesian.NewClassifierOpts(classes,
+		bayesian.WithSmoothing(1),
+		bayesian.WithDocumentPriors(),
+		bayesian.WithMinWordLength(3),
+		bayesian.WithStopwords([]string{"the", "and", "for", "after", "ahead"}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	tx := c.Begin()
+	for _, a := range articles {
+		if err := tx.Learn(tokenize(a.text), a.class); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return c, tx.Commit()
+}
+
+// tokenize preprocesses the text and splits it into words.
+func tokenize(text string) []string {
+	return strings.Fields(preprocess(text))
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	if err := run(strings.NewReader(sample)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAGNews(t *testing.T) {
+	articles, err := loadAGNews(strings.NewReader(`"2","Title","Text, with a comma"` + "\n"))
+	if err != nil || len(articles) != 1 {
+		t.Fatal(articles, err)
+	}
+	if articles[0].class != Sports || articles[0].text != "Title Text, with a comma" {
+		t.Fatal(articles[0])
+	}
+	if _, err := loadAGNews(strings.NewReader(`"5","Title","Text"` + "\n")); err == nil {
+		t.Fatal("bad class index accepted")
+	}
+}
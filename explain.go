@@ -0,0 +1,84 @@
+package bayesian
+
+import (
+	"math"
+	"sort"
+)
+
+// WordContribution is the contribution of a word of a document
+// to the log score of each class.
+type WordContribution struct {
+	Word          string
+	Count         int       // occurrences in the document
+	Contributions []float64 // indexed like the classes
+}
+
+// Influence returns how much the word tells the classes apart:
+// the difference between its largest and smallest contribution.
+func (w WordContribution) Influence() float64 {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, contrib := range w.Contributions {
+		min = math.Min(min, contrib)
+		max = math.Max(max, contrib)
+	}
+	return max - min
+}
+
+// Explanation breaks the log scores of a document down into the
+// contribution of the prior probability of each class, of the
+// length of the document and of each word.
+type Explanation struct {
+	Classes []Class
+	Priors  []float64          // log prior of each class, with its class weight and bias
+	Length  []float64          // log probability of the document length, see WithLengthModel
+	Words   []WordContribution // by decreasing influence
+	Scores  []float64          // as returned by LogScores
+}
+
+// Explain returns the contributions to the log scores of the
+// document, for instance to show why it was classified the way
+// it was. The contribution of a word includes its IDF weight and
+// its saturation, see WithIdfWeighting and
+// WithMaxWordContribution; the caps of WithNamespaceCaps only
+// apply to the scores.
+func (c *Classifier) Explain(document []string) Explanation {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling Explain.")
+	}
+	n := len(c.Classes)
+	e := Explanation{
+		Classes: c.Classes,
+		Priors:  make([]float64, n),
+		Length:  make([]float64, n),
+		Scores:  c.logScores(document),
+	}
+	priors := c.getPriors()
+	document = c.expand(document)
+	for index, class := range c.Classes {
+		e.Priors[index] = math.Log(priors[index]) + c.logBias(class)
+		e.Length[index] = c.lengthLogProb(class, len(document))
+	}
+	counts := make(map[string]int)
+	for _, word := range document {
+		if counts[word] == 0 {
+			e.Words = append(e.Words, WordContribution{Word: word})
+		}
+		counts[word]++
+	}
+	for i := range e.Words {
+		w := &e.Words[i]
+		w.Count = counts[w.Word]
+		w.Contributions = make([]float64, n)
+		for index, class := range c.Classes {
+			contrib := float64(w.Count) * c.wordWeight(w.Word) * c.wordLogProb(class, w.Word)
+			if c.maxWordContribution > 0 {
+				contrib = math.Max(contrib, -c.maxWordContribution)
+			}
+			w.Contributions[index] = contrib
+		}
+	}
+	sort.SliceStable(e.Words, func(i, j int) bool {
+		return e.Words[i].Influence() > e.Words[j].Influence()
+	})
+	return e
+}
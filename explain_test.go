@@ -0,0 +1,24 @@
+package bayesian
+
+import "testing"
+import "math"
+
+func TestExplain(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "tall"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly", "tall"}, Bad)
+	e := c.Explain([]string{"tall", "poor", "tall", "girl"})
+	Assert(t, len(e.Words) == 3, e.Words)
+	Assert(t, e.Words[0].Word == "poor" && e.Words[2].Word == "girl", e.Words)
+	tall := e.Words[1]
+	Assert(t, tall.Count == 2 && tall.Contributions[0] == 2*math.Log(0.5), tall)
+	Assert(t, tall.Influence() == 2*math.Log(0.5)-2*math.Log(0.25), tall.Influence())
+
+	for index := range e.Classes {
+		sum := e.Priors[index]
+		for _, w := range e.Words {
+			sum += w.Contributions[index]
+		}
+		Assert(t, math.Abs(sum-e.Scores[index]) < 1e-9, sum, e.Scores)
+	}
+}
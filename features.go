@@ -0,0 +1,248 @@
+package bayesian
+
+import "math"
+
+// varSmoothing is the fraction of the variance of a numeric
+// feature over all classes that is added to its variance in each
+// class, like the var_smoothing of scikit-learn, so that a
+// feature learned with a single value, or the same value every
+// time, in a class does not have a zero variance and outweigh
+// the words. It is larger than the default of scikit-learn since
+// classes often learn few values.
+const varSmoothing = 0.01
+
+// minVariance is the smallest variance of a numeric feature in a
+// class, for features that have the same value in all classes.
+const minVariance = 1e-9
+
+// Features holds side features of a document, which are learned
+// and scored together with its words by c.LearnWithFeatures and
+// c.LogScoresWithFeatures.
+type Features struct {
+	// Numeric holds continuous features, for instance the
+	// length of a message, whose values are modeled with a
+	// normal distribution in each class.
+	Numeric map[string]float64
+	// Categorical holds categorical features, for instance the
+	// country of the sender, whose values are counted in each
+	// class.
+	Categorical map[string]string
+}
+
+// gaussian holds the running mean and variance of the values of
+// a numeric feature in a class, with Welford's algorithm.
+type gaussian struct {
+	N    float64
+	Mean float64
+	M2   float64 // sum of squared differences from the mean
+}
+
+// add adds a value to the distribution.
+func (g *gaussian) add(x float64) {
+	g.N++
+	delta := x - g.Mean
+	g.Mean += delta / g.N
+	g.M2 += delta * (x - g.Mean)
+}
+
+// combine adds the values of another distribution.
+func (g *gaussian) combine(o *gaussian) {
+	n := g.N + o.N
+	if n == 0 {
+		return
+	}
+	delta := o.Mean - g.Mean
+	g.M2 += o.M2 + delta*delta*g.N*o.N/n
+	g.Mean += delta * o.N / n
+	g.N = n
+}
+
+// remove removes the values of another distribution, which were
+// combined into this one, reversing combine.
+func (g *gaussian) remove(o *gaussian) {
+	n := g.N - o.N
+	if n <= 0 {
+		*g = gaussian{}
+		return
+	}
+	mean := (g.N*g.Mean - o.N*o.Mean) / n
+	delta := o.Mean - mean
+	g.M2 = math.Max(g.M2-o.M2-delta*delta*n*o.N/g.N, 0)
+	g.Mean = mean
+	g.N = n
+}
+
+// logPdf returns the log of the probability density of x, with
+// the given variance added to the variance of the distribution.
+func (g *gaussian) logPdf(x, smoothing float64) float64 {
+	variance := math.Max(g.M2/g.N+smoothing, minVariance)
+	d := x - g.Mean
+	return -0.5*math.Log(2*math.Pi*variance) - d*d/(2*variance)
+}
+
+// LearnWithFeatures works the same as Learn, but also learns the
+// side features of the document for the class. The side
+// features are removed again by UnlearnWithFeatures and by the
+// sliding window, and are added and removed by Merge and
+// Subtract, but are not scaled by Decay.
+func (c *Classifier) LearnWithFeatures(document []string, features Features, which Class) {
+	c.Learn(document, which)
+	addFeatures(c.datas[which], features)
+	if n := len(c.window); n > 0 && c.window[n-1].class == which {
+		copied := copyFeatures(features)
+		c.window[n-1].features = &copied
+	}
+}
+
+// UnlearnWithFeatures reverses a call to
+// c.LearnWithFeatures(document, features, which), subtracting
+// the document and its side features from the class. It
+// returns the same errors as Unlearn, without changing the side
+// features.
+func (c *Classifier) UnlearnWithFeatures(document []string, features Features, which Class) error {
+	if err := c.Unlearn(document, which); err != nil {
+		return err
+	}
+	removeFeatures(c.datas[which], features)
+	return nil
+}
+
+// addFeatures adds the side features of a document to the class
+// data.
+func addFeatures(data *classData, features Features) {
+	for name, x := range features.Numeric {
+		if data.Numeric == nil {
+			data.Numeric = make(map[string]*gaussian)
+		}
+		g, ok := data.Numeric[name]
+		if !ok {
+			g = new(gaussian)
+			data.Numeric[name] = g
+		}
+		g.add(x)
+	}
+	for name, value := range features.Categorical {
+		if data.Categories == nil {
+			data.Categories = make(map[string]map[string]float64)
+		}
+		if data.Categories[name] == nil {
+			data.Categories[name] = make(map[string]float64)
+		}
+		data.Categories[name][value]++
+	}
+}
+
+// removeFeatures removes the side features of a document from
+// the class data, reversing addFeatures.
+func removeFeatures(data *classData, features Features) {
+	for name, x := range features.Numeric {
+		g, ok := data.Numeric[name]
+		if !ok {
+			continue
+		}
+		g.remove(&gaussian{N: 1, Mean: x})
+		if g.N == 0 {
+			delete(data.Numeric, name)
+		}
+	}
+	for name, value := range features.Categorical {
+		values := data.Categories[name]
+		if values[value] <= 1 {
+			delete(values, value)
+		} else {
+			values[value]--
+		}
+		if values != nil && len(values) == 0 {
+			delete(data.Categories, name)
+		}
+	}
+}
+
+// copyFeatures returns a copy of the features, for when they
+// are remembered by the sliding window.
+func copyFeatures(features Features) Features {
+	var copied Features
+	if features.Numeric != nil {
+		copied.Numeric = make(map[string]float64, len(features.Numeric))
+		for name, x := range features.Numeric {
+			copied.Numeric[name] = x
+		}
+	}
+	if features.Categorical != nil {
+		copied.Categorical = make(map[string]string, len(features.Categorical))
+		for name, value := range features.Categorical {
+			copied.Categorical[name] = value
+		}
+	}
+	return copied
+}
+
+// LogScoresWithFeatures works the same as LogScores, but adds
+// the log likelihood of the side features of the document,
+// learned with c.LearnWithFeatures, to the score of each class:
+// the log density of the normal distribution of each numeric
+// feature, and the log probability of the value of each
+// categorical feature, with add-one smoothing. The variance of
+// numeric features is smoothed with a fraction of their
+// variance over all classes. Numeric features never learned for
+// a class get a probability of defaultProb.
+func (c *Classifier) LogScoresWithFeatures(document []string, features Features) (scores []float64, inx int, strict bool) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling LogScoresWithFeatures.")
+	}
+	scores = c.logScores(document)
+	for index, class := range c.Classes {
+		if c.IsEligible(class) {
+			scores[index] += c.featuresLogProb(class, features)
+		}
+	}
+	inx, strict = c.pickMax(scores)
+	c.record(inx)
+	return scores, inx, strict
+}
+
+// featureVariance returns the variance of the values of the
+// numeric feature over all classes.
+func (c *Classifier) featureVariance(name string) float64 {
+	var all gaussian
+	for _, class := range c.Classes {
+		if g, ok := c.datas[class].Numeric[name]; ok {
+			all.combine(g)
+		}
+	}
+	if all.N == 0 {
+		return 0
+	}
+	return all.M2 / all.N
+}
+
+// featuresLogProb returns the log likelihood of the features in
+// the class.
+func (c *Classifier) featuresLogProb(class Class, features Features) (score float64) {
+	data := c.datas[class]
+	for name, x := range features.Numeric {
+		if g, ok := data.Numeric[name]; ok {
+			score += g.logPdf(x, varSmoothing*c.featureVariance(name))
+		} else {
+			score += math.Log(defaultProb)
+		}
+	}
+	for name, value := range features.Categorical {
+		// the number of values seen over all classes, and one
+		// for unseen values
+		values := make(map[string]bool)
+		for _, d := range c.datas {
+			for v := range d.Categories[name] {
+				values[v] = true
+			}
+		}
+		values[value] = true
+		counts := data.Categories[name]
+		total := float64(0)
+		for _, cnt := range counts {
+			total += cnt
+		}
+		score += math.Log((counts[value] + 1) / (total + float64(len(values))))
+	}
+	return
+}
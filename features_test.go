@@ -0,0 +1,91 @@
+package bayesian
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestFeatures(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	for _, n := range []float64{10, 12, 14} {
+		c.LearnWithFeatures([]string{"hello"}, Features{
+			Numeric:     map[string]float64{"length": n},
+			Categorical: map[string]string{"country": "nl"},
+		}, Good)
+	}
+	for _, n := range []float64{100, 120} {
+		c.LearnWithFeatures([]string{"hello"}, Features{
+			Numeric:     map[string]float64{"length": n},
+			Categorical: map[string]string{"country": "xx"},
+		}, Bad)
+	}
+
+	// the words tie, the features decide
+	g := c.datas[Good].Numeric["length"]
+	Assert(t, g.Mean == 12 && math.Abs(g.M2/g.N-8.0/3) < 1e-9, g)
+	words, _, _ := c.LogScores([]string{"hello"})
+	f := Features{Numeric: map[string]float64{"length": 13}, Categorical: map[string]string{"country": "nl"}}
+	scores, inx, strict := c.LogScoresWithFeatures([]string{"hello"}, f)
+	Assert(t, inx == 0 && strict, scores)
+	want := words[0] + g.logPdf(13, varSmoothing*c.featureVariance("length")) + math.Log(float64(3+1)/(3+2))
+	Assert(t, math.Abs(scores[0]-want) < 1e-9, scores, want)
+	_, inx, _ = c.LogScoresWithFeatures([]string{"hello"}, Features{Numeric: map[string]float64{"length": 110}})
+	Assert(t, inx == 1)
+
+	var buf bytes.Buffer
+	Assert(t, c.WriteTo(&buf) == nil)
+	d, err := NewClassifierFromReader(&buf)
+	Assert(t, err == nil, err)
+	loaded, _, _ := d.LogScoresWithFeatures([]string{"hello"}, f)
+	Assert(t, loaded[0] == scores[0] && loaded[1] == scores[1], loaded, scores)
+	cloned, _, _ := c.Clone().LogScoresWithFeatures([]string{"hello"}, f)
+	Assert(t, cloned[0] == scores[0], cloned, scores)
+}
+
+func TestFeatureVariance(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.LearnWithFeatures([]string{"tall", "rich"}, Features{Numeric: map[string]float64{"age": 30}}, Good)
+	c.LearnWithFeatures([]string{"poor"}, Features{Numeric: map[string]float64{"age": 40}}, Bad)
+	c.LearnWithFeatures([]string{"poor"}, Features{Numeric: map[string]float64{"age": 50}}, Bad)
+	var all gaussian
+	for _, x := range []float64{30, 40, 50} {
+		all.add(x)
+	}
+	Assert(t, math.Abs(c.featureVariance("age")-all.M2/all.N) < 1e-9, c.featureVariance("age"))
+
+	// a feature learned once does not outweigh the words
+	f := Features{Numeric: map[string]float64{"age": 35}}
+	_, inx, _ := c.LogScoresWithFeatures([]string{"tall", "rich"}, f)
+	Assert(t, inx == 0)
+	scores, _, _ := c.LogScoresWithFeatures(nil, f)
+	Assert(t, scores[0] > -100, scores)
+}
+
+func TestUnlearnFeatures(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithSlidingWindow(2, 0))
+	f := Features{Numeric: map[string]float64{"age": 30}, Categorical: map[string]string{"country": "nl"}}
+	c.LearnWithFeatures([]string{"tall"}, f, Good)
+	c.LearnWithFeatures([]string{"rich"}, Features{Numeric: map[string]float64{"age": 50}}, Good)
+	f.Numeric["age"] = 0 // the window keeps a copy
+	c.LearnWithFeatures([]string{"poor"}, Features{Numeric: map[string]float64{"age": 70}}, Bad)
+	g := c.datas[Good].Numeric["age"]
+	Assert(t, g.N == 1 && g.Mean == 50 && math.Abs(g.M2) < 1e-9, g)
+	Assert(t, len(c.datas[Good].Categories) == 0, c.datas[Good].Categories)
+
+	err := c.UnlearnWithFeatures([]string{"rich"}, Features{Numeric: map[string]float64{"age": 50}}, Good)
+	Assert(t, err == nil, err)
+	Assert(t, len(c.datas[Good].Numeric) == 0, c.datas[Good].Numeric)
+	err = c.UnlearnWithFeatures([]string{"rich"}, Features{Numeric: map[string]float64{"age": 70}}, Bad)
+	Assert(t, err == ErrNotLearned, err)
+	Assert(t, c.datas[Bad].Numeric["age"].N == 1)
+
+	c.LearnWithFeatures([]string{"poor"}, Features{Categorical: map[string]string{"country": "xx"}}, Bad)
+	var buf bytes.Buffer
+	Assert(t, c.WriteMsgpack(&buf) == nil)
+	d, err := NewClassifierFromMsgpack(&buf)
+	Assert(t, err == nil, err)
+	Assert(t, *d.datas[Bad].Numeric["age"] == *c.datas[Bad].Numeric["age"], d.datas[Bad].Numeric)
+	Assert(t, d.datas[Bad].Categories["country"]["xx"] == 1, d.datas[Bad].Categories)
+}
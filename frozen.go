@@ -0,0 +1,288 @@
+package bayesian
+
+import (
+	"math"
+	"strings"
+)
+
+// Frozen is a read-only snapshot of a classifier, taken with
+// c.Snapshot(), that holds precomputed log probabilities. It
+// does not change when the classifier goes on learning, and can
+// be used to classify documents from many goroutines at once.
+type Frozen struct {
+	Classes             []Class
+	logPriors           []float64            // including class weights and biases
+	logProbs            []map[string]float64 // nil with a perfect hash
+	hash                *perfectHash         // nil without a perfect hash
+	hashedProbs         []float64            // log probability of each slot and class, NaN if unseen
+	hashedProbs32       []float32            // hashedProbs with WithFloat32Counts
+	logUnseen           []float64
+	estimator           Estimator // asked about unseen words, nil if none
+	eligible            []bool
+	lengthMeans         []float64          // nil without a length model
+	idfWeights          map[string]float64 // nil without IDF weighting
+	featureWeights      map[string]float64
+	unseenIdf           float64
+	maxWordContribution float64
+	namespaceSep        string
+	namespaceCaps       map[string]float64
+	expander            Expander
+	minWordLength       int
+	stopwords           map[string]bool
+	skipOOV             bool
+	binarized           bool
+	ngramMin            int
+	ngramMax            int
+	temperature         float64
+	tiePolicy           tiePolicy
+}
+
+// Snapshot returns a Frozen snapshot of the classifier, which
+// classifies documents the same way as c does at the time of
+// the call. Snapshots can be taken periodically while c goes
+// on learning. Usage statistics are not recorded for documents
+// classified with the snapshot. If c has an Estimator, see
+// WithEstimator, the snapshot asks it about the words outside
+// the vocabulary of c, so it must be safe for concurrent use.
+func (c *Classifier) Snapshot() *Frozen {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling Snapshot.")
+	}
+	n := len(c.Classes)
+	f := &Frozen{
+		Classes:             append([]Class(nil), c.Classes...),
+		logPriors:           make([]float64, n),
+		logProbs:            make([]map[string]float64, n),
+		logUnseen:           make([]float64, n),
+		eligible:            make([]bool, n),
+		maxWordContribution: c.maxWordContribution,
+		namespaceSep:        c.namespaceSep,
+		expander:            c.expander,
+		minWordLength:       c.minWordLength,
+		skipOOV:             c.skipOOV,
+		binarized:           c.binarized,
+		ngramMin:            c.ngramMin,
+		ngramMax:            c.ngramMax,
+		temperature:         c.temperature,
+	}
+	priors := c.getPriors()
+	f.tiePolicy = tiePolicy{
+		tieBreak:   c.tieBreak,
+		tieEpsilon: c.tieEpsilon,
+		tieBreaker: restoreTieBreaker(c.tieBreak, c.tieBreaker.getSeed()),
+		classes:    f.Classes,
+		priors:     func() []float64 { return priors },
+	}
+	for index, class := range c.Classes {
+		f.logPriors[index] = math.Log(priors[index]) + c.logBias(class)
+		f.logUnseen[index] = math.Log(c.unseenProb(class))
+		f.eligible[index] = c.IsEligible(class)
+		if data := c.datas[class]; c.lengthModel && data.Docs > 0 {
+			if f.lengthMeans == nil {
+				f.lengthMeans = make([]float64, n)
+			}
+			f.lengthMeans[index] = data.Length / float64(data.Docs)
+		}
+		words := c.datas[class].Freqs
+		if c.complementNB {
+			f.logUnseen[index] = -math.Log(c.complementProb(class, 0))
+			words = c.getCorpus().freqs
+		} else if c.jmLambda > 0 || c.dirichletMu > 0 || c.estimator != nil {
+			words = c.getCorpus().freqs
+			f.estimator = c.estimator
+		}
+		logProbs := make(map[string]float64, len(words))
+		for word := range words {
+			logProbs[word] = c.wordLogProb(class, word)
+		}
+		f.logProbs[index] = logProbs
+	}
+	if c.idfWeighting {
+		f.idfWeights = make(map[string]float64, len(c.docFreqs))
+		for word := range c.docFreqs {
+			f.idfWeights[word] = c.idf(word)
+		}
+		f.unseenIdf = math.Log(float64(1+c.idfDocs)) + 1
+	}
+	if c.featureWeights != nil {
+		f.featureWeights = copyFreqs(c.featureWeights)
+	}
+	if c.stopwords != nil {
+		f.stopwords = make(map[string]bool, len(c.stopwords))
+		for word := range c.stopwords {
+			f.stopwords[word] = true
+		}
+	}
+	if c.namespaceCaps != nil {
+		f.namespaceCaps = make(map[string]float64, len(c.namespaceCaps))
+		for ns, bound := range c.namespaceCaps {
+			f.namespaceCaps[ns] = bound
+		}
+	}
+	return f
+}
+
+// LogScores works the same as the LogScores method of the
+// classifier the snapshot was taken of.
+func (f *Frozen) LogScores(document []string) (scores []float64, inx int, strict bool) {
+	if f.ngramMax > 0 {
+		document = NGrams(document, f.ngramMin, f.ngramMax)
+	}
+	document = dropIgnored(expandDocument(f.expander, document), f.minWordLength, f.stopwords)
+	if f.skipOOV {
+		document = f.dropOOV(document)
+	}
+	if f.binarized {
+		document = dedupe(document)
+	}
+	slots := f.slots(document)
+	scores = make([]float64, len(f.Classes))
+	for index := range f.Classes {
+		if !f.eligible[index] {
+			scores[index] = math.Inf(-1)
+			continue
+		}
+		scores[index] = f.logScore(index, document, slots)
+		if f.lengthMeans != nil && f.lengthMeans[index] > 0 {
+			scores[index] += poissonLogProb(len(document), f.lengthMeans[index])
+		}
+	}
+	inx, strict = f.tiePolicy.pickMax(scores)
+	return scores, inx, strict
+}
+
+// ProbScores works the same as the ProbScores method of the
+// classifier the snapshot was taken of.
+func (f *Frozen) ProbScores(document []string) (scores []float64, inx int, strict bool) {
+	scores, _, _ = f.LogScores(document)
+	scores = probs(temper(scores, f.temperature))
+	inx, strict = f.tiePolicy.pickMax(scores)
+	return scores, inx, strict
+}
+
+// slots returns the slot of each word of the document in the
+// perfect hash, or -1 for words outside the vocabulary, so that
+// each word is hashed once for all classes. It returns nil
+// without a perfect hash.
+func (f *Frozen) slots(document []string) []int {
+	if f.hash == nil {
+		return nil
+	}
+	slots := make([]int, len(document))
+	for i, word := range document {
+		slot, ok := f.hash.slot(word)
+		if !ok {
+			slot = -1
+		}
+		slots[i] = slot
+	}
+	return slots
+}
+
+// logProb returns the log probability of the word in the class
+// with the given index, and false if the class has none. With a
+// perfect hash, the word is looked up by its slot, which is -1
+// outside the vocabulary.
+func (f *Frozen) logProb(index int, word string, slot int) (float64, bool) {
+	if f.hash == nil {
+		logProb, ok := f.logProbs[index][word]
+		return logProb, ok
+	}
+	if slot < 0 {
+		return 0, false
+	}
+	var logProb float64
+	if f.hashedProbs32 != nil {
+		logProb = float64(f.hashedProbs32[slot*len(f.Classes)+index])
+	} else {
+		logProb = f.hashedProbs[slot*len(f.Classes)+index]
+	}
+	return logProb, !math.IsNaN(logProb)
+}
+
+// wordScore returns the weighted log probability of the word,
+// with the given slot, in the class with the given index.
+func (f *Frozen) wordScore(index int, word string, slot int) float64 {
+	logProb, ok := f.logProb(index, word, slot)
+	if !ok && f.estimator != nil {
+		logProb = math.Log(f.estimator.WordProb(f.Classes[index], word))
+	} else if !ok {
+		logProb = f.logUnseen[index]
+	}
+	if weight, ok := f.featureWeights[word]; ok {
+		logProb *= weight
+	}
+	if f.idfWeights == nil {
+		return logProb
+	}
+	if weight, ok := f.idfWeights[word]; ok {
+		return weight * logProb
+	}
+	return f.unseenIdf * logProb
+}
+
+// logScore mirrors Classifier.logScore.
+func (f *Frozen) logScore(index int, document []string, slots []int) float64 {
+	score := f.logPriors[index]
+	if f.maxWordContribution <= 0 && len(f.namespaceCaps) == 0 {
+		for i, word := range document {
+			score += f.wordScore(index, word, slotOf(slots, i))
+		}
+		return score
+	}
+	contribs := make(map[string]float64)
+	words := make([]string, 0)
+	for i, word := range document {
+		if _, ok := contribs[word]; !ok {
+			words = append(words, word)
+		}
+		contribs[word] += f.wordScore(index, word, slotOf(slots, i))
+	}
+	var namespaces []string
+	nsScores := make(map[string]float64)
+	for _, word := range words {
+		contrib := contribs[word]
+		if f.maxWordContribution > 0 {
+			contrib = math.Max(contrib, -f.maxWordContribution)
+		}
+		if i := strings.Index(word, f.namespaceSep); len(f.namespaceCaps) > 0 && i >= 0 {
+			if _, ok := f.namespaceCaps[word[:i]]; ok {
+				if _, seen := nsScores[word[:i]]; !seen {
+					namespaces = append(namespaces, word[:i])
+				}
+				nsScores[word[:i]] += contrib
+				continue
+			}
+		}
+		score += contrib
+	}
+	for _, ns := range namespaces {
+		score += math.Max(nsScores[ns], -f.namespaceCaps[ns])
+	}
+	return score
+}
+
+// slotOf returns the slot of the i-th word given by slots, or -1
+// if slots is nil.
+func slotOf(slots []int, i int) int {
+	if slots == nil {
+		return -1
+	}
+	return slots[i]
+}
+
+// dropOOV returns the words of the document that the snapshot
+// has a probability for in some class.
+func (f *Frozen) dropOOV(document []string) []string {
+	known := make([]string, 0, len(document))
+	slots := f.slots(document)
+	for i, word := range document {
+		for index := range f.Classes {
+			if _, ok := f.logProb(index, word, slotOf(slots, i)); ok {
+				known = append(known, word)
+				break
+			}
+		}
+	}
+	return known
+}
@@ -0,0 +1,26 @@
+package bayesian
+
+import "testing"
+import "math"
+
+func TestSnapshot(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithIdfWeighting(), WithMaxWordContribution(20))
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.SetClassBlocklist(Good, "rich")
+	c.SetClassWeight(Bad, 0.5)
+	f := c.Snapshot()
+	c.Learn([]string{"rich", "poor"}, Bad)
+
+	doc := []string{"tall", "rich", "girl", "tall"}
+	scores, inx, strict := f.LogScores(doc)
+	c.Unlearn([]string{"rich", "poor"}, Bad)
+	want, wantInx, _ := c.LogScores(doc)
+	Assert(t, inx == wantInx && strict, scores)
+	for i := range scores {
+		Assert(t, math.Abs(scores[i]-want[i]) < 1e-9, scores, want)
+	}
+	probs, _, _ := f.ProbScores(doc)
+	Assert(t, math.Abs(probs[0]+probs[1]-1) < 1e-9, probs)
+}
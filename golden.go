@@ -0,0 +1,97 @@
+package bayesian
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// ErrGoldenMismatch is returned when a classifier no longer
+// produces the outputs recorded in a golden fixture.
+var ErrGoldenMismatch = errors.New("classifier output differs from golden fixture")
+
+// golden is a golden fixture: a serialized model, a set of
+// documents and the log scores the model produced for them.
+type golden struct {
+	Model     []byte
+	Documents [][]string
+	Scores    [][]float64
+}
+
+// SaveGoldenFile records a golden fixture to a file; see
+// SaveGolden.
+func SaveGoldenFile(name string, c *Classifier, docs [][]string) (err error) {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return SaveGolden(file, c, docs)
+}
+
+// SaveGolden records the classifier together with the documents
+// and the log scores it currently produces for them as a golden
+// fixture. A test can later verify with CheckGolden that the
+// recorded model still produces the same outputs, for instance
+// after upgrading this package.
+func SaveGolden(w io.Writer, c *Classifier, docs [][]string) (err error) {
+	var model bytes.Buffer
+	if err = c.WriteTo(&model); err != nil {
+		return
+	}
+	g := &golden{Model: model.Bytes(), Documents: docs}
+	for _, doc := range docs {
+		g.Scores = append(g.Scores, c.logScores(doc))
+	}
+	enc := gob.NewEncoder(w)
+	return enc.Encode(g)
+}
+
+// CheckGoldenFile verifies a golden fixture stored in a file;
+// see CheckGolden.
+func CheckGoldenFile(name string, tolerance float64) (err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return CheckGolden(file, tolerance)
+}
+
+// CheckGolden loads a golden fixture written with SaveGolden,
+// scores its documents with its model and returns an error
+// wrapping ErrGoldenMismatch if any log score differs from the
+// recorded one by more than the tolerance, or if the most
+// likely class changed.
+func CheckGolden(r io.Reader, tolerance float64) (err error) {
+	dec := gob.NewDecoder(r)
+	g := new(golden)
+	if err = dec.Decode(g); err != nil {
+		return
+	}
+	c, err := NewClassifierFromReader(bytes.NewReader(g.Model))
+	if err != nil {
+		return
+	}
+	for i, doc := range g.Documents {
+		scores := c.logScores(doc)
+		inx, _ := findMax(scores)
+		if expected, _ := findMax(g.Scores[i]); inx != expected {
+			return fmt.Errorf("%w: document %d classified as %v, expected %v",
+				ErrGoldenMismatch, i, c.Classes[inx], c.Classes[expected])
+		}
+		for j, score := range scores {
+			if math.Abs(score-g.Scores[i][j]) > tolerance {
+				return fmt.Errorf("%w: document %d scored %v for %v, expected %v",
+					ErrGoldenMismatch, i, score, c.Classes[j], g.Scores[i][j])
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,30 @@
+package bayesian
+
+import "testing"
+import "bytes"
+import "encoding/gob"
+import "errors"
+
+func TestGolden(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	docs := [][]string{{"tall", "man"}, {"poor", "girl"}}
+	var buf bytes.Buffer
+	err := SaveGolden(&buf, c, docs)
+	Assert(t, err == nil, "could not save:", err)
+	data := buf.Bytes()
+	err = CheckGolden(bytes.NewReader(data), 0)
+	Assert(t, err == nil, "golden check failed:", err)
+
+	// a fixture with different expectations must fail
+	g := new(golden)
+	gob.NewDecoder(bytes.NewReader(data)).Decode(g)
+	g.Scores[1][0] += 1e-6
+	buf.Reset()
+	gob.NewEncoder(&buf).Encode(g)
+	err = CheckGolden(bytes.NewReader(buf.Bytes()), 1e-9)
+	Assert(t, errors.Is(err, ErrGoldenMismatch), "mismatch not detected:", err)
+	err = CheckGolden(bytes.NewReader(buf.Bytes()), 1e-3)
+	Assert(t, err == nil, "tolerance not applied:", err)
+}
@@ -0,0 +1,87 @@
+// Package gonumadapter converts the term-class and term-document
+// matrices of github.com/jbrukh/bayesian classifiers to and from
+// gonum matrices, so that the data behind a classifier can be
+// analyzed with gonum, for instance by SVD or clustering. It is
+// a separate package so that the classifier does not depend on
+// gonum.
+package gonumadapter
+
+import (
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/jbrukh/bayesian"
+)
+
+// TermClassMatrix returns the word counts of the classifier as a
+// matrix with a row for each of the terms and a column for each
+// of c.Classes, see c.TermClassMatrix(). The matrix is nil if the
+// classifier has counted no words.
+func TermClassMatrix(c *bayesian.Classifier) (terms []string, m *mat.Dense) {
+	terms, rows, cols, vals := c.TermClassMatrix()
+	return terms, dense(len(terms), len(c.Classes), rows, cols, vals)
+}
+
+// TermDocumentMatrix returns the documents as a matrix with a row
+// for each of the terms and a column for each document, see
+// c.TermDocumentMatrix(docs). The rows match those of
+// TermClassMatrix. The matrix is nil if there are no terms or no
+// documents.
+func TermDocumentMatrix(c *bayesian.Classifier, docs [][]string) (terms []string, m *mat.Dense) {
+	terms, rows, cols, vals := c.TermDocumentMatrix(docs)
+	return terms, dense(len(terms), len(docs), rows, cols, vals)
+}
+
+// ObserveMatrix adds the counts of a term-class matrix, such as
+// one returned by TermClassMatrix, to the classifier, see
+// c.ObserveMatrix. Row i holds the counts of terms[i].
+func ObserveMatrix(c *bayesian.Classifier, terms []string, m mat.Matrix) {
+	rows, cols, vals := coordinates(m)
+	c.ObserveMatrix(terms, rows, cols, vals)
+}
+
+// LearnMatrix learns the columns of a term-document matrix, such
+// as one returned by TermDocumentMatrix, as documents of the
+// classes at the same index, see c.LearnMatrix. Row i holds the
+// counts of terms[i]. It panics if a column has no class.
+func LearnMatrix(c *bayesian.Classifier, terms []string, m mat.Matrix, classes []bayesian.Class) {
+	rows, cols, vals := coordinates(m)
+	c.LearnMatrix(terms, rows, cols, vals, classes)
+}
+
+// dense returns the matrix of the given size with the entries
+// given in coordinate form, or nil if it would be empty, which
+// gonum does not allow.
+func dense(r, c int, rows, cols []int, vals []float64) *mat.Dense {
+	if r == 0 || c == 0 {
+		return nil
+	}
+	m := mat.NewDense(r, c, nil)
+	for k, v := range vals {
+		m.Set(rows[k], cols[k], v)
+	}
+	return m
+}
+
+// coordinates returns the non-zero entries of the matrix in
+// coordinate form, visiting only those of sparse matrices that
+// implement mat.NonZeroDoer.
+func coordinates(m mat.Matrix) (rows, cols []int, vals []float64) {
+	add := func(i, j int, v float64) {
+		rows = append(rows, i)
+		cols = append(cols, j)
+		vals = append(vals, v)
+	}
+	if nz, ok := m.(mat.NonZeroDoer); ok {
+		nz.DoNonZero(add)
+		return
+	}
+	r, c := m.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if v := m.At(i, j); v != 0 {
+				add(i, j, v)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package gonumadapter
+
+import "testing"
+
+import "github.com/jbrukh/bayesian"
+
+const (
+	Good bayesian.Class = "good"
+	Bad  bayesian.Class = "bad"
+)
+
+func TestTermClassMatrix(t *testing.T) {
+	c := bayesian.NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "rich", "tall"}, Good)
+	c.Learn([]string{"poor", "tall"}, Bad)
+	terms, m := TermClassMatrix(c)
+	r, cols := m.Dims()
+	if len(terms) != 3 || r != 3 || cols != 2 || terms[2] != "tall" || m.At(2, 0) != 2 || m.At(2, 1) != 1 {
+		t.Fatal(terms, m)
+	}
+
+	d := bayesian.NewClassifier(Good, Bad)
+	ObserveMatrix(d, terms, m)
+	freqs := d.WordFrequencies([]string{"tall", "poor"})
+	if freqs[0][0] != 2.0/3 || freqs[1][1] != 1.0/2 {
+		t.Fatal(freqs)
+	}
+
+	if _, m = TermClassMatrix(bayesian.NewClassifier(Good, Bad)); m != nil {
+		t.Fatal("expected no matrix for an empty classifier")
+	}
+}
+
+func TestTermDocumentMatrix(t *testing.T) {
+	c := bayesian.NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "rich", "tall"}, Good)
+	c.Learn([]string{"poor", "tall"}, Bad)
+	terms, m := TermDocumentMatrix(c, [][]string{{"tall", "tall", "girl"}, {"poor", "rich"}})
+	r, cols := m.Dims()
+	if r != len(terms) || cols != 2 || m.At(2, 0) != 2 || m.At(0, 1) != 1 || m.At(1, 1) != 1 {
+		t.Fatal(terms, m)
+	}
+
+	d := bayesian.NewClassifier(Good, Bad)
+	LearnMatrix(d, terms, m, []bayesian.Class{Good, Bad})
+	if d.Learned() != 2 {
+		t.Fatal(d.Learned())
+	}
+	freqs := d.WordFrequencies([]string{"tall", "rich"})
+	if freqs[0][0] != 1 || freqs[1][1] != 1.0/2 {
+		t.Fatal(freqs)
+	}
+}
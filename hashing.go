@@ -0,0 +1,212 @@
+package bayesian
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// ErrNoBuckets is returned when creating a hashing classifier
+// with fewer than one bucket.
+var ErrNoBuckets = errors.New("provide at least one bucket")
+
+// HashingClassifier is a classifier for unbounded vocabularies,
+// using the hashing trick: words are mapped to a fixed number of
+// buckets with ShardOf, and the counts of each class are kept in
+// a flat slice of buckets rather than a map of words, so that
+// its memory does not grow with the vocabulary. Words of the
+// same bucket share their counts, so the number of buckets,
+// e.g. 1<<20, trades memory for accuracy.
+type HashingClassifier struct {
+	Classes   []Class
+	freqs     [][]float64 // freqs[j][b] is the count of bucket b in class j
+	totals    []float64
+	smoothing float64 // additive smoothing alpha, 0 if disabled
+}
+
+// serializableHashing is the gob representation of a
+// HashingClassifier.
+type serializableHashing struct {
+	Classes   []Class
+	Freqs     [][]float64
+	Totals    []float64
+	Smoothing float64
+}
+
+// NewHashingClassifier returns a new hashing classifier with the
+// given number of buckets. The classes provided should be at
+// least 2 in number and unique, or this method will panic.
+func NewHashingClassifier(buckets int, classes ...Class) (h *HashingClassifier) {
+	h, err := NewHashingClassifierSafe(buckets, classes...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return
+}
+
+// NewHashingClassifierSafe works the same as NewHashingClassifier,
+// but returns ErrNoBuckets if there are fewer than one bucket, and
+// the errors of NewClassifierOpts if the classes are invalid,
+// instead of panicking.
+func NewHashingClassifierSafe(buckets int, classes ...Class) (h *HashingClassifier, err error) {
+	if buckets < 1 {
+		return nil, fmt.Errorf("%w, got %d", ErrNoBuckets, buckets)
+	}
+	if err = checkClasses(classes); err != nil {
+		return nil, err
+	}
+	h = &HashingClassifier{
+		Classes: append([]Class(nil), classes...),
+		freqs:   make([][]float64, len(classes)),
+		totals:  make([]float64, len(classes)),
+	}
+	for j := range h.freqs {
+		h.freqs[j] = make([]float64, buckets)
+	}
+	return
+}
+
+// NewHashingClassifierFromFile loads an existing hashing
+// classifier from file, as saved with h.WriteToFile(string).
+func NewHashingClassifierFromFile(name string) (h *HashingClassifier, err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return NewHashingClassifierFromReader(file)
+}
+
+// NewHashingClassifierFromReader decodes a Gob encoded hashing
+// classifier.
+func NewHashingClassifierFromReader(r io.Reader) (h *HashingClassifier, err error) {
+	w := new(serializableHashing)
+	if err = gob.NewDecoder(r).Decode(w); err != nil {
+		return nil, err
+	}
+	if len(w.Freqs) != len(w.Classes) || len(w.Totals) != len(w.Classes) || len(w.Freqs) == 0 {
+		return nil, errors.New("invalid hashing classifier data")
+	}
+	for _, freqs := range w.Freqs {
+		if len(freqs) != len(w.Freqs[0]) || len(freqs) == 0 {
+			return nil, errors.New("invalid hashing classifier data")
+		}
+	}
+	return &HashingClassifier{
+		Classes:   w.Classes,
+		freqs:     w.Freqs,
+		totals:    w.Totals,
+		smoothing: w.Smoothing,
+	}, nil
+}
+
+// WriteToFile serializes this hashing classifier to a file.
+func (h *HashingClassifier) WriteToFile(name string) (err error) {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = h.WriteTo(file)
+	return
+}
+
+// WriteTo serializes this hashing classifier to GOB and writes
+// it to the Writer.
+func (h *HashingClassifier) WriteTo(w io.Writer) (n int64, err error) {
+	cw := &countingWriter{w: w}
+	err = gob.NewEncoder(cw).Encode(&serializableHashing{
+		Classes:   h.Classes,
+		Freqs:     h.freqs,
+		Totals:    h.totals,
+		Smoothing: h.smoothing,
+	})
+	return cw.n, err
+}
+
+// SetSmoothing estimates the probability of each bucket in a
+// class with additive smoothing, (n+alpha)/(N_j+alpha*B), where B
+// is the number of buckets, see WithSmoothing. A value of 0
+// disables smoothing, which is the default; it panics if alpha
+// is negative.
+func (h *HashingClassifier) SetSmoothing(alpha float64) {
+	if alpha < 0 || math.IsNaN(alpha) {
+		panic("smoothing alpha must not be negative")
+	}
+	h.smoothing = alpha
+}
+
+// Buckets returns the number of buckets.
+func (h *HashingClassifier) Buckets() int {
+	return len(h.freqs[0])
+}
+
+// Learn will train the classifier on the provided document. It
+// panics if the classifier does not have the class.
+func (h *HashingClassifier) Learn(document []string, which Class) {
+	j := h.classIndex(which)
+	for _, word := range document {
+		h.freqs[j][ShardOf(word, h.Buckets())]++
+	}
+	h.totals[j] += float64(len(document))
+}
+
+// LogScores works the same as c.LogScores, with the counts of
+// the bucket of each word. Tied classes are not broken by a
+// policy, see WithTieBreak: the one declared first is picked.
+func (h *HashingClassifier) LogScores(document []string) (scores []float64, inx int, strict bool) {
+	n := len(h.Classes)
+	scores = make([]float64, n, n)
+	sum := float64(0)
+	for _, total := range h.totals {
+		sum += total
+	}
+	buckets := make([]int, len(document))
+	for i, word := range document {
+		buckets[i] = ShardOf(word, h.Buckets())
+	}
+	for j := range h.Classes {
+		prior := 1 / float64(n)
+		if sum > 0 {
+			prior = h.totals[j] / sum
+		}
+		score := math.Log(prior)
+		for _, b := range buckets {
+			p := defaultProb
+			if h.smoothing > 0 {
+				p = (h.freqs[j][b] + h.smoothing) / (h.totals[j] + h.smoothing*float64(h.Buckets()))
+			} else if freq := h.freqs[j][b]; freq > 0 {
+				p = freq / h.totals[j]
+			}
+			score += math.Log(p)
+		}
+		scores[j] = score
+	}
+	inx, strict = findMax(scores)
+	return scores, inx, strict
+}
+
+// ProbScores works the same as LogScores, but delivers actual
+// probabilities, see c.ProbScores.
+func (h *HashingClassifier) ProbScores(document []string) (scores []float64, inx int, strict bool) {
+	scores, _, _ = h.LogScores(document)
+	scores = softmax(scores)
+	inx, strict = findMax(scores)
+	return scores, inx, strict
+}
+
+// classIndex returns the index of the class, and panics if the
+// classifier does not have the class.
+func (h *HashingClassifier) classIndex(class Class) int {
+	for j, cl := range h.Classes {
+		if cl == class {
+			return j
+		}
+	}
+	panic(fmt.Sprintf("%v: %q", ErrClassNotFound, class))
+}
@@ -0,0 +1,71 @@
+package bayesian
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestHashingClassifier(t *testing.T) {
+	h := NewHashingClassifier(1<<16, Good, Bad)
+	c := NewClassifier(Good, Bad)
+	for _, m := range []interface {
+		Learn([]string, Class)
+	}{h, c} {
+		m.Learn([]string{"tall", "handsome", "rich"}, Good)
+		m.Learn([]string{"bald", "poor", "ugly", "tall"}, Bad)
+	}
+	Assert(t, h.Buckets() == 1<<16)
+
+	// without collisions, the scores are the same
+	doc := []string{"tall", "rich", "girl"}
+	scores, inx, strict := h.LogScores(doc)
+	want, _, _ := c.LogScores(doc)
+	Assert(t, inx == 0 && strict, scores)
+	Assert(t, math.Abs(scores[0]-want[0]) < 1e-9 && math.Abs(scores[1]-want[1]) < 1e-9, scores, want)
+
+	probs, _, _ := h.ProbScores(doc)
+	Assert(t, math.Abs(probs[0]+probs[1]-1) < 1e-9, probs)
+
+	// with a single bucket, all words collide
+	one := NewHashingClassifier(1, Good, Bad)
+	one.Learn([]string{"tall"}, Good)
+	one.Learn([]string{"bald"}, Bad)
+	scores, _, strict = one.LogScores([]string{"tall"})
+	Assert(t, !strict, scores)
+}
+
+func TestHashingClassifierSafe(t *testing.T) {
+	_, err := NewHashingClassifierSafe(0, Good, Bad)
+	Assert(t, errors.Is(err, ErrNoBuckets), err)
+	_, err = NewHashingClassifierSafe(8, Good)
+	Assert(t, errors.Is(err, ErrTooFewClasses), err)
+	_, err = NewHashingClassifierSafe(8, Good, Good)
+	Assert(t, errors.Is(err, ErrDuplicateClass), err)
+	h, err := NewHashingClassifierSafe(8, Good, Bad)
+	Assert(t, err == nil && h.Buckets() == 8, err)
+}
+
+func TestHashingSmoothing(t *testing.T) {
+	h := NewHashingClassifier(1<<16, Good, Bad)
+	h.SetSmoothing(1)
+	docs := [][]string{{"tall", "handsome", "rich"}, {"bald", "poor", "ugly", "tall"}}
+	h.Learn(docs[0], Good)
+	h.Learn(docs[1], Bad)
+	scores, _, _ := h.LogScores([]string{"tall", "girl"})
+	b := float64(h.Buckets())
+	want := math.Log(3.0/7) + math.Log((1+1)/(3+b)) + math.Log((0+1)/(3+b))
+	Assert(t, math.Abs(scores[0]-want) < 1e-9, scores[0], want)
+
+	var buf bytes.Buffer
+	n, err := h.WriteTo(&buf)
+	Assert(t, err == nil && n == int64(buf.Len()), err, n)
+	loaded, err := NewHashingClassifierFromReader(&buf)
+	Assert(t, err == nil, err)
+	again, _, _ := loaded.LogScores([]string{"tall", "girl"})
+	Assert(t, again[0] == scores[0] && again[1] == scores[1], again, scores)
+
+	_, err = NewHashingClassifierFromReader(bytes.NewReader([]byte("junk")))
+	Assert(t, err != nil)
+}
@@ -0,0 +1,113 @@
+package bayesian
+
+import "sort"
+
+// TermClassMatrix returns the word counts of the classifier as
+// a sparse term-class matrix in coordinate form: the count of
+// word terms[rows[k]] in class c.Classes[cols[k]] is vals[k].
+// The terms are sorted. This is the form accepted by sparse
+// matrix builders, so the model can be analyzed with linear
+// algebra packages; the gonumadapter package converts it to a
+// gonum matrix.
+func (c *Classifier) TermClassMatrix() (terms []string, rows, cols []int, vals []float64) {
+	index := make(map[string]int)
+	for _, class := range c.Classes {
+		for word := range c.datas[class].Freqs {
+			index[word] = 0
+		}
+	}
+	terms = make([]string, 0, len(index))
+	for word := range index {
+		terms = append(terms, word)
+	}
+	sort.Strings(terms)
+	for i, word := range terms {
+		index[word] = i
+	}
+	for j, class := range c.Classes {
+		freqs := c.datas[class].Freqs
+		for _, word := range terms {
+			if cnt, ok := freqs[word]; ok {
+				rows = append(rows, index[word])
+				cols = append(cols, j)
+				vals = append(vals, cnt*c.scale())
+			}
+		}
+	}
+	return
+}
+
+// ObserveMatrix adds the counts of a term-class matrix in the
+// coordinate form produced by c.TermClassMatrix() to the
+// classifier, as if each entry had been passed to Observe.
+func (c *Classifier) ObserveMatrix(terms []string, rows, cols []int, vals []float64) {
+	c.applyDecay()
+	for k, cnt := range vals {
+		data := c.datas[c.Classes[cols[k]]]
+		data.Freqs[terms[rows[k]]] += cnt
+		c.touch(data, terms[rows[k]])
+		data.Total += cnt
+	}
+	c.invalidate()
+}
+
+// TermDocumentMatrix returns the documents as a sparse
+// term-document matrix in coordinate form, with the same terms
+// as c.TermClassMatrix(), so that the rows of both matrices
+// match: the number of occurrences of word terms[rows[k]] in
+// docs[cols[k]] is vals[k]. The documents are split into n-grams
+// and filtered as when they are learned, and words the
+// classifier has not counted are left out.
+func (c *Classifier) TermDocumentMatrix(docs [][]string) (terms []string, rows, cols []int, vals []float64) {
+	terms = c.Vocabulary()
+	index := make(map[string]int, len(terms))
+	for i, word := range terms {
+		index[word] = i
+	}
+	for j, doc := range docs {
+		counts := make(map[string]int)
+		for _, word := range c.ngrams(doc) {
+			counts[word]++
+		}
+		c.filterCounts(counts)
+		words := make([]string, 0, len(counts))
+		for word := range counts {
+			if _, ok := index[word]; ok {
+				words = append(words, word)
+			}
+		}
+		sort.Strings(words)
+		for _, word := range words {
+			rows = append(rows, index[word])
+			cols = append(cols, j)
+			vals = append(vals, float64(counts[word]))
+		}
+	}
+	return
+}
+
+// LearnMatrix learns the columns of a term-document matrix in
+// the coordinate form produced by c.TermDocumentMatrix(docs):
+// column j is learned as a document of class classes[j], as if
+// it had been passed to Learn. It panics if a column has no
+// class.
+func (c *Classifier) LearnMatrix(terms []string, rows, cols []int, vals []float64, classes []Class) {
+	docs := make([]map[string]int, len(classes))
+	for k, cnt := range vals {
+		j := cols[k]
+		if j >= len(classes) {
+			panic("provide a class for each document")
+		}
+		if docs[j] == nil {
+			docs[j] = make(map[string]int)
+		}
+		docs[j][terms[rows[k]]] += int(cnt)
+	}
+	for j, counts := range docs {
+		if counts == nil {
+			counts = make(map[string]int)
+		}
+		c.learnCounts(counts, classes[j], 1)
+	}
+	c.invalidate()
+}
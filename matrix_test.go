@@ -0,0 +1,35 @@
+package bayesian
+
+import "testing"
+
+func TestTermClassMatrix(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "rich", "tall"}, Good)
+	c.Learn([]string{"poor", "tall"}, Bad)
+	terms, rows, cols, vals := c.TermClassMatrix()
+	Assert(t, len(terms) == 3 && terms[0] == "poor" && terms[2] == "tall", terms)
+	Assert(t, len(vals) == 4, vals)
+
+	d := NewClassifier(Good, Bad)
+	d.ObserveMatrix(terms, rows, cols, vals)
+	Assert(t, d.datas[Good].Freqs["tall"] == 2)
+	Assert(t, d.datas[Bad].Freqs["tall"] == 1)
+	Assert(t, d.datas[Good].Total == 3 && d.datas[Bad].Total == 2)
+}
+
+func TestTermDocumentMatrix(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "rich", "tall"}, Good)
+	c.Learn([]string{"poor", "tall"}, Bad)
+	docs := [][]string{{"tall", "tall", "girl"}, {"poor", "rich"}}
+	terms, rows, cols, vals := c.TermDocumentMatrix(docs)
+	classTerms, _, _, _ := c.TermClassMatrix()
+	Assert(t, len(terms) == len(classTerms) && terms[0] == classTerms[0], terms)
+	Assert(t, len(vals) == 3 && terms[rows[0]] == "tall" && cols[0] == 0 && vals[0] == 2, rows, cols, vals)
+	Assert(t, terms[rows[1]] == "poor" && cols[1] == 1 && terms[rows[2]] == "rich" && cols[2] == 1, rows, cols)
+
+	d := NewClassifier(Good, Bad)
+	d.LearnMatrix(terms, rows, cols, vals, []Class{Good, Bad})
+	Assert(t, d.Learned() == 2 && d.datas[Good].Freqs["tall"] == 2 && d.datas[Bad].Total == 2)
+	Assert(t, d.datas[Good].Docs == 1 && d.datas[Bad].Freqs["rich"] == 1)
+}
@@ -0,0 +1,187 @@
+package bayesian
+
+import "math"
+
+// Merge adds the training data of the other classifier to this
+// one, as if the documents learned by the other classifier had
+// been learned by this one, including the side features of its
+// documents, their document frequencies for WithIdfWeighting and
+// their lengths for WithBM25. Every class of the other classifier
+// must be a class of this one, or ErrClassNotFound is returned
+// and nothing is changed. ErrConverted is returned if either
+// classifier is a converted TF-IDF classifier.
+func (c *Classifier) Merge(other *Classifier) error {
+	c.applyDecay()
+	other.applyDecay()
+	if err := c.checkCompatible(other); err != nil {
+		return err
+	}
+	for _, class := range other.Classes {
+		data, src := c.datas[class], other.datas[class]
+		for word, cnt := range src.Freqs {
+			data.Freqs[word] += cnt
+			c.touch(data, word)
+		}
+		for word, tfs := range src.FreqTfs {
+			data.FreqTfs[word] = append(data.FreqTfs[word], tfs...)
+		}
+		data.Total += src.Total
+		data.Docs += src.Docs
+		data.Length += src.Length
+		mergeFeatures(data, src)
+	}
+	c.learned += other.learned
+	if other.docFreqs != nil {
+		if c.docFreqs == nil {
+			c.docFreqs = make(map[string]int, len(other.docFreqs))
+		}
+		for word, df := range other.docFreqs {
+			c.docFreqs[word] += df
+		}
+	}
+	c.idfDocs += other.idfDocs
+	c.bm25Words += other.bm25Words
+	c.bm25Docs += other.bm25Docs
+	c.invalidate()
+	return nil
+}
+
+// Subtract removes the training data of the other classifier
+// from this one, reversing a Merge; this allows a rolling model
+// to be maintained by merging the newest models and subtracting
+// expired ones. Counts that would become negative are removed,
+// as are the words whose counts drop to 0. Errors are returned
+// as for Merge.
+func (c *Classifier) Subtract(other *Classifier) error {
+	c.applyDecay()
+	other.applyDecay()
+	if err := c.checkCompatible(other); err != nil {
+		return err
+	}
+	for _, class := range other.Classes {
+		data, src := c.datas[class], other.datas[class]
+		for word, cnt := range src.Freqs {
+			have, ok := data.Freqs[word]
+			if !ok {
+				continue
+			}
+			if cnt >= have {
+				data.Total -= have
+				delete(data.Freqs, word)
+				delete(data.Updated, word)
+				continue
+			}
+			data.Freqs[word] -= cnt
+			data.Total -= cnt
+			c.touch(data, word)
+		}
+		for word, tfs := range src.FreqTfs {
+			for _, tf := range tfs {
+				data.FreqTfs[word] = removeSample(data.FreqTfs[word], tf)
+			}
+			if len(data.FreqTfs[word]) == 0 {
+				delete(data.FreqTfs, word)
+			}
+		}
+		if data.Total < 0 {
+			data.Total = 0
+		}
+		data.Docs -= src.Docs
+		if data.Docs < 0 {
+			data.Docs = 0
+		}
+		data.Length = math.Max(data.Length-src.Length, 0)
+		subtractFeatures(data, src)
+	}
+	c.learned -= other.learned
+	if c.learned < 0 {
+		c.learned = 0
+	}
+	for word, df := range other.docFreqs {
+		if c.docFreqs[word] <= df {
+			delete(c.docFreqs, word)
+			continue
+		}
+		c.docFreqs[word] -= df
+	}
+	c.idfDocs -= other.idfDocs
+	if c.idfDocs < 0 {
+		c.idfDocs = 0
+	}
+	c.bm25Docs -= other.bm25Docs
+	c.bm25Words = math.Max(c.bm25Words-other.bm25Words, 0)
+	if c.bm25Docs <= 0 {
+		c.bm25Docs = 0
+		c.bm25Words = 0
+	}
+	c.invalidate()
+	return nil
+}
+
+// mergeFeatures adds the side features learned by the class src
+// to the class data.
+func mergeFeatures(data, src *classData) {
+	for name, g := range src.Numeric {
+		if data.Numeric == nil {
+			data.Numeric = make(map[string]*gaussian)
+		}
+		if data.Numeric[name] == nil {
+			data.Numeric[name] = new(gaussian)
+		}
+		data.Numeric[name].combine(g)
+	}
+	for name, values := range src.Categories {
+		if data.Categories == nil {
+			data.Categories = make(map[string]map[string]float64)
+		}
+		if data.Categories[name] == nil {
+			data.Categories[name] = make(map[string]float64)
+		}
+		for value, cnt := range values {
+			data.Categories[name][value] += cnt
+		}
+	}
+}
+
+// subtractFeatures removes the side features learned by the
+// class src from the class data, reversing mergeFeatures.
+func subtractFeatures(data, src *classData) {
+	for name, g := range src.Numeric {
+		have, ok := data.Numeric[name]
+		if !ok {
+			continue
+		}
+		have.remove(g)
+		if have.N == 0 {
+			delete(data.Numeric, name)
+		}
+	}
+	for name, values := range src.Categories {
+		have := data.Categories[name]
+		for value, cnt := range values {
+			if have[value] <= cnt {
+				delete(have, value)
+				continue
+			}
+			have[value] -= cnt
+		}
+		if have != nil && len(have) == 0 {
+			delete(data.Categories, name)
+		}
+	}
+}
+
+// checkCompatible returns an error if the training data of the
+// other classifier cannot be merged into or subtracted from
+// this one.
+func (c *Classifier) checkCompatible(other *Classifier) error {
+	if (c.tfIdf && c.DidConvertTfIdf) || (other.tfIdf && other.DidConvertTfIdf) {
+		return ErrConverted
+	}
+	for _, class := range other.Classes {
+		if _, ok := c.datas[class]; !ok {
+			return ErrClassNotFound
+		}
+	}
+	return nil
+}
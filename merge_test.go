@@ -0,0 +1,60 @@
+package bayesian
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMergeSubtract(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	day1 := NewClassifier(Good, Bad)
+	day1.Learn([]string{"tall", "nice"}, Good)
+	day1.Learn([]string{"poor"}, Bad)
+
+	err := c.Merge(day1)
+	Assert(t, err == nil, err)
+	Assert(t, c.Learned() == 3 && c.datas[Good].Freqs["tall"] == 2 && c.datas[Good].Total == 5)
+	Assert(t, c.datas[Bad].Freqs["poor"] == 1 && c.datas[Bad].Docs == 1)
+
+	err = c.Subtract(day1)
+	Assert(t, err == nil, err)
+	Assert(t, c.Learned() == 1 && c.datas[Good].Freqs["tall"] == 1 && c.datas[Good].Total == 3)
+	_, ok := c.datas[Good].Freqs["nice"]
+	Assert(t, !ok && c.datas[Bad].Total == 0 && len(c.datas[Bad].Freqs) == 0)
+
+	// subtracting more than was counted does not go negative
+	err = c.Subtract(day1)
+	Assert(t, err == nil && c.datas[Good].Total == 2 && c.Learned() == 0, err)
+
+	other := NewClassifier(Good, "Ugly")
+	err = c.Merge(other)
+	Assert(t, err == ErrClassNotFound, err)
+}
+
+func TestMergeSubtractState(t *testing.T) {
+	c, _ := NewClassifierOpts([]Class{Good, Bad}, WithIdfWeighting(), WithBM25(1.2, 0.75))
+	c.LearnWithFeatures([]string{"tall", "rich"}, Features{Numeric: map[string]float64{"len": 2}, Categorical: map[string]string{"from": "us"}}, Good)
+	other, _ := NewClassifierOpts([]Class{Good, Bad}, WithIdfWeighting(), WithBM25(1.2, 0.75))
+	other.LearnWithFeatures([]string{"tall"}, Features{Numeric: map[string]float64{"len": 4}, Categorical: map[string]string{"from": "uk"}}, Good)
+	other.LearnWithFeatures([]string{"poor"}, Features{Numeric: map[string]float64{"len": 6}}, Good)
+
+	err := c.Merge(other)
+	Assert(t, err == nil, err)
+	Assert(t, c.docFreqs["tall"] == 2 && c.docFreqs["poor"] == 1 && c.idfDocs == 3, c.docFreqs)
+	Assert(t, c.bm25Docs == 3 && c.bm25Words == 4, c.bm25Docs, c.bm25Words)
+	g := c.datas[Good].Numeric["len"]
+	Assert(t, g.N == 3 && g.Mean == 4 && math.Abs(g.M2-8) < 1e-12, g)
+	Assert(t, c.datas[Good].Categories["from"]["uk"] == 1 && c.datas[Good].Categories["from"]["us"] == 1)
+
+	err = c.Subtract(other)
+	Assert(t, err == nil, err)
+	Assert(t, c.docFreqs["tall"] == 1 && c.idfDocs == 1, c.docFreqs)
+	_, ok := c.docFreqs["poor"]
+	Assert(t, !ok, "poor should have no document frequency")
+	Assert(t, c.bm25Docs == 1 && c.bm25Words == 2, c.bm25Docs, c.bm25Words)
+	g = c.datas[Good].Numeric["len"]
+	Assert(t, g.N == 1 && g.Mean == 2 && math.Abs(g.M2) < 1e-12, g)
+	_, ok = c.datas[Good].Categories["from"]["uk"]
+	Assert(t, !ok && c.datas[Good].Categories["from"]["us"] == 1)
+}
@@ -0,0 +1,1084 @@
+package bayesian
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// classData holds the frequency data for words in a
+// particular class. In the future, we may replace this
+// structure with a trie-like structure for more
+// efficient storage.
+type classData struct {
+	Freqs   map[string]float64
+	FreqTfs map[string][]float64
+	Total   float64
+	Docs    int                  // number of documents learned
+	Length  float64              // total length of the documents learned
+	Updated map[string]int64     // last change of each word count, in Unix nanoseconds
+	Tfs     map[string][]float64 // raw TF samples, kept by the TF-IDF conversion
+	Idf     float64              // IDF weight applied by the TF-IDF conversion
+	Counts  map[string]float64   // raw word counts, kept by the TF-IDF conversion
+	Dropped map[string]float64   // raw counts of the terms the TF-IDF conversion dropped
+
+	Numeric    map[string]*gaussian          // distribution of each numeric feature
+	Categories map[string]map[string]float64 // count of each value of each categorical feature
+}
+
+// newClassData creates a new empty classData node.
+func newClassData() *classData {
+	return &classData{
+		Freqs:   make(map[string]float64),
+		FreqTfs: make(map[string][]float64),
+	}
+}
+
+// getWordProb returns P(W|C_j) -- the probability of seeing
+// a particular word W in a document of this class.
+func (d *classData) getWordProb(word string) float64 {
+	value, ok := d.Freqs[word]
+	if !ok {
+		return defaultProb
+	}
+	return value / d.Total
+}
+
+// getWordsProb returns P(D|C_j) -- the probability of seeing
+// this set of words in a document of this class.
+//
+// Note that words should not be empty, and this method of
+// calulation is prone to underflow if there are many words
+// and their individual probabilties are small.
+func (d *classData) getWordsProb(words []string) (prob float64) {
+	prob = 1
+	for _, word := range words {
+		prob *= d.getWordProb(word)
+	}
+	return
+}
+
+// Observe should be used when word-frequencies have been already been learned
+// externally (e.g., hadoop)
+func (c *Classifier) Observe(word string, count int, which Class) {
+	c.applyDecay()
+	c.ensureClass(which)
+	data := c.datas[which]
+	data.Freqs[word] += float64(count)
+	c.touch(data, word)
+	data.Total += float64(count)
+	c.invalidate()
+}
+
+// ObserveErr works the same as Observe, but returns
+// ErrClassNotFound instead of panicking if the classifier does
+// not have the class, see WithAutoCreateClasses.
+func (c *Classifier) ObserveErr(word string, count int, which Class) error {
+	if err := c.checkClass(which); err != nil {
+		return err
+	}
+	c.Observe(word, count, which)
+	return nil
+}
+
+// ObserveDocument should be used when word-frequencies of a
+// number of documents have already been aggregated externally.
+// Unlike Observe, it also records the number of documents the
+// counts were aggregated from, so that the document counts
+// (and Learned) match those of a classifier that learned the
+// documents. The counts of a single document are learned the
+// same way as by Learn. The counts of several documents are
+// added at once, after the decay of WithAutoDecay for each of
+// them, with the ignored words left out; the documents are not
+// remembered by the sliding window, and since it is not known
+// how the counts are split between them, the counts are capped
+// at the number of documents times WithMaxTermCountPerDoc, and
+// for WithIdfWeighting a word is taken to occur in as many of
+// the documents as its count allows, which is exact with
+// WithBinarized. It panics for the counts of several documents
+// with TF-IDF or BM25, which need the counts of each document,
+// and if the classifier does not have the class, see
+// WithAutoCreateClasses.
+func (c *Classifier) ObserveDocument(freqs map[string]int, docCount int, which Class) {
+	counts := make(map[string]int, len(freqs))
+	for word, count := range freqs {
+		if count > 0 {
+			counts[word] = count
+		}
+	}
+	if docCount == 1 {
+		c.learnCounts(counts, which, 1)
+		c.invalidate()
+		return
+	}
+	if c.tfIdf || c.bm25 {
+		panic("Cannot observe the counts of several documents with TF-IDF or BM25. Observe them one at a time.")
+	}
+	c.ensureClass(which)
+	if c.autoDecay > 0 {
+		for i := 0; i < docCount; i++ {
+			c.Decay(c.autoDecay)
+		}
+	}
+	c.applyDecay()
+	data := c.datas[which]
+	for word, count := range counts {
+		if c.ignored(word) {
+			continue
+		}
+		if limit := c.termCountLimit(); limit > 0 && docCount > 0 && count > limit*docCount {
+			count = limit * docCount
+		}
+		data.Freqs[word] += float64(count)
+		data.Total += float64(count)
+		data.Length += float64(count)
+		c.touch(data, word)
+		if c.idfWeighting && docCount > 0 {
+			if c.docFreqs == nil {
+				c.docFreqs = make(map[string]int)
+			}
+			if count > docCount {
+				count = docCount
+			}
+			c.docFreqs[word] += count
+		}
+	}
+	if c.idfWeighting && docCount > 0 {
+		c.idfDocs += docCount
+	}
+	data.Docs += docCount
+	c.learned += docCount
+	c.invalidate()
+}
+
+// ObserveDocumentErr works the same as ObserveDocument, but
+// returns ErrClassNotFound instead of panicking if the
+// classifier does not have the class.
+func (c *Classifier) ObserveDocumentErr(freqs map[string]int, docCount int, which Class) error {
+	if err := c.checkClass(which); err != nil {
+		return err
+	}
+	c.ObserveDocument(freqs, docCount, which)
+	return nil
+}
+
+// Learn will accept new training documents for
+// supervised learning. It panics if the classifier does not
+// have the class, see WithAutoCreateClasses.
+func (c *Classifier) Learn(document []string, which Class) {
+	c.learn(document, which)
+	c.invalidate()
+}
+
+// LearnErr works the same as Learn, but returns ErrClassNotFound
+// instead of panicking if the classifier does not have the
+// class, see WithAutoCreateClasses, and ErrConverted if it is a
+// TF-IDF classifier that was already converted.
+func (c *Classifier) LearnErr(document []string, which Class) error {
+	if err := c.checkLearnable(which); err != nil {
+		return err
+	}
+	c.Learn(document, which)
+	return nil
+}
+
+// checkLearnable returns the error LearnErr returns if documents
+// of the class cannot be learned.
+func (c *Classifier) checkLearnable(which Class) error {
+	if err := c.checkClass(which); err != nil {
+		return err
+	}
+	if c.tfIdf && c.DidConvertTfIdf && !c.incrementalTfIdf {
+		return ErrConverted
+	}
+	return nil
+}
+
+// LearnBatch learns many documents at once: documents[i] is
+// learned as a document of class classes[i]. The slices must
+// have the same length, or this method will panic.
+func (c *Classifier) LearnBatch(documents [][]string, classes []Class) {
+	if len(documents) != len(classes) {
+		panic("provide a class for each document")
+	}
+	for i, document := range documents {
+		c.learn(document, classes[i])
+	}
+	c.invalidate()
+}
+
+// LearnWeighted works the same as Learn, but each word of the
+// document adds the given weight to the counts instead of 1, so
+// that documents can contribute fractional or multiplied
+// counts, for instance according to the confidence in their
+// label. It counts as one learned document. LearnWeighted
+// cannot be used with a TF-IDF classifier.
+func (c *Classifier) LearnWeighted(document []string, which Class, weight float64) {
+	if c.tfIdf {
+		panic("Cannot learn weighted documents with a TF-IDF classifier.")
+	}
+	c.learnWeighted(document, which, weight)
+	c.invalidate()
+}
+
+// learn adds the document to the counts of the class, without
+// invalidating the statistics cached from the counts.
+func (c *Classifier) learn(document []string, which Class) {
+	c.learnWeighted(document, which, 1)
+}
+
+// learnWeighted adds the document to the counts of the class
+// with the given weight, without invalidating the statistics
+// cached from the counts.
+func (c *Classifier) learnWeighted(document []string, which Class, weight float64) {
+	counts := make(map[string]int)
+	for _, word := range c.ngrams(document) {
+		counts[word]++
+	}
+	c.learnCounts(counts, which, weight)
+}
+
+// learnCounts adds a document, given as the number of
+// occurrences of each of its words, to the counts of the class
+// with the given weight, without invalidating the statistics
+// cached from the counts.
+func (c *Classifier) learnCounts(counts map[string]int, which Class, weight float64) {
+	c.ensureClass(which)
+	if c.autoDecay > 0 {
+		c.Decay(c.autoDecay)
+	}
+	// the counts are stored divided by the decay scale
+	weight /= c.scale()
+	docLen := c.filterCounts(counts)
+	data := c.datas[which]
+
+	// If we are a tfidf classifier we first need to get terms as
+	// terms frequency and store that to work out the idf part later
+	// in ConvertToIDF().
+	if c.tfIdf {
+		if c.DidConvertTfIdf && !c.incrementalTfIdf {
+			panic("Cannot call ConvertTermsFreqToTfIdf more than once. Reset and relearn to reconvert.")
+		}
+
+		for wIndex, wCount := range counts {
+			// Term Frequency: word count in document / document length
+			tf := float64(wCount) / float64(docLen)
+			if c.DidConvertTfIdf {
+				// keep the raw sample for RefreshTfIdf
+				data.Tfs[wIndex] = append(data.Tfs[wIndex], tf)
+				continue
+			}
+			// add the TF sample, after training we can get IDF values.
+			data.FreqTfs[wIndex] = append(data.FreqTfs[wIndex], tf)
+		}
+
+		if c.DidConvertTfIdf {
+			// the counts hold TF-IDF weights until RefreshTfIdf
+			for word, cnt := range counts {
+				data.Counts[word] += weight * float64(cnt)
+				if _, dropped := data.Dropped[word]; dropped {
+					data.Dropped[word] += weight * float64(cnt)
+				} else {
+					data.Total += weight * float64(cnt)
+				}
+			}
+			data.Length += float64(docLen)
+			data.Docs++
+			c.learned++
+			c.countDocFreqs(counts)
+			c.remember(windowEntry{counts: counts, class: which, weight: weight, length: float64(docLen)})
+			return
+		}
+	}
+
+	var amounts map[string]float64
+	if c.bm25 {
+		c.bm25Words += float64(docLen)
+		c.bm25Docs++
+		amounts = make(map[string]float64, len(counts))
+	}
+	for word, cnt := range counts {
+		if c.bm25 {
+			amounts[word] = c.bm25Weight(cnt, docLen)
+			data.Freqs[word] += weight * amounts[word]
+			data.Total += weight * amounts[word]
+		} else {
+			for i := 0; i < cnt; i++ {
+				data.Freqs[word] += weight
+				data.Total += weight
+			}
+		}
+		c.touch(data, word)
+	}
+	data.Length += float64(docLen)
+	data.Docs++
+	c.learned++
+	c.countDocFreqs(counts)
+	c.remember(windowEntry{counts: counts, amounts: amounts, class: which, weight: weight, length: float64(docLen)})
+}
+
+// filterCounts removes the ignored words from the counts of a
+// document and caps the counts of the others, see
+// WithMaxTermCountPerDoc, and returns the length of the document
+// that is left.
+func (c *Classifier) filterCounts(counts map[string]int) (docLen int) {
+	for word, cnt := range counts {
+		if c.ignored(word) {
+			delete(counts, word)
+			continue
+		}
+		if limit := c.termCountLimit(); limit > 0 && cnt > limit {
+			cnt = limit
+			counts[word] = cnt
+		}
+		docLen += cnt
+	}
+	return
+}
+
+// termCountLimit returns the maximum number of occurrences of a
+// term per learned document, 1 with WithBinarized, or 0 if there
+// is no limit.
+func (c *Classifier) termCountLimit() int {
+	if c.binarized {
+		return 1
+	}
+	return c.maxTermCount
+}
+
+// bm25Weight returns the BM25 weight of a word counted cnt times
+// in a document of docLen words, given the average length of the
+// documents learned so far, see WithBM25.
+func (c *Classifier) bm25Weight(cnt, docLen int) float64 {
+	avg := c.bm25Words / float64(c.bm25Docs)
+	norm := 1 - c.bm25B
+	if avg > 0 {
+		norm += c.bm25B * float64(docLen) / avg
+	}
+	return float64(cnt) * (c.bm25K1 + 1) / (float64(cnt) + c.bm25K1*norm)
+}
+
+// unlearnBM25 removes a document of docLen words from the
+// average document length of BM25.
+func (c *Classifier) unlearnBM25(docLen float64) {
+	if c.bm25Docs > 0 {
+		c.bm25Docs--
+	}
+	c.bm25Words = math.Max(c.bm25Words-docLen, 0)
+	if c.bm25Docs == 0 {
+		c.bm25Words = 0
+	}
+}
+
+// minDecayScale is the decay scale below which the scale is
+// applied to the stored counts, before they grow too large.
+const minDecayScale = 1e-100
+
+// Decay multiplies all word counts and class totals by the
+// factor, which should be between 0 and 1, so that the model
+// gradually forgets old training data in favor of new. Document
+// counts are not affected. The counts are stored divided by a
+// scale that Decay multiplies by the factor, so that it does
+// not need to visit every count.
+func (c *Classifier) Decay(factor float64) {
+	if factor <= 0 || factor > 1 {
+		panic("decay factor must be in (0, 1]")
+	}
+	c.decayScale = c.scale() * factor
+	if c.decayScale < minDecayScale {
+		c.applyDecay()
+	}
+	c.invalidate()
+}
+
+// scale returns the factor the stored word counts and class
+// totals are multiplied by, see Decay.
+func (c *Classifier) scale() float64 {
+	if c.decayScale == 0 {
+		return 1
+	}
+	return c.decayScale
+}
+
+// applyDecay multiplies the stored word counts and class totals
+// by the scale of Decay, and resets the scale to 1. It must be
+// called before the stored counts are changed other than by
+// learning documents.
+func (c *Classifier) applyDecay() {
+	scale := c.scale()
+	if scale == 1 {
+		return
+	}
+	for _, data := range c.datas {
+		for word := range data.Freqs {
+			data.Freqs[word] *= scale
+		}
+		for word := range data.Counts {
+			data.Counts[word] *= scale
+		}
+		for word := range data.Dropped {
+			data.Dropped[word] *= scale
+		}
+		data.Total *= scale
+	}
+	for i := range c.window {
+		c.window[i].weight *= scale
+	}
+	c.decayScale = 0
+	c.invalidate()
+}
+
+// Unlearn reverses a call to c.Learn(document, which),
+// subtracting the document from the counts of the class, for
+// instance when the document turns out to have been mislabeled.
+// It returns ErrNotLearned, without changing anything, if the
+// class does not hold the words of the document,
+// ErrClassNotFound if the classifier does not have the class,
+// and ErrConverted for a TF-IDF classifier that was already
+// converted. Documents learned with LearnWeighted must be
+// unlearned with UnlearnWeighted and the same weight.
+func (c *Classifier) Unlearn(document []string, which Class) error {
+	return c.unlearn(document, which, 1)
+}
+
+// UnlearnWeighted reverses a call to c.LearnWeighted(document,
+// which, weight), subtracting the weight of each word of the
+// document from the counts of the class. It returns the same
+// errors as Unlearn. UnlearnWeighted cannot be used with a
+// TF-IDF classifier.
+func (c *Classifier) UnlearnWeighted(document []string, which Class, weight float64) error {
+	if c.tfIdf {
+		panic("Cannot unlearn weighted documents with a TF-IDF classifier.")
+	}
+	return c.unlearn(document, which, weight)
+}
+
+// unlearnTolerance is the relative rounding error allowed when
+// the counts added by learning a document are subtracted again.
+const unlearnTolerance = 1e-9
+
+// unlearn implements Unlearn and UnlearnWeighted. The BM25
+// weights of a document are taken from the sliding window if it
+// remembers the document, and are computed from the current
+// average document length otherwise, see WithBM25.
+func (c *Classifier) unlearn(document []string, which Class, weight float64) error {
+	c.applyDecay()
+	data, ok := c.datas[which]
+	if !ok {
+		return ErrClassNotFound
+	}
+	if c.tfIdf && c.DidConvertTfIdf {
+		return ErrConverted
+	}
+	words := make(map[string]int)
+	for _, word := range c.ngrams(document) {
+		words[word]++
+	}
+	docLen := float64(c.filterCounts(words))
+	var bm25 map[string]float64
+	if c.bm25 {
+		if i := c.remembered(words, which); i >= 0 {
+			bm25 = c.window[i].amounts
+		}
+	}
+	amounts := make(map[string]float64, len(words))
+	for word, cnt := range words {
+		amount := weight * float64(cnt)
+		if c.bm25 {
+			perUnit, ok := bm25[word]
+			if !ok {
+				perUnit = c.bm25Weight(cnt, int(docLen))
+			}
+			amount = weight * perUnit
+		}
+		if data.Freqs[word] < amount*(1-unlearnTolerance) {
+			return ErrNotLearned
+		}
+		amounts[word] = amount
+	}
+
+	if c.tfIdf {
+		for word, cnt := range words {
+			data.FreqTfs[word] = removeSample(data.FreqTfs[word], float64(cnt)/docLen)
+			if len(data.FreqTfs[word]) == 0 {
+				delete(data.FreqTfs, word)
+			}
+		}
+	}
+	for word, amount := range amounts {
+		data.Freqs[word] -= amount
+		c.touch(data, word)
+		if data.Freqs[word] <= amount*unlearnTolerance {
+			delete(data.Freqs, word)
+			delete(data.Updated, word)
+		}
+		data.Total -= amount
+		if c.idfWeighting && c.docFreqs[word] > 0 {
+			c.docFreqs[word]--
+		}
+	}
+	if c.idfWeighting && c.idfDocs > 0 {
+		c.idfDocs--
+	}
+	if data.Docs > 0 {
+		data.Docs--
+	}
+	data.Length = math.Max(data.Length-docLen, 0)
+	if c.bm25 {
+		c.unlearnBM25(docLen)
+	}
+	c.learned--
+	c.unremember(words, which)
+	c.invalidate()
+	return nil
+}
+
+// removeSample removes one occurrence of the TF sample from the
+// samples, if it is present.
+func removeSample(samples []float64, sample float64) []float64 {
+	for i, s := range samples {
+		if s == sample {
+			return append(samples[:i], samples[i+1:]...)
+		}
+	}
+	return samples
+}
+
+// touch records that the count of the word in the class changed
+// now, if word timestamps are enabled.
+func (c *Classifier) touch(data *classData, word string) {
+	if !c.wordTimestamps {
+		return
+	}
+	if data.Updated == nil {
+		data.Updated = make(map[string]int64)
+	}
+	data.Updated[word] = c.now().UnixNano()
+}
+
+// PruneStale removes from each class the words whose count has
+// not changed for longer than the given duration, subtracting
+// their counts from the class totals, and returns the number
+// of entries removed. Only words counted while word timestamps
+// were enabled (see WithWordTimestamps) are considered.
+func (c *Classifier) PruneStale(olderThan time.Duration) (removed int) {
+	c.applyDecay()
+	cutoff := c.now().Add(-olderThan).UnixNano()
+	for _, class := range c.Classes {
+		data := c.datas[class]
+		for word, updated := range data.Updated {
+			if updated >= cutoff {
+				continue
+			}
+			data.Total -= data.Freqs[word]
+			delete(data.Freqs, word)
+			delete(data.FreqTfs, word)
+			delete(data.Updated, word)
+			removed++
+		}
+	}
+	if removed > 0 {
+		c.invalidate()
+	}
+	return
+}
+
+// Prune removes the words whose total count over all classes is
+// below minCount, such as the many words seen only once, which
+// take up much of the memory of large models but contribute
+// little to the classification, adjusting the class totals. It
+// returns the number of word counts removed, over all classes.
+// A TF-IDF classifier must be pruned before it is converted,
+// when its counts are still word counts, or Prune panics.
+func (c *Classifier) Prune(minCount int) (removed int) {
+	c.applyDecay()
+	if c.tfIdf && c.DidConvertTfIdf {
+		panic("Cannot prune a converted TF-IDF classifier. Prune before calling ConvertTermsFreqToTfIdf.")
+	}
+	for word, cnt := range c.getCorpus().freqs {
+		if cnt >= float64(minCount) {
+			continue
+		}
+		delete(c.docFreqs, word)
+		for _, class := range c.Classes {
+			data := c.datas[class]
+			if _, ok := data.Freqs[word]; !ok {
+				continue
+			}
+			data.Total -= data.Freqs[word]
+			delete(data.Freqs, word)
+			delete(data.FreqTfs, word)
+			delete(data.Updated, word)
+			removed++
+		}
+	}
+	if removed > 0 {
+		c.invalidate()
+	}
+	return
+}
+
+// countDocFreqs adds the document, given as the number of
+// occurrences of each of its words, to the document frequencies
+// of its words if IDF weighting is enabled.
+func (c *Classifier) countDocFreqs(counts map[string]int) {
+	if !c.idfWeighting {
+		return
+	}
+	if c.docFreqs == nil {
+		c.docFreqs = make(map[string]int)
+	}
+	for word := range counts {
+		c.docFreqs[word]++
+	}
+	c.idfDocs++
+}
+
+// countDocFeatures works the same as countDocFreqs, but for a
+// document given as a sparse feature vector.
+func (c *Classifier) countDocFeatures(vec map[string]float64) {
+	if !c.idfWeighting {
+		return
+	}
+	if c.docFreqs == nil {
+		c.docFreqs = make(map[string]int)
+	}
+	for word := range vec {
+		c.docFreqs[word]++
+	}
+	c.idfDocs++
+}
+
+// LearnVector accepts a new training document given as a
+// sparse feature vector, mapping each feature to its weight.
+// Each weight is added to the feature count as if the feature
+// had been seen that many times, so fractional and arbitrarily
+// extracted features can be learned. Like the words of a
+// document, ignored features are skipped (see WithStopwords and
+// WithMinWordLength), and the document is counted for
+// WithIdfWeighting and remembered for WithSlidingWindow. It
+// panics if the classifier does not have the class, see
+// WithAutoCreateClasses.
+//
+// LearnVector does not record TF samples and cannot be used
+// with a TF-IDF classifier.
+func (c *Classifier) LearnVector(vec map[string]float64, which Class) {
+	if c.tfIdf {
+		panic("Cannot learn sparse vectors with a TF-IDF classifier.")
+	}
+	c.ensureClass(which)
+	if c.autoDecay > 0 {
+		c.Decay(c.autoDecay)
+	}
+	// the counts are stored divided by the decay scale
+	scale := c.scale()
+	data := c.datas[which]
+	amounts := make(map[string]float64, len(vec))
+	length := float64(0)
+	for word, weight := range vec {
+		if c.ignored(word) {
+			continue
+		}
+		amounts[word] = weight
+		data.Freqs[word] += weight / scale
+		data.Total += weight / scale
+		c.touch(data, word)
+		length += weight
+	}
+	data.Length += length
+	data.Docs++
+	c.learned++
+	c.countDocFeatures(amounts)
+	c.remember(windowEntry{amounts: amounts, class: which, weight: 1 / scale, length: length})
+	c.invalidate()
+}
+
+// LearnVectorErr works the same as LearnVector, but returns
+// ErrClassNotFound instead of panicking if the classifier does
+// not have the class.
+func (c *Classifier) LearnVectorErr(vec map[string]float64, which Class) error {
+	if err := c.checkClass(which); err != nil {
+		return err
+	}
+	c.LearnVector(vec, which)
+	return nil
+}
+
+// corpus holds statistics about the words over all classes.
+type corpus struct {
+	freqs    map[string]float64 // count of each word over all classes
+	total    float64
+	vocab    int // size of the vocabulary, of the whole model for a shard
+	foldOnce sync.Once
+	folded   map[string]bool // case folded words, see foldedWords
+}
+
+// getCorpus returns the corpus statistics of the model,
+// computing them if the counts changed since they were last
+// computed.
+func (c *Classifier) getCorpus() *corpus {
+	if cached, ok := c.corpus.Load().(*corpus); ok && cached != nil {
+		return cached
+	}
+	computed := &corpus{freqs: make(map[string]float64)}
+	scale := c.scale()
+	for _, class := range c.Classes {
+		data := c.datas[class]
+		for word, cnt := range data.Freqs {
+			computed.freqs[word] += cnt * scale
+		}
+		computed.total += data.Total * scale
+	}
+	computed.vocab = len(computed.freqs)
+	if c.shardVocab > 0 {
+		computed.vocab = c.shardVocab
+	}
+	c.corpus.Store(computed)
+	return computed
+}
+
+// invalidate discards the statistics cached from the counts;
+// it must be called whenever the counts change.
+func (c *Classifier) invalidate() {
+	c.corpus.Store((*corpus)(nil))
+}
+
+// WordFrequencies returns a matrix of word frequencies that currently
+// exist in the classifier for each class state for the given input
+// words. In other words, if you obtain the frequencies
+//
+//    freqs := c.WordFrequencies(/* [j]string */)
+//
+// then the expression freq[i][j] represents the frequency of the j-th
+// word within the i-th class.
+func (c *Classifier) WordFrequencies(words []string) (freqMatrix [][]float64) {
+	n, l := len(c.Classes), len(words)
+	freqMatrix = make([][]float64, n)
+	for i := range freqMatrix {
+		arr := make([]float64, l)
+		data := c.datas[c.Classes[i]]
+		for j := range arr {
+			arr[j] = data.getWordProb(words[j])
+		}
+		freqMatrix[i] = arr
+	}
+	return
+}
+
+// WordsByClass returns a map of words and their probability of
+// appearing in the given class.
+func (c *Classifier) WordsByClass(class Class) (freqMap map[string]float64) {
+	freqMap = make(map[string]float64)
+	for word, cnt := range c.datas[class].Freqs {
+		freqMap[word] = cnt / c.datas[class].Total
+	}
+
+	return freqMap
+}
+
+// Vocabulary returns the words the classifier has counted in
+// any class, in alphabetical order.
+func (c *Classifier) Vocabulary() []string {
+	freqs := c.getCorpus().freqs
+	words := make([]string, 0, len(freqs))
+	for word := range freqs {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// VocabularySize returns the number of words the classifier has
+// counted in any class.
+func (c *Classifier) VocabularySize() int {
+	return len(c.getCorpus().freqs)
+}
+
+// ClassVocabulary returns the words counted in the class, in
+// alphabetical order, or nil if the classifier does not have the
+// class.
+func (c *Classifier) ClassVocabulary(class Class) []string {
+	data, ok := c.datas[class]
+	if !ok {
+		return nil
+	}
+	words := make([]string, 0, len(data.Freqs))
+	for word := range data.Freqs {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// VocabularyCounts returns the count of each word over all
+// classes, so that the vocabularies of model versions can be
+// audited and compared. The map is a copy.
+func (c *Classifier) VocabularyCounts() map[string]float64 {
+	return copyFreqs(c.getCorpus().freqs)
+}
+
+// ClassVocabularyCounts returns the count of each word in the
+// class, or nil if the classifier does not have the class. The
+// map is a copy.
+func (c *Classifier) ClassVocabularyCounts(class Class) map[string]float64 {
+	data, ok := c.datas[class]
+	if !ok {
+		return nil
+	}
+	counts := copyFreqs(data.Freqs)
+	if scale := c.scale(); scale != 1 {
+		for word := range counts {
+			counts[word] *= scale
+		}
+	}
+	return counts
+}
+
+// SharedVocabulary returns the words counted in every one of the
+// given classes, or of all classes if none are given, in
+// alphabetical order. Classes the classifier does not have
+// share no words.
+func (c *Classifier) SharedVocabulary(classes ...Class) []string {
+	if len(classes) == 0 {
+		classes = c.Classes
+	}
+	datas := make([]*classData, len(classes))
+	for i, class := range classes {
+		if datas[i] = c.datas[class]; datas[i] == nil {
+			return nil
+		}
+	}
+	// iterate over the smallest vocabulary
+	sort.Slice(datas, func(i, j int) bool {
+		return len(datas[i].Freqs) < len(datas[j].Freqs)
+	})
+	words := make([]string, 0)
+	for word := range datas[0].Freqs {
+		shared := true
+		for _, data := range datas[1:] {
+			if _, ok := data.Freqs[word]; !ok {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// Compact rebuilds the internal maps of the classifier at their
+// current size. Go maps never shrink, so after many words were
+// removed, e.g. by Unlearn, Subtract or PruneStale, compacting
+// releases the memory they still hold. It returns an estimate
+// of the bytes reclaimed: the decrease of the live heap, which
+// is measured by running the garbage collector before and after
+// rebuilding the maps, so that it also counts the memory freed
+// or allocated by other goroutines meanwhile. Running the
+// collector twice makes Compact expensive; call it after large
+// deletions rather than after every one.
+func (c *Classifier) Compact() (reclaimed int64) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for _, class := range c.Classes {
+		data := c.datas[class]
+		data.Freqs = copyFreqs(data.Freqs)
+		data.FreqTfs = copySamples(data.FreqTfs)
+		if data.Tfs != nil {
+			data.Tfs = copySamples(data.Tfs)
+		}
+		if data.Counts != nil {
+			data.Counts = copyFreqs(data.Counts)
+		}
+		if data.Dropped != nil {
+			data.Dropped = copyFreqs(data.Dropped)
+		}
+		if data.Updated != nil {
+			updated := make(map[string]int64, len(data.Updated))
+			for word, t := range data.Updated {
+				updated[word] = t
+			}
+			data.Updated = updated
+		}
+	}
+	if c.docFreqs != nil {
+		docFreqs := make(map[string]int, len(c.docFreqs))
+		for word, cnt := range c.docFreqs {
+			docFreqs[word] = cnt
+		}
+		c.docFreqs = docFreqs
+	}
+	c.invalidate()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	return int64(before.HeapAlloc) - int64(after.HeapAlloc)
+}
+
+// copyFreqs returns a copy of the word counts.
+func copyFreqs(freqs map[string]float64) map[string]float64 {
+	result := make(map[string]float64, len(freqs))
+	for word, cnt := range freqs {
+		result[word] = cnt
+	}
+	return result
+}
+
+// copySamples returns a copy of the TF samples.
+func copySamples(samples map[string][]float64) map[string][]float64 {
+	result := make(map[string][]float64, len(samples))
+	for word, tfs := range samples {
+		result[word] = append([]float64(nil), tfs...)
+	}
+	return result
+}
+
+// Clone returns a deep copy of the classifier, which can be
+// trained independently of c, for instance in the background
+// before being swapped in for c. The copy shares the usage
+// statistics of c, see c.Stats(), and its Expander and
+// MismatchMonitor.
+func (c *Classifier) Clone() *Classifier {
+	w := c.serializable()
+	w.Classes = append([]Class(nil), c.Classes...)
+	w.Datas = make(map[Class]*classData, len(c.datas))
+	for class, data := range c.datas {
+		w.Datas[class] = data.clone()
+	}
+	if c.docFreqs != nil {
+		w.DocFreqs = make(map[string]int, len(c.docFreqs))
+		for word, cnt := range c.docFreqs {
+			w.DocFreqs[word] = cnt
+		}
+	}
+	w.Blocklists = copyLists(c.blocklists)
+	w.Allowlists = copyLists(c.allowlists)
+	if c.namespaceCaps != nil {
+		w.NamespaceCaps = make(map[string]float64, len(c.namespaceCaps))
+		for ns, bound := range c.namespaceCaps {
+			w.NamespaceCaps[ns] = bound
+		}
+	}
+	if c.priorCounts != nil {
+		w.PriorCounts = make(map[Class]int, len(c.priorCounts))
+		for class, cnt := range c.priorCounts {
+			w.PriorCounts[class] = cnt
+		}
+	}
+	if c.priors != nil {
+		w.Priors = make(map[Class]float64, len(c.priors))
+		for class, prior := range c.priors {
+			w.Priors[class] = prior
+		}
+	}
+	if c.samplingWeights != nil {
+		w.SamplingWeights = make(map[Class]float64, len(c.samplingWeights))
+		for class, rate := range c.samplingWeights {
+			w.SamplingWeights[class] = rate
+		}
+	}
+	if c.stopwords != nil {
+		w.Stopwords = make(map[string]bool, len(c.stopwords))
+		for word := range c.stopwords {
+			w.Stopwords[word] = true
+		}
+	}
+	if c.archived != nil {
+		w.Archived = make(map[Class]bool, len(c.archived))
+		for class := range c.archived {
+			w.Archived[class] = true
+		}
+	}
+	if c.featureWeights != nil {
+		w.FeatureWeights = copyFreqs(c.featureWeights)
+	}
+	if c.classWeights != nil {
+		w.ClassWeights = make(map[Class]float64, len(c.classWeights))
+		for class, weight := range c.classWeights {
+			w.ClassWeights[class] = weight
+		}
+	}
+	if c.biases != nil {
+		w.Biases = make(map[Class]float64, len(c.biases))
+		for class, bias := range c.biases {
+			w.Biases[class] = bias
+		}
+	}
+	clone := w.classifier()
+	clone.stats = c.stats
+	clone.expander = c.expander
+	clone.estimator = c.estimator
+	clone.now = c.now
+	clone.windowed = c.windowed
+	clone.windowSize = c.windowSize
+	clone.windowTTL = c.windowTTL
+	clone.window = append([]windowEntry(nil), c.window...)
+	clone.mismatchMonitor = c.mismatchMonitor
+	clone.monitored = atomic.LoadInt64(&c.monitored)
+	c.mistakesMu.Lock()
+	clone.maxMistakes = c.maxMistakes
+	clone.mistakes = c.orderedMistakes()
+	c.mistakesMu.Unlock()
+	return clone
+}
+
+// clone returns a deep copy of the class data.
+func (d *classData) clone() *classData {
+	clone := &classData{
+		Freqs:   copyFreqs(d.Freqs),
+		FreqTfs: copySamples(d.FreqTfs),
+		Total:   d.Total,
+		Docs:    d.Docs,
+		Length:  d.Length,
+		Idf:     d.Idf,
+	}
+	if d.Tfs != nil {
+		clone.Tfs = copySamples(d.Tfs)
+	}
+	if d.Counts != nil {
+		clone.Counts = copyFreqs(d.Counts)
+	}
+	if d.Dropped != nil {
+		clone.Dropped = copyFreqs(d.Dropped)
+	}
+	if d.Updated != nil {
+		clone.Updated = make(map[string]int64, len(d.Updated))
+		for word, t := range d.Updated {
+			clone.Updated[word] = t
+		}
+	}
+	if d.Numeric != nil {
+		clone.Numeric = make(map[string]*gaussian, len(d.Numeric))
+		for name, g := range d.Numeric {
+			copied := *g
+			clone.Numeric[name] = &copied
+		}
+	}
+	if d.Categories != nil {
+		clone.Categories = make(map[string]map[string]float64, len(d.Categories))
+		for name, counts := range d.Categories {
+			clone.Categories[name] = copyFreqs(counts)
+		}
+	}
+	return clone
+}
+
+// copyLists returns a copy of the word lists of the classes.
+func copyLists(lists map[Class]map[string]bool) map[Class]map[string]bool {
+	if lists == nil {
+		return nil
+	}
+	result := make(map[Class]map[string]bool, len(lists))
+	for class, list := range lists {
+		result[class] = make(map[string]bool, len(list))
+		for word, ok := range list {
+			result[class][word] = ok
+		}
+	}
+	return result
+}
@@ -0,0 +1,319 @@
+package bayesian
+
+import "testing"
+import "fmt"
+import "time"
+import "math"
+import "bytes"
+
+func TestMaxTermCountPerDoc(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithMaxTermCountPerDoc(2))
+	c.Learn([]string{"buy", "buy", "buy", "buy", "now"}, Bad)
+	data := c.datas[Bad]
+	Assert(t, data.Freqs["buy"] == 2, "buy not capped")
+	Assert(t, data.Freqs["now"] == 1)
+	Assert(t, data.Total == 3)
+}
+
+func TestUnlearn(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"tall", "poor"}, Good)
+	c.Learn([]string{"bald", "ugly"}, Bad)
+
+	err := c.Unlearn([]string{"tall", "poor"}, Good)
+	Assert(t, err == nil, err)
+	data := c.datas[Good]
+	Assert(t, data.Total == 3 && data.Docs == 1 && c.Learned() == 2)
+	Assert(t, data.Freqs["tall"] == 1)
+	_, ok := data.Freqs["poor"]
+	Assert(t, !ok, "poor should be removed")
+
+	err = c.Unlearn([]string{"tall", "tall"}, Good)
+	Assert(t, err == ErrNotLearned, err)
+	Assert(t, data.Freqs["tall"] == 1 && data.Total == 3)
+
+	// relabel a document
+	c.Learn([]string{"tall", "poor"}, Bad)
+	c.Unlearn([]string{"tall", "poor"}, Bad)
+	Assert(t, c.datas[Bad].Total == 2 && c.Learned() == 2)
+}
+
+func TestUnlearnIgnored(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	c.SetOptions(WithStopwords([]string{"the"}), WithMinWordLength(2))
+	c.Learn([]string{"the", "tall", "a", "man"}, Good)
+	c.Learn([]string{"the", "tall", "rich", "man"}, Good)
+	err := c.Unlearn([]string{"the", "tall", "a", "man"}, Good)
+	Assert(t, err == nil, err)
+	data := c.datas[Good]
+	Assert(t, data.Docs == 1 && data.Length == 3)
+	tfs := data.FreqTfs["tall"]
+	Assert(t, len(tfs) == 1 && tfs[0] == float64(1)/3, tfs)
+}
+
+func TestUnlearnTfIdf(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"tall", "blonde"}, Good)
+	err := c.Unlearn([]string{"tall", "blonde"}, Good)
+	Assert(t, err == nil, err)
+	tfs := c.datas[Good].FreqTfs["tall"]
+	Assert(t, len(tfs) == 1 && tfs[0] == float64(1)/3, tfs)
+	_, ok := c.datas[Good].FreqTfs["blonde"]
+	Assert(t, !ok)
+
+	c.ConvertTermsFreqToTfIdf()
+	err = c.Unlearn([]string{"tall"}, Good)
+	Assert(t, err == ErrConverted, err)
+}
+
+func TestPruneStale(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClassifier(Good, Bad)
+	c.now = func() time.Time { return now }
+	c.Learn([]string{"untracked"}, Good)
+	c.SetOptions(WithWordTimestamps())
+	c.Learn([]string{"tall", "rich"}, Good)
+	c.Learn([]string{"poor"}, Bad)
+	now = now.Add(48 * time.Hour)
+	c.Learn([]string{"tall"}, Good)
+	c.Observe("ugly", 2, Bad)
+
+	removed := c.PruneStale(24 * time.Hour)
+	Assert(t, removed == 2, removed)
+	good, bad := c.datas[Good], c.datas[Bad]
+	Assert(t, good.Freqs["tall"] == 2 && good.Freqs["untracked"] == 1 && good.Total == 3, good.Freqs)
+	Assert(t, bad.Freqs["ugly"] == 2 && bad.Total == 2, bad.Freqs)
+	_, ok := good.Freqs["rich"]
+	Assert(t, !ok, "rich should be pruned")
+}
+
+func TestLearnBatch(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.LearnBatch([][]string{{"tall", "handsome"}, {"bald", "poor", "ugly"}, {"rich"}}, []Class{Good, Bad, Good})
+	Assert(t, c.Learned() == 3)
+	Assert(t, c.datas[Good].Total == 3 && c.datas[Good].Docs == 2)
+	Assert(t, c.datas[Bad].Total == 3 && c.datas[Bad].Docs == 1)
+}
+
+func TestLearnWeighted(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.LearnWeighted([]string{"tall", "rich", "tall"}, Good, 0.5)
+	c.LearnWeighted([]string{"poor"}, Bad, 3)
+	Assert(t, c.datas[Good].Freqs["tall"] == 1 && c.datas[Good].Total == 1.5)
+	Assert(t, c.datas[Bad].Freqs["poor"] == 3 && c.datas[Bad].Total == 3)
+	Assert(t, c.Learned() == 2 && c.datas[Good].Docs == 1)
+
+	c.LearnWeighted([]string{"x", "y"}, Good, 0.1)
+	err := c.Unlearn([]string{"x", "y"}, Good)
+	Assert(t, err == ErrNotLearned, err)
+	err = c.UnlearnWeighted([]string{"x", "y"}, Good, 0.1)
+	Assert(t, err == nil, err)
+	err = c.UnlearnWeighted([]string{"tall", "rich", "tall"}, Good, 0.5)
+	Assert(t, err == nil, err)
+	Assert(t, len(c.datas[Good].Freqs) == 0 && math.Abs(c.datas[Good].Total) < 1e-12, c.datas[Good].Freqs)
+	Assert(t, c.Learned() == 1 && c.datas[Good].Docs == 0)
+}
+
+func TestCompact(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	doc := make([]string, 100000)
+	for i := range doc {
+		doc[i] = fmt.Sprint("word", i)
+	}
+	c.Learn(doc, Good)
+	c.Learn([]string{"tall"}, Good)
+	c.Unlearn(doc, Good)
+	reclaimed := c.Compact()
+	Assert(t, reclaimed > 1<<20, reclaimed)
+	Assert(t, len(c.datas[Good].Freqs) == 1 && c.datas[Good].Freqs["tall"] == 1)
+}
+
+func TestObserveDocument(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.ObserveDocument(map[string]int{"tall": 3, "rich": 1}, 2, Good)
+	c.ObserveDocument(map[string]int{"poor": 2}, 1, Bad)
+
+	d := NewClassifier(Good, Bad)
+	d.Learn([]string{"tall", "rich", "tall"}, Good)
+	d.Learn([]string{"tall"}, Good)
+	d.Learn([]string{"poor", "poor"}, Bad)
+
+	Assert(t, c.Learned() == d.Learned())
+	for _, class := range c.Classes {
+		Assert(t, c.datas[class].Docs == d.datas[class].Docs, class)
+		Assert(t, c.datas[class].Total == d.datas[class].Total, class)
+	}
+	Assert(t, c.datas[Good].Freqs["tall"] == 3)
+
+	// the counts of one document are learned like the document
+	c, _ = NewClassifierOpts([]Class{Good, Bad}, WithIdfWeighting(), WithBM25(1.2, 0.75), WithMaxTermCountPerDoc(2))
+	d, _ = NewClassifierOpts([]Class{Good, Bad}, WithIdfWeighting(), WithBM25(1.2, 0.75), WithMaxTermCountPerDoc(2))
+	c.ObserveDocument(map[string]int{"tall": 3, "rich": 1}, 1, Good)
+	c.ObserveDocument(map[string]int{"poor": 1}, 1, Bad)
+	d.Learn([]string{"tall", "rich", "tall", "tall"}, Good)
+	d.Learn([]string{"poor"}, Bad)
+	Assert(t, c.idfDocs == 2 && c.docFreqs["tall"] == 1 && c.bm25Docs == 2 && c.bm25Words == d.bm25Words, c.docFreqs)
+	for _, class := range c.Classes {
+		for word, freq := range d.datas[class].Freqs {
+			Assert(t, c.datas[class].Freqs[word] == freq, class, word)
+		}
+		Assert(t, c.datas[class].Total == d.datas[class].Total, class)
+	}
+
+	// the document frequencies of several binarized documents
+	c, _ = NewClassifierOpts([]Class{Good, Bad}, WithIdfWeighting(), WithBinarized())
+	d, _ = NewClassifierOpts([]Class{Good, Bad}, WithIdfWeighting(), WithBinarized())
+	c.ObserveDocument(map[string]int{"tall": 5, "rich": 1}, 2, Good)
+	d.Learn([]string{"tall", "rich", "tall"}, Good)
+	d.Learn([]string{"tall"}, Good)
+	Assert(t, c.idfDocs == d.idfDocs && c.docFreqs["tall"] == 2 && c.docFreqs["rich"] == 1, c.docFreqs)
+	Assert(t, c.datas[Good].Freqs["tall"] == d.datas[Good].Freqs["tall"] && c.datas[Good].Total == d.datas[Good].Total)
+	cs, _, _ := c.LogScores([]string{"tall", "rich"})
+	ds, _, _ := d.LogScores([]string{"tall", "rich"})
+	Assert(t, cs[0] == ds[0] && cs[1] == ds[1], cs, ds)
+}
+
+func TestDecay(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "tall", "rich", "nice"}, Good)
+	c.Decay(0.5)
+	counts := c.ClassVocabularyCounts(Good)
+	Assert(t, counts["tall"] == 1 && c.classTotal(Good) == 2 && c.datas[Good].Docs == 1)
+	Assert(t, c.wordProb(Good, "tall") == 0.5)
+
+	c.SetOptions(WithAutoDecay(0.5))
+	c.Learn([]string{"rich"}, Good)
+	counts = c.ClassVocabularyCounts(Good)
+	Assert(t, counts["tall"] == 0.5 && counts["rich"] == 1.25 && c.classTotal(Good) == 2, counts)
+
+	// the decay is applied to the counts before they change
+	c.Observe("nice", 1, Good)
+	data := c.datas[Good]
+	Assert(t, c.decayScale == 0 && data.Freqs["tall"] == 0.5 && data.Freqs["nice"] == 1.25 && data.Total == 3)
+
+	// the stored counts do not grow without bound
+	for i := 0; i < 400; i++ {
+		c.Learn([]string{"rich"}, Good)
+	}
+	Assert(t, c.scale() >= minDecayScale && math.Abs(c.classTotal(Good)-2) < 1e-9, c.classTotal(Good))
+
+	var buf bytes.Buffer
+	Assert(t, c.WriteTo(&buf) == nil)
+	loaded, err := NewClassifierFromReader(&buf)
+	Assert(t, err == nil && loaded.classTotal(Good) == c.classTotal(Good), err)
+
+	defer func() {
+		Assert(t, recover() != nil, "no panic")
+	}()
+	WithAutoDecay(1.5)
+}
+
+func TestClone(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.SetClassBlocklist(Good, "ugly")
+	clone := c.Clone()
+	clone.Learn([]string{"tall", "blonde"}, Good)
+	clone.SetClassBlocklist(Bad, "tall")
+	Assert(t, c.Learned() == 2 && clone.Learned() == 3)
+	Assert(t, c.datas[Good].Freqs["tall"] == 1 && clone.datas[Good].Freqs["tall"] == 2)
+	Assert(t, c.datas[Good].Total == 3 && clone.datas[Good].Total == 5)
+	Assert(t, c.blocklists[Bad] == nil && clone.blocklists[Good]["ugly"])
+
+	clone.Classes[0] = "Other"
+	Assert(t, c.Classes[0] == Good)
+}
+
+func TestVocabulary(t *testing.T) {
+	c := NewClassifier(Good, Bad, "Neutral")
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "tall"}, Bad)
+	c.Learn([]string{"tall", "poor", "average"}, "Neutral")
+	vocab := c.Vocabulary()
+	Assert(t, c.VocabularySize() == 6 && len(vocab) == 6 && vocab[0] == "average", vocab)
+
+	shared := c.SharedVocabulary()
+	Assert(t, len(shared) == 1 && shared[0] == "tall", shared)
+	shared = c.SharedVocabulary(Bad, "Neutral")
+	Assert(t, len(shared) == 2 && shared[0] == "poor" && shared[1] == "tall", shared)
+	Assert(t, c.SharedVocabulary(Good, "Ugly") == nil)
+
+	words := c.ClassVocabulary(Bad)
+	Assert(t, len(words) == 3 && words[0] == "bald" && words[2] == "tall", words)
+	Assert(t, c.ClassVocabulary("Ugly") == nil)
+	counts := c.VocabularyCounts()
+	Assert(t, len(counts) == 6 && counts["tall"] == 3 && counts["poor"] == 2, counts)
+	counts["tall"] = 0
+	Assert(t, c.VocabularyCounts()["tall"] == 3, "not a copy")
+	classCounts := c.ClassVocabularyCounts(Good)
+	Assert(t, len(classCounts) == 3 && classCounts["rich"] == 1, classCounts)
+	Assert(t, c.ClassVocabularyCounts("Ugly") == nil)
+}
+
+func TestBM25(t *testing.T) {
+	c, _ := NewClassifierOpts([]Class{Good, Bad}, WithBM25(1.2, 0.75))
+	c.Learn([]string{"rich", "rich", "rich", "tall"}, Good)
+	// avg = 4, len = 4
+	Assert(t, math.Abs(c.datas[Good].Freqs["rich"]-3*2.2/(3+1.2)) < 1e-12, c.datas[Good].Freqs["rich"])
+	Assert(t, math.Abs(c.datas[Good].Total-(3*2.2/4.2+2.2/2.2)) < 1e-12, c.datas[Good].Total)
+
+	// avg = 3, len = 2
+	c.Learn([]string{"poor", "bald"}, Bad)
+	norm := 1 - 0.75 + 0.75*2/3
+	Assert(t, math.Abs(c.datas[Bad].Freqs["poor"]-2.2/(1+1.2*norm)) < 1e-12, c.datas[Bad].Freqs["poor"])
+
+	err := c.Unlearn([]string{"poor", "bald"}, Bad)
+	Assert(t, err == nil, err)
+	Assert(t, len(c.datas[Bad].Freqs) == 0 && c.bm25Docs == 1 && c.bm25Words == 4, c.datas[Bad].Freqs)
+	c.Reset()
+	Assert(t, c.bm25Docs == 0 && c.bm25Words == 0)
+
+	for _, params := range [][2]float64{{-1, 0.75}, {1.2, -0.1}, {1.2, 1.5}, {math.NaN(), 0.75}, {1.2, math.NaN()}} {
+		_, err = NewClassifierOpts([]Class{Good, Bad}, WithBM25(params[0], params[1]))
+		Assert(t, err != nil, "invalid BM25 parameters accepted", params)
+	}
+}
+
+func TestBM25Window(t *testing.T) {
+	c, _ := NewClassifierOpts([]Class{Good, Bad}, WithBM25(1.2, 0.75), WithSlidingWindow(1, 0))
+	c.Learn([]string{"a", "a", "a"}, Good)
+	c.Learn([]string{"a"}, Good)
+	// avg = 2, len = 1 when learned
+	norm := 1 - 0.75 + 0.75*1/2.0
+	want := 2.2 / (1 + 1.2*norm)
+	data := c.datas[Good]
+	Assert(t, math.Abs(data.Freqs["a"]-want) < 1e-12 && math.Abs(data.Total-want) < 1e-12, data.Freqs)
+	Assert(t, c.bm25Docs == 1 && c.bm25Words == 1)
+
+	// the remembered weights are subtracted, though the average changed
+	c.SetOptions(WithSlidingWindow(2, 0))
+	c.Learn([]string{"b", "b", "b", "b", "b"}, Good)
+	err := c.Unlearn([]string{"a"}, Good)
+	Assert(t, err == nil, err)
+	_, ok := data.Freqs["a"]
+	Assert(t, !ok && c.bm25Docs == 1 && c.bm25Words == 5, data.Freqs)
+}
+
+func TestPrune(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "tall"}, Bad)
+	removed := c.Prune(2)
+	// handsome, bald and poor are seen once
+	Assert(t, removed == 3, removed)
+	Assert(t, c.datas[Good].Total == 3 && c.datas[Bad].Total == 1, c.WordCount())
+	Assert(t, c.VocabularySize() == 2, c.Vocabulary())
+	Assert(t, c.Prune(2) == 0)
+
+	d := NewClassifierTfIdf(Good, Bad)
+	d.Learn([]string{"tall", "rich"}, Good)
+	d.ConvertTermsFreqToTfIdf()
+	defer func() {
+		Assert(t, recover() != nil, "pruning a converted classifier should panic")
+	}()
+	d.Prune(2)
+}
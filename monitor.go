@@ -0,0 +1,81 @@
+package bayesian
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// MismatchReport describes a classified document in which an
+// unusually high fraction of tokens is missing from the
+// vocabulary, or matches the vocabulary only after case
+// folding. This usually means that the documents are tokenized
+// differently than the training documents were.
+type MismatchReport struct {
+	Document []string
+	Tokens   int         // number of tokens in the document
+	OOV      int         // tokens missing from the vocabulary
+	Folded   int         // OOV tokens in the vocabulary after case folding
+	Meta     interface{} // metadata given to ClassifyWithMeta, if any
+}
+
+// MismatchMonitor configures the detection of tokenization
+// mismatches between training and classification; see
+// WithMismatchMonitor.
+type MismatchMonitor struct {
+	Every     int     // check every Every-th classified document
+	MaxOOV    float64 // report if the OOV fraction of tokens exceeds this
+	MaxFolded float64 // report if the folded fraction of tokens exceeds this
+	Report    func(MismatchReport)
+}
+
+// WithMismatchMonitor samples every m.Every-th classified
+// document and calls m.Report if the fraction of its tokens
+// missing from the vocabulary exceeds m.MaxOOV, or the fraction
+// found in the vocabulary only after case folding exceeds
+// m.MaxFolded. Report may be called concurrently if documents
+// are classified concurrently.
+func WithMismatchMonitor(m MismatchMonitor) Option {
+	return func(c *Classifier) {
+		c.mismatchMonitor = &m
+	}
+}
+
+// monitor checks the document, with the given metadata, for a
+// tokenization mismatch if it is sampled by the configured
+// monitor.
+func (c *Classifier) monitor(document []string, meta interface{}) {
+	m := c.mismatchMonitor
+	if m == nil || len(document) == 0 {
+		return
+	}
+	if m.Every > 1 && atomic.AddInt64(&c.monitored, 1)%int64(m.Every) != 0 {
+		return
+	}
+	corpus := c.getCorpus()
+	report := MismatchReport{Document: document, Tokens: len(document), Meta: meta}
+	for _, word := range document {
+		if _, ok := corpus.freqs[word]; ok {
+			continue
+		}
+		report.OOV++
+		if corpus.foldedWords()[strings.ToLower(word)] {
+			report.Folded++
+		}
+	}
+	tokens := float64(report.Tokens)
+	if float64(report.OOV)/tokens > m.MaxOOV || float64(report.Folded)/tokens > m.MaxFolded {
+		m.Report(report)
+	}
+}
+
+// foldedWords returns the set of case folded words of the
+// corpus, computing it on first use.
+func (co *corpus) foldedWords() map[string]bool {
+	co.foldOnce.Do(func() {
+		co.folded = make(map[string]bool, len(co.freqs))
+		for word := range co.freqs {
+			co.folded[strings.ToLower(word)] = true
+		}
+	})
+	return co.folded
+}
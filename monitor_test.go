@@ -0,0 +1,29 @@
+package bayesian
+
+import "testing"
+
+func TestMismatchMonitor(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	var reports []MismatchReport
+	c.SetOptions(WithMismatchMonitor(MismatchMonitor{
+		Every:     2,
+		MaxOOV:    0.5,
+		MaxFolded: 0.3,
+		Report:    func(r MismatchReport) { reports = append(reports, r) },
+	}))
+	c.LogScores([]string{"Tall", "Rich", "man"}) // not sampled
+	c.LogScores([]string{"Tall", "Rich", "man"})
+	c.LogScores([]string{"tall", "rich", "man"})
+	c.LogScores([]string{"tall", "rich", "boy"})
+	Assert(t, len(reports) == 1, reports)
+	Assert(t, reports[0].Tokens == 3 && reports[0].OOV == 3 && reports[0].Folded == 2, reports[0])
+
+	// documents given as counts are monitored and counted as well
+	seen := c.Seen()
+	c.LogScoresFreqs(map[string]int{"Tall": 2, "man": 1}) // not sampled
+	c.LogScoresFreqs(map[string]int{"Tall": 2, "man": 1})
+	Assert(t, len(reports) == 2 && c.Seen() == seen+2, reports)
+	Assert(t, reports[1].Tokens == 3 && reports[1].OOV == 3 && reports[1].Folded == 2, reports[1])
+}
@@ -0,0 +1,813 @@
+package bayesian
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrMsgpack is returned when decoding data that does not
+// follow the msgpack schema of classifiers.
+var ErrMsgpack = errors.New("invalid msgpack classifier data")
+
+// WriteMsgpack serializes the model of this classifier to the
+// Writer as a stream of msgpack (https://msgpack.org) values,
+// so that it can be decoded incrementally, one class at a time,
+// by consumers written in other languages. The stream consists
+// of a header map
+//
+//    {"version": 1, "classes": [str...], "learned": int,
+//     "tf_idf": bool, "did_convert_tf_idf": bool}
+//
+// which, for a classifier with a sliding window, also holds
+//
+//    "window_size": int, "window_ttl": int, "window": [map...]
+//
+// where the TTL is in nanoseconds and each remembered document
+// is a map, oldest first,
+//
+//    {"class": str, "length": float64, "weight": float64,
+//     "learned": int, "counts": {str: int...},
+//     "amounts": {str: float64...}, "numeric": {str: float64...},
+//     "categorical": {str: str...}}
+//
+// with the time it was learned in Unix nanoseconds, and the
+// last four keys omitted if the document has no such values,
+// followed by one map per class, in the order of "classes":
+//
+//    {"class": str, "total": float64, "docs": int,
+//     "length": float64, "freqs": {str: float64...},
+//     "freq_tfs": {str: [float64...]...}}
+//
+// The class map of a converted TF-IDF classifier also holds the
+// raw state kept by the conversion, in the keys
+//
+//    "idf": float64, "tfs": {str: [float64...]...},
+//    "counts": {str: float64...}, "dropped": {str: float64...}
+//
+// where "counts" and "dropped" are omitted if the conversion did
+// not keep them. The side features learned with
+// LearnWithFeatures are written, if any, in the keys
+//
+//    "numeric": {str: [n, mean, m2]...},
+//    "categories": {str: {str: float64...}...}
+//
+// where n, mean and m2 are the number of values of a numeric
+// feature, their mean and the sum of their squared differences
+// from the mean. Map keys are written in sorted order. Numbers
+// given as float64 are written as msgpack integers if they are
+// integral, like most counts, so decoders must accept both.
+// Decoders should ignore keys they do not know. Only the model
+// and the sliding window are written; other options and usage
+// statistics are not.
+func (c *Classifier) WriteMsgpack(w io.Writer) (err error) {
+	bw := bufio.NewWriter(w)
+	e := &msgpackEncoder{w: bw}
+	if c.windowed {
+		e.mapHeader(8)
+	} else {
+		e.mapHeader(5)
+	}
+	e.str("version")
+	e.int(1)
+	e.str("classes")
+	e.arrayHeader(len(c.Classes))
+	for _, class := range c.Classes {
+		e.str(string(class))
+	}
+	e.str("learned")
+	e.int(int64(c.learned))
+	e.str("tf_idf")
+	e.bool(c.tfIdf)
+	e.str("did_convert_tf_idf")
+	e.bool(c.DidConvertTfIdf)
+	if c.windowed {
+		e.str("window_size")
+		e.int(int64(c.windowSize))
+		e.str("window_ttl")
+		e.int(int64(c.windowTTL))
+		e.str("window")
+		e.arrayHeader(len(c.window))
+		for _, entry := range c.window {
+			e.windowEntry(entry, c.scale())
+		}
+	}
+
+	for _, class := range c.Classes {
+		data := c.datas[class]
+		fields := 6
+		if c.DidConvertTfIdf {
+			fields += 2
+			if data.Counts != nil {
+				fields++
+			}
+			if data.Dropped != nil {
+				fields++
+			}
+		}
+		if len(data.Numeric) > 0 {
+			fields++
+		}
+		if len(data.Categories) > 0 {
+			fields++
+		}
+		e.mapHeader(fields)
+		e.str("class")
+		e.str(string(class))
+		e.str("total")
+		e.float(data.Total * c.scale())
+		e.str("docs")
+		e.int(int64(data.Docs))
+		e.str("length")
+		e.float(data.Length)
+		e.str("freqs")
+		if c.float32Counts {
+			e.floats32(data.Freqs, c.scale())
+		} else {
+			e.floats(data.Freqs, c.scale())
+		}
+		e.str("freq_tfs")
+		e.samples(data.FreqTfs)
+		if len(data.Numeric) > 0 {
+			e.str("numeric")
+			names := make([]string, 0, len(data.Numeric))
+			for name := range data.Numeric {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			e.mapHeader(len(names))
+			for _, name := range names {
+				g := data.Numeric[name]
+				e.str(name)
+				e.arrayHeader(3)
+				e.float(g.N)
+				e.float(g.Mean)
+				e.float(g.M2)
+			}
+		}
+		if len(data.Categories) > 0 {
+			e.str("categories")
+			names := make([]string, 0, len(data.Categories))
+			for name := range data.Categories {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			e.mapHeader(len(names))
+			for _, name := range names {
+				e.str(name)
+				e.floats(data.Categories[name], 1)
+			}
+		}
+		if !c.DidConvertTfIdf {
+			continue
+		}
+		e.str("idf")
+		e.float(data.Idf)
+		e.str("tfs")
+		e.samples(data.Tfs)
+		if data.Counts != nil {
+			e.str("counts")
+			e.floats(data.Counts, c.scale())
+		}
+		if data.Dropped != nil {
+			e.str("dropped")
+			e.floats(data.Dropped, c.scale())
+		}
+	}
+	if e.err != nil {
+		return e.err
+	}
+	return bw.Flush()
+}
+
+// NewClassifierFromMsgpack loads a classifier written with
+// c.WriteMsgpack(io.Writer).
+func NewClassifierFromMsgpack(r io.Reader) (c *Classifier, err error) {
+	d := &msgpackDecoder{r: bufio.NewReader(r)}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	header, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, ErrMsgpack
+	}
+	names, ok := header["classes"].([]interface{})
+	if !ok {
+		return nil, ErrMsgpack
+	}
+	classes := make([]Class, len(names))
+	for i, name := range names {
+		s, ok := name.(string)
+		if !ok {
+			return nil, ErrMsgpack
+		}
+		classes[i] = Class(s)
+	}
+	if c, err = NewClassifierSafe(classes...); err != nil {
+		return nil, err
+	}
+	learned, _ := toFloat(header["learned"])
+	c.learned = int(learned)
+	c.tfIdf, _ = header["tf_idf"].(bool)
+	c.DidConvertTfIdf, _ = header["did_convert_tf_idf"].(bool)
+	if err = c.decodeWindow(header); err != nil {
+		return nil, err
+	}
+
+	for range classes {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, ErrMsgpack
+		}
+		name, _ := m["class"].(string)
+		data, ok := c.datas[Class(name)]
+		if !ok {
+			return nil, ErrMsgpack
+		}
+		data.Total, _ = toFloat(m["total"])
+		docs, _ := toFloat(m["docs"])
+		data.Docs = int(docs)
+		data.Length, _ = toFloat(m["length"])
+		data.Idf, _ = toFloat(m["idf"])
+		if data.Freqs, err = decodeFloats(m["freqs"]); err != nil {
+			return nil, err
+		}
+		if data.FreqTfs, err = decodeSamples(m["freq_tfs"]); err != nil {
+			return nil, err
+		}
+		if _, ok := m["tfs"]; ok {
+			if data.Tfs, err = decodeSamples(m["tfs"]); err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := m["counts"]; ok {
+			if data.Counts, err = decodeFloats(m["counts"]); err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := m["dropped"]; ok {
+			if data.Dropped, err = decodeFloats(m["dropped"]); err != nil {
+				return nil, err
+			}
+		}
+		if err = decodeFeatures(data, m); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// decodeWindow sets the sliding window and the remembered
+// documents from the decoded msgpack header.
+func (c *Classifier) decodeWindow(header map[string]interface{}) error {
+	size := toInt(header["window_size"])
+	ttl := toInt(header["window_ttl"])
+	WithSlidingWindow(int(size), time.Duration(ttl))(c)
+	entries, _ := header["window"].([]interface{})
+	for _, v := range entries {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return ErrMsgpack
+		}
+		name, _ := m["class"].(string)
+		if _, ok := c.datas[Class(name)]; !ok {
+			return ErrMsgpack
+		}
+		entry := windowEntry{class: Class(name)}
+		entry.length, _ = toFloat(m["length"])
+		entry.weight, _ = toFloat(m["weight"])
+		entry.learned = time.Unix(0, toInt(m["learned"]))
+		if _, ok := m["counts"]; ok {
+			counts, err := decodeFloats(m["counts"])
+			if err != nil {
+				return err
+			}
+			entry.counts = make(map[string]int, len(counts))
+			for word, cnt := range counts {
+				entry.counts[word] = int(cnt)
+			}
+		}
+		if _, ok := m["amounts"]; ok {
+			amounts, err := decodeFloats(m["amounts"])
+			if err != nil {
+				return err
+			}
+			entry.amounts = amounts
+		}
+		_, numeric := m["numeric"]
+		_, categorical := m["categorical"]
+		if numeric || categorical {
+			features := new(Features)
+			if numeric {
+				values, err := decodeFloats(m["numeric"])
+				if err != nil {
+					return err
+				}
+				features.Numeric = values
+			}
+			if categorical {
+				values, _ := m["categorical"].(map[string]interface{})
+				features.Categorical = make(map[string]string, len(values))
+				for name, value := range values {
+					s, ok := value.(string)
+					if !ok {
+						return ErrMsgpack
+					}
+					features.Categorical[name] = s
+				}
+			}
+			entry.features = features
+		}
+		c.window = append(c.window, entry)
+	}
+	return nil
+}
+
+// decodeFeatures sets the side features of the class data from
+// the decoded msgpack map of the class.
+func decodeFeatures(data *classData, m map[string]interface{}) error {
+	numeric, _ := m["numeric"].(map[string]interface{})
+	for name, v := range numeric {
+		values, ok := v.([]interface{})
+		if !ok || len(values) != 3 {
+			return ErrMsgpack
+		}
+		var stats [3]float64
+		for i, n := range values {
+			if stats[i], ok = toFloat(n); !ok {
+				return ErrMsgpack
+			}
+		}
+		if data.Numeric == nil {
+			data.Numeric = make(map[string]*gaussian)
+		}
+		data.Numeric[name] = &gaussian{N: stats[0], Mean: stats[1], M2: stats[2]}
+	}
+	categories, _ := m["categories"].(map[string]interface{})
+	for name, v := range categories {
+		counts, err := decodeFloats(v)
+		if err != nil {
+			return err
+		}
+		if data.Categories == nil {
+			data.Categories = make(map[string]map[string]float64)
+		}
+		data.Categories[name] = counts
+	}
+	return nil
+}
+
+// decodeFloats converts a decoded msgpack map of numbers, which
+// may be missing, to a map of float64 values.
+func decodeFloats(v interface{}) (map[string]float64, error) {
+	m, _ := v.(map[string]interface{})
+	floats := make(map[string]float64, len(m))
+	for key, n := range m {
+		f, ok := toFloat(n)
+		if !ok {
+			return nil, ErrMsgpack
+		}
+		floats[key] = f
+	}
+	return floats, nil
+}
+
+// decodeSamples converts a decoded msgpack map of arrays of
+// numbers, which may be missing, to a map of float64 slices.
+func decodeSamples(v interface{}) (map[string][]float64, error) {
+	m, _ := v.(map[string]interface{})
+	samples := make(map[string][]float64, len(m))
+	for key, a := range m {
+		values, ok := a.([]interface{})
+		if !ok {
+			return nil, ErrMsgpack
+		}
+		floats := make([]float64, 0, len(values))
+		for _, n := range values {
+			f, ok := toFloat(n)
+			if !ok {
+				return nil, ErrMsgpack
+			}
+			floats = append(floats, f)
+		}
+		samples[key] = floats
+	}
+	return samples, nil
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedSampleKeys works the same as sortedKeys, for a map of
+// samples.
+func sortedSampleKeys(m map[string][]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toFloat converts a decoded msgpack number to a float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// toInt returns the decoded msgpack integer, or 0 if the value
+// is not an integer; unlike toFloat it keeps all 64 bits.
+func toInt(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case uint64:
+		return int64(n)
+	}
+	return 0
+}
+
+// msgpackEncoder writes the subset of msgpack needed to encode
+// classifiers. The first error is kept in err.
+type msgpackEncoder struct {
+	w   io.Writer
+	err error
+}
+
+func (e *msgpackEncoder) write(b ...byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *msgpackEncoder) header(fix, fixMax int, code16, code32 byte, n int) {
+	switch {
+	case n <= fixMax:
+		e.write(byte(fix | n))
+	case n <= math.MaxUint16:
+		e.write(code16, byte(n>>8), byte(n))
+	default:
+		e.write(code32, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func (e *msgpackEncoder) mapHeader(n int) {
+	e.header(0x80, 15, 0xde, 0xdf, n)
+}
+
+func (e *msgpackEncoder) arrayHeader(n int) {
+	e.header(0x90, 15, 0xdc, 0xdd, n)
+}
+
+func (e *msgpackEncoder) str(s string) {
+	if n := len(s); n > 31 && n <= math.MaxUint8 {
+		e.write(0xd9, byte(n))
+	} else {
+		e.header(0xa0, 31, 0xda, 0xdb, n)
+	}
+	e.write([]byte(s)...)
+}
+
+// int writes the integer in the smallest signed msgpack format
+// that holds it.
+func (e *msgpackEncoder) int(n int64) {
+	var b [9]byte
+	switch {
+	case n >= -32 && n < 128:
+		e.write(byte(n))
+		return
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		e.write(0xd0, byte(n))
+		return
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		b[0] = 0xd1
+		binary.BigEndian.PutUint16(b[1:], uint16(n))
+		e.write(b[:3]...)
+		return
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		b[0] = 0xd2
+		binary.BigEndian.PutUint32(b[1:], uint32(n))
+		e.write(b[:5]...)
+		return
+	}
+	b[0] = 0xd3
+	binary.BigEndian.PutUint64(b[1:], uint64(n))
+	e.write(b[:]...)
+}
+
+// maxExactInt is the largest integer up to which all integers
+// are exactly representable as float64 values.
+const maxExactInt = 1 << 53
+
+// float writes the number as an integer if it is integral, since
+// most counts are and integers take 1 to 5 bytes instead of 9,
+// and as a float64 otherwise.
+func (e *msgpackEncoder) float(f float64) {
+	if f == math.Trunc(f) && math.Abs(f) <= maxExactInt && !(f == 0 && math.Signbit(f)) {
+		e.int(int64(f))
+		return
+	}
+	var b [9]byte
+	b[0] = 0xcb
+	binary.BigEndian.PutUint64(b[1:], math.Float64bits(f))
+	e.write(b[:]...)
+}
+
+// floats writes a map of numbers in the order of its keys, each
+// multiplied by the scale.
+func (e *msgpackEncoder) floats(m map[string]float64, scale float64) {
+	keys := sortedKeys(m)
+	e.mapHeader(len(keys))
+	for _, key := range keys {
+		e.str(key)
+		e.float(m[key] * scale)
+	}
+}
+
+// floats32 works the same as floats, but writes the numbers
+// that are not integers as float32.
+func (e *msgpackEncoder) floats32(m map[string]float64, scale float64) {
+	keys := sortedKeys(m)
+	e.mapHeader(len(keys))
+	for _, key := range keys {
+		e.str(key)
+		f := float32(m[key] * scale)
+		if float64(f) == math.Trunc(float64(f)) {
+			e.float(float64(f))
+			continue
+		}
+		var b [5]byte
+		b[0] = 0xca
+		binary.BigEndian.PutUint32(b[1:], math.Float32bits(f))
+		e.write(b[:]...)
+	}
+}
+
+// samples writes a map of arrays of numbers in the order of its
+// keys.
+func (e *msgpackEncoder) samples(m map[string][]float64) {
+	keys := sortedSampleKeys(m)
+	e.mapHeader(len(keys))
+	for _, key := range keys {
+		e.str(key)
+		e.arrayHeader(len(m[key]))
+		for _, f := range m[key] {
+			e.float(f)
+		}
+	}
+}
+
+// windowEntry writes a remembered document, with its weight
+// multiplied by the decay scale.
+func (e *msgpackEncoder) windowEntry(entry windowEntry, scale float64) {
+	fields := 4
+	if entry.counts != nil {
+		fields++
+	}
+	if entry.amounts != nil {
+		fields++
+	}
+	if entry.features != nil && entry.features.Numeric != nil {
+		fields++
+	}
+	if entry.features != nil && entry.features.Categorical != nil {
+		fields++
+	}
+	e.mapHeader(fields)
+	e.str("class")
+	e.str(string(entry.class))
+	e.str("length")
+	e.float(entry.length)
+	e.str("weight")
+	e.float(entry.weight * scale)
+	e.str("learned")
+	e.int(entry.learned.UnixNano())
+	if entry.counts != nil {
+		e.str("counts")
+		words := make([]string, 0, len(entry.counts))
+		for word := range entry.counts {
+			words = append(words, word)
+		}
+		sort.Strings(words)
+		e.mapHeader(len(words))
+		for _, word := range words {
+			e.str(word)
+			e.int(int64(entry.counts[word]))
+		}
+	}
+	if entry.amounts != nil {
+		e.str("amounts")
+		e.floats(entry.amounts, 1)
+	}
+	if entry.features != nil && entry.features.Numeric != nil {
+		e.str("numeric")
+		e.floats(entry.features.Numeric, 1)
+	}
+	if entry.features != nil && entry.features.Categorical != nil {
+		e.str("categorical")
+		names := make([]string, 0, len(entry.features.Categorical))
+		for name := range entry.features.Categorical {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		e.mapHeader(len(names))
+		for _, name := range names {
+			e.str(name)
+			e.str(entry.features.Categorical[name])
+		}
+	}
+}
+
+func (e *msgpackEncoder) bool(v bool) {
+	if v {
+		e.write(0xc3)
+	} else {
+		e.write(0xc2)
+	}
+}
+
+// msgpackDecoder reads msgpack values into nil, bool, int64,
+// uint64, float64, string, []byte, []interface{} and
+// map[string]interface{} values. The lengths in the data are not
+// trusted: memory is allocated as the values are read, not ahead
+// of them, and values nest at most maxMsgpackDepth deep.
+type msgpackDecoder struct {
+	r     *bufio.Reader
+	depth int
+}
+
+const (
+	// maxMsgpackDepth is the deepest nesting of msgpack values
+	// the decoder accepts; classifiers nest three deep.
+	maxMsgpackDepth = 32
+
+	// maxMsgpackPrealloc is the largest number of elements or
+	// bytes allocated for a msgpack value before they are read.
+	maxMsgpackPrealloc = 1 << 12
+)
+
+// prealloc returns the capacity to allocate for n elements or
+// bytes that are yet to be read.
+func prealloc(n uint64) int {
+	if n > maxMsgpackPrealloc {
+		return maxMsgpackPrealloc
+	}
+	return int(n)
+}
+
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(d.r, b[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+func (d *msgpackDecoder) bytes(n uint64) ([]byte, error) {
+	b := bytes.NewBuffer(make([]byte, 0, prealloc(n)))
+	if _, err := io.CopyN(b, d.r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (d *msgpackDecoder) array(n uint64) (interface{}, error) {
+	a := make([]interface{}, 0, prealloc(n))
+	for i := uint64(0); i < n; i++ {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+func (d *msgpackDecoder) dict(n uint64) (interface{}, error) {
+	m := make(map[string]interface{}, prealloc(n))
+	for i := uint64(0); i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, ErrMsgpack
+		}
+		if m[key], err = d.value(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// sized reads the length of a value with the given size in
+// bytes, and then the value with read.
+func (d *msgpackDecoder) sized(size int, read func(uint64) (interface{}, error)) (interface{}, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return nil, err
+	}
+	return read(n)
+}
+
+// nested reads an array or map of n elements with read, one
+// level deeper.
+func (d *msgpackDecoder) nested(n uint64, read func(uint64) (interface{}, error)) (interface{}, error) {
+	if d.depth >= maxMsgpackDepth {
+		return nil, fmt.Errorf("%w: nested too deep", ErrMsgpack)
+	}
+	d.depth++
+	defer func() { d.depth-- }()
+	return read(n)
+}
+
+func (d *msgpackDecoder) str(n uint64) (interface{}, error) {
+	b, err := d.bytes(n)
+	return string(b), err
+}
+
+func (d *msgpackDecoder) bin(n uint64) (interface{}, error) {
+	return d.bytes(n)
+}
+
+func (d *msgpackDecoder) value() (interface{}, error) {
+	code, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return d.nested(uint64(code&0x0f), d.dict)
+	case code&0xf0 == 0x90:
+		return d.nested(uint64(code&0x0f), d.array)
+	case code&0xe0 == 0xa0:
+		return d.str(uint64(code & 0x1f))
+	}
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		return d.sized(1<<(code-0xc4), d.bin)
+	case 0xca:
+		n, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := d.uint(8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(1 << (code - 0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (code - 0xd0)
+		n, err := d.uint(size)
+		shift := uint(64 - 8*size)
+		return int64(n<<shift) >> shift, err
+	case 0xd9, 0xda, 0xdb:
+		return d.sized(1<<(code-0xd9), d.str)
+	case 0xdc, 0xdd:
+		return d.sized(2<<(code-0xdc), func(n uint64) (interface{}, error) {
+			return d.nested(n, d.array)
+		})
+	case 0xde, 0xdf:
+		return d.sized(2<<(code-0xde), func(n uint64) (interface{}, error) {
+			return d.nested(n, d.dict)
+		})
+	}
+	return nil, fmt.Errorf("%w: unsupported type 0x%x", ErrMsgpack, code)
+}
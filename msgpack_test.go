@@ -0,0 +1,124 @@
+package bayesian
+
+import "testing"
+import "bytes"
+import "bufio"
+import "errors"
+import "math"
+
+func TestMsgpack(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	long := "a-word-that-is-longer-than-thirty-one-bytes"
+	c.Learn([]string{"tall", "handsome", "rich", long}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	for i := 0; i < 200; i++ {
+		c.Learn([]string{"poor"}, Bad)
+	}
+	var buf bytes.Buffer
+	err := c.WriteMsgpack(&buf)
+	Assert(t, err == nil, "could not write:", err)
+
+	d, err := NewClassifierFromMsgpack(&buf)
+	Assert(t, err == nil, "could not read:", err)
+	Assert(t, d.Learned() == 202 && d.IsTfIdf() && !d.DidConvertTfIdf)
+	Assert(t, d.Classes[0] == Good && d.Classes[1] == Bad)
+	Assert(t, d.datas[Good].Freqs[long] == 1 && d.datas[Good].Total == 4)
+	Assert(t, d.datas[Bad].Freqs["poor"] == 201 && d.datas[Bad].Docs == 201)
+	Assert(t, len(d.datas[Bad].FreqTfs["poor"]) == 201)
+
+	_, err = NewClassifierFromMsgpack(bytes.NewReader([]byte{0x01}))
+	Assert(t, err == ErrMsgpack, err)
+}
+
+func TestMsgpackDecoder(t *testing.T) {
+	var buf bytes.Buffer
+	e := &msgpackEncoder{w: &buf}
+	e.int(-5)
+	e.int(300)
+	e.float(0.25)
+	e.arrayHeader(20)
+	for i := 0; i < 20; i++ {
+		e.bool(i%2 == 0)
+	}
+	d := &msgpackDecoder{r: bufio.NewReader(&buf)}
+	for _, expected := range []interface{}{int64(-5), int64(300), 0.25} {
+		v, err := d.value()
+		Assert(t, err == nil && v == expected, v, err)
+	}
+	v, err := d.value()
+	a, ok := v.([]interface{})
+	Assert(t, err == nil && ok && len(a) == 20 && a[0] == true && a[1] == false, v, err)
+}
+
+func TestMsgpackUntrusted(t *testing.T) {
+	// lengths larger than the data fail without allocating them
+	for _, data := range [][]byte{
+		{0xdb, 0xff, 0xff, 0xff, 0xff, 'a'},
+		{0xc6, 0xff, 0xff, 0xff, 0xff},
+		{0xdd, 0xff, 0xff, 0xff, 0xff, 0x01},
+		{0xdf, 0xff, 0xff, 0xff, 0xff, 0xa1, 'a', 0x01},
+	} {
+		d := &msgpackDecoder{r: bufio.NewReader(bytes.NewReader(data))}
+		_, err := d.value()
+		Assert(t, err != nil, data)
+	}
+
+	deep := bytes.Repeat([]byte{0x91}, 1000)
+	d := &msgpackDecoder{r: bufio.NewReader(bytes.NewReader(append(deep, 0x01)))}
+	_, err := d.value()
+	Assert(t, errors.Is(err, ErrMsgpack), err)
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	c.SetOptions(WithLengthModel(), WithIncrementalTfIdf(), WithMinDocFreq(2))
+	c.Learn([]string{"tall", "handsome", "rich", "tall"}, Good)
+	c.Learn([]string{"tall", "nice"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.Learn([]string{"poor", "tall"}, Bad)
+	c.ConvertTermsFreqToTfIdf()
+	c.Learn([]string{"rich", "nice"}, Good)
+
+	var buf, again bytes.Buffer
+	Assert(t, c.WriteMsgpack(&buf) == nil)
+	Assert(t, c.WriteMsgpack(&again) == nil)
+	Assert(t, bytes.Equal(buf.Bytes(), again.Bytes()), "output should be deterministic")
+
+	d, err := NewClassifierFromMsgpack(&buf)
+	Assert(t, err == nil, "could not read:", err)
+	d.SetOptions(WithLengthModel(), WithIncrementalTfIdf(), WithMinDocFreq(2))
+	Assert(t, d.datas[Good].Length == c.datas[Good].Length && d.datas[Good].Idf == c.datas[Good].Idf)
+	Assert(t, len(d.datas[Good].Tfs["nice"]) == 2 && d.datas[Good].Counts["tall"] == 3, d.datas[Good].Tfs)
+	Assert(t, d.datas[Good].Dropped["rich"] == 2, d.datas[Good].Dropped)
+	doc := []string{"tall", "poor", "nice"}
+	want, _, _ := c.LogScores(doc)
+	got, _, _ := d.LogScores(doc)
+	Assert(t, want[0] == got[0] && want[1] == got[1], want, got)
+
+	c.RefreshTfIdf()
+	d.RefreshTfIdf()
+	want, _, _ = c.LogScores(doc)
+	got, _, _ = d.LogScores(doc)
+	Assert(t, want[0] == got[0] && want[1] == got[1], want, got)
+}
+
+func TestMsgpackIntegralFloats(t *testing.T) {
+	values := []float64{0, 1, -1, 127, -33, 200, -200, 70000, -70000, 1 << 40, 2.5, math.Copysign(0, -1)}
+	var buf bytes.Buffer
+	e := &msgpackEncoder{w: &buf}
+	e.float(1)
+	e.float(300)
+	e.float(2.5)
+	Assert(t, buf.Len() == 1+3+9, buf.Len())
+
+	buf.Reset()
+	for _, f := range values {
+		e.float(f)
+	}
+	d := &msgpackDecoder{r: bufio.NewReader(&buf)}
+	for _, expected := range values {
+		v, err := d.value()
+		f, ok := toFloat(v)
+		Assert(t, err == nil && ok && f == expected && math.Signbit(f) == math.Signbit(expected), v, err)
+	}
+}
@@ -0,0 +1,482 @@
+package bayesian
+
+// Option configures optional behavior of a Classifier.
+// Options are applied with c.SetOptions(...Option).
+type Option func(c *Classifier)
+
+// WithMinProbMargin sets the minimum difference between the
+// probabilities of the most likely class and the runner-up for
+// c.ClassifyWithThreshold to be confident of a classification.
+func WithMinProbMargin(margin float64) Option {
+	return func(c *Classifier) {
+		c.minProbMargin = margin
+	}
+}
+
+// WithTieBreak sets the policy to pick the most likely class
+// when several classes have the highest score, TieBreakFirst by
+// default. The strict value returned with the scores is false
+// either way. Use WithRandomTieBreak for TieBreakRandom.
+func WithTieBreak(policy TieBreak) Option {
+	return func(c *Classifier) {
+		if policy == TieBreakRandom {
+			WithRandomTieBreak(1)(c)
+			return
+		}
+		c.tieBreak = policy
+	}
+}
+
+// WithTieEpsilon makes the classifier report the scores within
+// eps of the highest one as tied with it, so that strict is
+// false and the tie is broken as configured with WithTieBreak,
+// since scores are hardly ever exactly equal in floating point.
+// The tolerance applies to the scores returned: log scores for
+// LogScores, probabilities for ProbScores. A value of 0 means
+// exact equality.
+func WithTieEpsilon(eps float64) Option {
+	return func(c *Classifier) {
+		c.tieEpsilon = eps
+	}
+}
+
+// WithRandomTieBreak picks the most likely class at random when
+// several classes have the highest score, from a source with the
+// given seed, so that the choices are reproducible.
+func WithRandomTieBreak(seed int64) Option {
+	return func(c *Classifier) {
+		c.tieBreak = TieBreakRandom
+		c.tieBreaker = newTieBreaker(seed)
+	}
+}
+
+// WithTemperature divides the log scores by t before they are
+// converted to probabilities by ProbScores, SafeProbScores,
+// Classify and Calibrate: above 1 it softens the typically
+// extreme probabilities of Naive Bayes, below 1 it sharpens
+// them. The most likely class does not change. A value of 0
+// means 1. See also the Temperature field of ScoreOptions.
+func WithTemperature(t float64) Option {
+	return func(c *Classifier) {
+		c.temperature = t
+	}
+}
+
+// WithSkipOOV ignores the words of scored documents that the
+// classifier has not seen in any class, instead of giving them
+// the probability of unseen words in every class, which can
+// swamp the signal of documents with many such words. See also
+// the SkipOOV field of ScoreOptions.
+func WithSkipOOV() Option {
+	return func(c *Classifier) {
+		c.skipOOV = true
+	}
+}
+
+// WithAutoCreateClasses makes the classifier add the classes
+// it does not have when learning or observing documents of
+// them, instead of reporting them as not found.
+func WithAutoCreateClasses() Option {
+	return func(c *Classifier) {
+		c.autoCreateClasses = true
+	}
+}
+
+// WithTfIdf makes the classifier a TF-IDF classifier, see
+// NewClassifierTfIdf. It should be passed to NewClassifierOpts.
+func WithTfIdf() Option {
+	return func(c *Classifier) {
+		c.tfIdf = true
+	}
+}
+
+// WithSmoothing estimates the probability of each word in a
+// class with additive smoothing, (n+alpha)/(N_j+alpha*V), where
+// n is the count of the word in the class, N_j is the total
+// count of the class and V is the size of the vocabulary, so
+// that words never seen in a class get the probability of
+// alpha pseudo-occurrences. Alpha 1 is Laplace smoothing, and
+// smaller values, like 0.01 for large vocabularies, Lidstone
+// smoothing. A value of 0 disables smoothing, which is the
+// default, to keep the behavior of existing models. It panics
+// if alpha is negative.
+func WithSmoothing(alpha float64) Option {
+	if alpha < 0 {
+		panic("smoothing alpha must not be negative")
+	}
+	return func(c *Classifier) {
+		c.smoothing = alpha
+	}
+}
+
+// WithJelinekMercer smooths the probability of each word in a
+// class by interpolation with its probability over all classes,
+// lambda*P(W|C_j) + (1-lambda)*P(W), so that words never seen in
+// a class but common overall get a sensible probability, unlike
+// with additive smoothing. Words never seen in any class still
+// get the probability of unseen words. It takes precedence over
+// WithSmoothing. A lambda of 0 disables it; it panics if lambda
+// is not in [0, 1].
+func WithJelinekMercer(lambda float64) Option {
+	if lambda < 0 || lambda > 1 {
+		panic("lambda must be in [0, 1]")
+	}
+	return func(c *Classifier) {
+		c.jmLambda = lambda
+	}
+}
+
+// WithDirichletSmoothing smooths the probability of each word
+// in a class with a Dirichlet prior of mu pseudo-counts
+// distributed like the words over all classes,
+// (n+mu*P(W))/(N_j+mu), where n is the count of the word in the
+// class and N_j the total count of the class, so that small
+// classes rely more on the corpus distribution than large ones.
+// Words never seen in any class still get the probability of
+// unseen words. It takes precedence over WithSmoothing, but not
+// over WithJelinekMercer. A mu of 0 disables it; it panics if
+// mu is negative.
+func WithDirichletSmoothing(mu float64) Option {
+	if mu < 0 {
+		panic("mu must not be negative")
+	}
+	return func(c *Classifier) {
+		c.dirichletMu = mu
+	}
+}
+
+// WithComplementNB makes the classifier score documents with
+// Complement Naive Bayes: each word contributes the negated log
+// of its probability in all the other classes together, rather
+// than the log of its probability in the class, which is much
+// more robust to imbalanced training data, since each class is
+// scored against the counts of the others. The probabilities
+// are smoothed with the alpha of WithSmoothing, or 1. The prior
+// probabilities are still added to the scores, so combine it
+// with WithUniformPriors to ignore them entirely. Class word
+// lists are not applied, and WithMaxWordContribution has no
+// effect on the positive contributions.
+func WithComplementNB() Option {
+	return func(c *Classifier) {
+		c.complementNB = true
+	}
+}
+
+// WithMinWordLength ignores the words shorter than n characters,
+// both when learning and when classifying documents.
+func WithMinWordLength(n int) Option {
+	return func(c *Classifier) {
+		c.minWordLength = n
+	}
+}
+
+// WithStopwords ignores the given words, both when learning and
+// when classifying documents.
+func WithStopwords(words []string) Option {
+	return func(c *Classifier) {
+		c.stopwords = make(map[string]bool, len(words))
+		for _, word := range words {
+			c.stopwords[word] = true
+		}
+	}
+}
+
+// WithMaxTermCountPerDoc limits the number of occurrences of
+// any single term that a learned document may contribute to
+// the counts, so that documents stuffed with a keyword do not
+// distort the model. A value of 0 means no limit. With
+// WithBinarized, the limit is 1.
+func WithMaxTermCountPerDoc(n int) Option {
+	return func(c *Classifier) {
+		c.maxTermCount = n
+	}
+}
+
+// WithNGrams replaces the words of each document, both when
+// learning and when scoring it, with its n-grams for each n from
+// min to max, see NGrams, so that word order is taken into
+// account: with WithNGrams(1, 2), "not good" is learned as
+// "not", "good" and "not good". It panics unless 1 <= min <= max.
+func WithNGrams(min, max int) Option {
+	if min < 1 || max < min {
+		panic("provide 1 <= min <= max")
+	}
+	return func(c *Classifier) {
+		c.ngramMin = min
+		c.ngramMax = max
+	}
+}
+
+// WithBM25 adds the BM25 weight of each word of a learned
+// document to the counts of its class, instead of the number of
+// its occurrences: cnt*(k1+1)/(cnt+k1*(1-b+b*len/avg)), where
+// cnt is the number of occurrences, len the length of the
+// document and avg the average length of the documents learned
+// so far. Repeated words saturate as set by k1, for instance
+// 1.2, and long documents are normalized as set by b between 0
+// and 1, for instance 0.75. NewClassifierOpts returns an error
+// if k1 is negative or b is not between 0 and 1.
+func WithBM25(k1, b float64) Option {
+	return func(c *Classifier) {
+		c.bm25 = true
+		c.bm25K1 = k1
+		c.bm25B = b
+	}
+}
+
+// WithLengthModel models the length of the documents of each
+// class with a Poisson distribution of the mean length of its
+// learned documents, and adds the log probability of the length
+// of a scored document, after the words ignored are dropped, to
+// its score in all the scoring methods, so that classes whose
+// documents are systematically shorter or longer can be told
+// apart by length. Documents learned before the option was set
+// are taken into account.
+func WithLengthModel() Option {
+	return func(c *Classifier) {
+		c.lengthModel = true
+	}
+}
+
+// WithBinarized counts each word at most once per document, both
+// when learning and when scoring documents, like boolean
+// multinomial Naive Bayes, so that repeating a word ("viagra
+// viagra viagra") does not give it more weight. It implies
+// WithMaxTermCountPerDoc(1), whatever the limit set with that
+// option, in either order.
+func WithBinarized() Option {
+	return func(c *Classifier) {
+		c.binarized = true
+	}
+}
+
+// WithFloat32Counts keeps the word counts at float32 precision
+// where the classifier stores them compactly: WriteTo and
+// WriteMsgpack write the counts as float32, and
+// SnapshotPerfectHash keeps its log probabilities as float32,
+// which halves the size of the counts in the serialized model
+// and of the array of log probabilities of the snapshot.
+// Integral counts up to 2^24 (16,777,216) are kept exactly;
+// other counts, such as those of weighted or decayed documents,
+// and log probabilities are rounded to about 7 significant
+// digits, so that scores change by about 1e-7 times the length
+// of the document, which only changes the classification of
+// documents whose best scores are about as close. The counts of
+// the classifier itself stay float64, since a map of float32
+// values takes as much memory as one of float64 values, its
+// slots being padded to the alignment of the string keys.
+func WithFloat32Counts() Option {
+	return func(c *Classifier) {
+		c.float32Counts = true
+	}
+}
+
+// WithMaxWordContribution saturates the total contribution of
+// each unique word of a scored document at the given bound on
+// |log P(W|C_j)|, so that a single token repeated many times
+// cannot force the classification on its own. A value of 0
+// means no bound.
+func WithMaxWordContribution(bound float64) Option {
+	return func(c *Classifier) {
+		c.maxWordContribution = bound
+	}
+}
+
+// WithAdaptiveDefaultProb replaces the fixed tiny probability
+// given to words never seen in a class with one derived from
+// the model, 1/(N_j+V), where N_j is the total count of the
+// class and V is the size of the vocabulary over all classes.
+// The fixed value is far too low for small models, and too high
+// for very large ones.
+func WithAdaptiveDefaultProb() Option {
+	return func(c *Classifier) {
+		c.adaptiveDefaultProb = true
+	}
+}
+
+// WithMinClassDocs excludes classes with fewer than n learned
+// documents from classification: they score -Inf (and a
+// probability of 0) until they are sufficiently trained, so
+// that a newly added class cannot win due to the artifacts of
+// its tiny vocabulary. Documents added with Observe do not
+// count as learned documents. While no class is eligible,
+// Classify returns a Result without a class, Score and
+// Confidence return ErrNoEligibleClass, and
+// ClassifyWithThreshold is not confident.
+func WithMinClassDocs(n int) Option {
+	return func(c *Classifier) {
+		c.minClassDocs = n
+	}
+}
+
+// WithIdfWeighting weights the log probability of each word of
+// a scored document by the IDF of the word, log((1+N)/(1+df))+1,
+// where N is the number of learned documents and df the number
+// of them containing the word. Unlike the TF-IDF classifier, the
+// model keeps raw counts and can go on learning; the document
+// frequencies are maintained separately, from the documents
+// learned with Learn once this option is set.
+func WithIdfWeighting() Option {
+	return func(c *Classifier) {
+		c.idfWeighting = true
+	}
+}
+
+// WithWordTimestamps records when the count of each word in
+// each class last changed, so that words no longer seen can be
+// removed with c.PruneStale(time.Duration).
+func WithWordTimestamps() Option {
+	return func(c *Classifier) {
+		c.wordTimestamps = true
+	}
+}
+
+// WithAutoDecay makes the classifier call c.Decay(factor) before
+// learning each document, so that old training data is forgotten
+// exponentially. A factor of 0 disables it; it panics if the
+// factor is not between 0 and 1.
+func WithAutoDecay(factor float64) Option {
+	if factor < 0 || factor > 1 {
+		panic("decay factor must be in [0, 1]")
+	}
+	return func(c *Classifier) {
+		c.autoDecay = factor
+	}
+}
+
+// WithMinDocFreq makes ConvertTermsFreqToTfIdf drop the terms
+// that occur in fewer than n learned documents over all classes,
+// like the min_df parameter of common TF-IDF implementations,
+// so that the model is not dominated by hapax terms. A value of
+// 0 means no minimum.
+func WithMinDocFreq(n int) Option {
+	return func(c *Classifier) {
+		c.minDocFreq = n
+	}
+}
+
+// WithMaxDocFreq makes ConvertTermsFreqToTfIdf drop the terms
+// that occur in more than the given fraction of the learned
+// documents over all classes, like the max_df parameter of
+// common TF-IDF implementations, so that boilerplate does not
+// dominate the model. A value of 0 means no maximum.
+func WithMaxDocFreq(ratio float64) Option {
+	return func(c *Classifier) {
+		c.maxDocFreq = ratio
+	}
+}
+
+// WithDocumentPriors estimates the prior probability of each
+// class as its share of the learned documents, rather than of
+// the learned words, so that classes with longer documents are
+// not favored.
+func WithDocumentPriors() Option {
+	return func(c *Classifier) {
+		c.documentPriors = true
+	}
+}
+
+// WithSamplingWeights sets the rate at which the documents of
+// each class were sampled to build the training set, for
+// instance 0.1 for a majority class that was downsampled to a
+// tenth, so that the prior probabilities estimated from the
+// training data are corrected to those of the population. The
+// likelihoods P(W|C_j) are not affected by sampling whole
+// documents of a class, and need no correction. Classes missing
+// from rates have a rate of 1.
+func WithSamplingWeights(rates map[Class]float64) Option {
+	return func(c *Classifier) {
+		c.samplingWeights = make(map[Class]float64, len(rates))
+		for class, rate := range rates {
+			if rate <= 0 {
+				panic("sampling rate must be positive")
+			}
+			c.samplingWeights[class] = rate
+		}
+	}
+}
+
+// WithUniformPriors gives all classes the same prior
+// probability, regardless of how much training data each of
+// them has. Priors set with c.SetPriors take precedence.
+func WithUniformPriors() Option {
+	return func(c *Classifier) {
+		c.uniformPriors = true
+	}
+}
+
+// WithNamespaceCaps bounds the total contribution to |log P| of
+// the words of a namespace in a scored document, so that a
+// single spoofable field cannot override all the other
+// evidence. The namespace of a word is the part before the
+// first occurrence of sep, as in "from:alice" with sep ":",
+// and caps maps namespaces to their bounds; words of other
+// namespaces are not capped. Passing nil caps removes the
+// bounds.
+func WithNamespaceCaps(sep string, caps map[string]float64) Option {
+	return func(c *Classifier) {
+		c.namespaceSep = sep
+		c.namespaceCaps = make(map[string]float64, len(caps))
+		for ns, bound := range caps {
+			c.namespaceCaps[ns] = bound
+		}
+	}
+}
+
+// WithTfIdfWeighting sets the formulas of the TF and IDF parts
+// of the weights computed by ConvertTermsFreqToTfIdf, so that
+// they can be made comparable with textbook TF-IDF and other
+// tooling. The default, TfSublinear and IdfClass, is the
+// historical weighting of this package.
+func WithTfIdfWeighting(tf TfWeighting, idf IdfWeighting) Option {
+	return func(c *Classifier) {
+		c.tfIdfTf = tf
+		c.tfIdfIdf = idf
+	}
+}
+
+// WithIncrementalTfIdf lets a TF-IDF classifier go on learning
+// documents after ConvertTermsFreqToTfIdf, instead of panicking.
+// Their raw TF samples are kept, and they are taken into account
+// once c.RefreshTfIdf() recomputes the TF-IDF weights.
+func WithIncrementalTfIdf() Option {
+	return func(c *Classifier) {
+		c.incrementalTfIdf = true
+	}
+}
+
+// Expander maps a word of a document being classified to the
+// words it should be scored as, for instance its synonyms or
+// the parts of an alias ("NYC" to "new", "york").
+type Expander func(word string) []string
+
+// WithExpander sets an Expander that is applied to each word
+// of a document at classification time, which can improve
+// recall without retraining. A word for which the Expander
+// returns no words is scored as is. The Expander is not
+// serialized with the classifier.
+func WithExpander(e Expander) Option {
+	return func(c *Classifier) {
+		c.expander = e
+	}
+}
+
+// WithMistakeReservoir makes the classifier retain the n most
+// recent documents reported as misclassified through c.Feedback,
+// accessible with c.RecentMistakes(). A value of 0 disables
+// the reservoir; NewClassifierOpts returns an error if n is
+// negative, which otherwise disables it as well.
+func WithMistakeReservoir(n int) Option {
+	return func(c *Classifier) {
+		c.mistakesMu.Lock()
+		defer c.mistakesMu.Unlock()
+		c.maxMistakes = n
+		mistakes := c.orderedMistakes()
+		if n < 0 {
+			mistakes = nil
+		} else if len(mistakes) > n {
+			mistakes = mistakes[len(mistakes)-n:]
+		}
+		c.mistakes, c.mistakesNext = mistakes, 0
+	}
+}
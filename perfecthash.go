@@ -0,0 +1,165 @@
+package bayesian
+
+import (
+	"math"
+	"sort"
+)
+
+// maxPerfectHashSeed is the largest seed tried for a bucket of
+// words before the perfect hash is given up on; it is only
+// reached for adversarial vocabularies.
+const maxPerfectHashSeed = 1 << 16
+
+// perfectHash is a minimal perfect hash of a vocabulary, built
+// with the hash and displace method: the words are hashed to
+// buckets, and each bucket gets a seed that hashes its words to
+// distinct free slots, or the slot of its single word. Every word
+// of the vocabulary maps to its own slot in [0, len(words)).
+type perfectHash struct {
+	seeds []int32  // seed of each bucket, or -slot-1 for a single word
+	words []string // word of each slot, to reject other words
+}
+
+// newPerfectHash builds a minimal perfect hash of the distinct
+// words, or returns nil if no seed separates some bucket.
+func newPerfectHash(words []string) *perfectHash {
+	n := len(words)
+	h := &perfectHash{seeds: make([]int32, n), words: make([]string, n)}
+	if n == 0 {
+		return h
+	}
+	buckets := make([][]string, n)
+	for _, word := range words {
+		b := mphHash(0, word) % uint32(n)
+		buckets[b] = append(buckets[b], word)
+	}
+	// place the largest buckets first, while most slots are free
+	order := make([]int, n)
+	for b := range order {
+		order[b] = b
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(buckets[order[i]]) > len(buckets[order[j]])
+	})
+	taken := make([]bool, n)
+	free := 0
+	for _, b := range order {
+		bucket := buckets[b]
+		switch len(bucket) {
+		case 0:
+			continue
+		case 1:
+			for taken[free] {
+				free++
+			}
+			taken[free] = true
+			h.words[free] = bucket[0]
+			h.seeds[b] = int32(-free - 1)
+			continue
+		}
+		slots := make([]uint32, len(bucket))
+		seed := uint32(1)
+	search:
+		for ; seed <= maxPerfectHashSeed; seed++ {
+			for i, word := range bucket {
+				slot := mphHash(seed, word) % uint32(n)
+				if taken[slot] {
+					continue search
+				}
+				for _, other := range slots[:i] {
+					if other == slot {
+						continue search
+					}
+				}
+				slots[i] = slot
+			}
+			break
+		}
+		if seed > maxPerfectHashSeed {
+			return nil
+		}
+		for i, slot := range slots {
+			taken[slot] = true
+			h.words[slot] = bucket[i]
+		}
+		h.seeds[b] = int32(seed)
+	}
+	return h
+}
+
+// slot returns the slot of the word, and false if the word is
+// not in the vocabulary.
+func (h *perfectHash) slot(word string) (int, bool) {
+	n := uint32(len(h.words))
+	if n == 0 {
+		return 0, false
+	}
+	seed := h.seeds[mphHash(0, word)%n]
+	var slot int
+	if seed < 0 {
+		slot = int(-seed - 1)
+	} else {
+		slot = int(mphHash(uint32(seed), word) % n)
+	}
+	return slot, h.words[slot] == word
+}
+
+// mphHash is FNV-1a of the word, started from a state that
+// depends on the seed.
+func mphHash(seed uint32, word string) uint32 {
+	h := uint32(2166136261) ^ (seed * 16777619)
+	for i := 0; i < len(word); i++ {
+		h ^= uint32(word[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// SnapshotPerfectHash works the same as Snapshot, but builds a
+// minimal perfect hash of the vocabulary of the snapshot, and
+// keeps the log probabilities of all classes in an array indexed
+// by it, so that words are looked up by indexing the array
+// instead of probing a map per class. Building the hash takes
+// longer than Snapshot, so it pays off for snapshots that
+// classify many documents. If the hash cannot be built, the
+// snapshot falls back to maps. With WithFloat32Counts, the array
+// holds float32 values.
+func (c *Classifier) SnapshotPerfectHash() *Frozen {
+	f := c.Snapshot()
+	vocab := make(map[string]bool)
+	for _, logProbs := range f.logProbs {
+		for word := range logProbs {
+			vocab[word] = true
+		}
+	}
+	words := make([]string, 0, len(vocab))
+	for word := range vocab {
+		words = append(words, word)
+	}
+	hash := newPerfectHash(words)
+	if hash == nil {
+		return f
+	}
+	n := len(f.Classes)
+	if c.float32Counts {
+		f.hashedProbs32 = make([]float32, len(words)*n)
+	} else {
+		f.hashedProbs = make([]float64, len(words)*n)
+	}
+	for slot, word := range hash.words {
+		for index, logProbs := range f.logProbs {
+			logProb, ok := logProbs[word]
+			if !ok {
+				logProb = math.NaN()
+			}
+			if f.hashedProbs32 != nil {
+				f.hashedProbs32[slot*n+index] = float32(logProb)
+			} else {
+				f.hashedProbs[slot*n+index] = logProb
+			}
+		}
+	}
+	f.hash = hash
+	f.logProbs = nil
+	return f
+}
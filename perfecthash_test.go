@@ -0,0 +1,46 @@
+package bayesian
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestPerfectHash(t *testing.T) {
+	words := make([]string, 10000)
+	for i := range words {
+		words[i] = fmt.Sprint("word", i)
+	}
+	h := newPerfectHash(words)
+	Assert(t, h != nil, "could not build the hash")
+	seen := make(map[int]bool)
+	for _, word := range words {
+		slot, ok := h.slot(word)
+		Assert(t, ok && !seen[slot] && slot < len(words), word, slot)
+		seen[slot] = true
+	}
+	_, ok := h.slot("unknown")
+	Assert(t, !ok, "unknown word should not be found")
+	_, ok = newPerfectHash(nil).slot("word")
+	Assert(t, !ok)
+}
+
+func TestSnapshotPerfectHash(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithIdfWeighting())
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly", "rich"}, Bad)
+	c.SetClassBlocklist(Good, "rich")
+	f := c.SnapshotPerfectHash()
+	Assert(t, f.hash != nil && f.logProbs == nil)
+
+	for _, doc := range [][]string{{"tall", "rich", "girl"}, {"poor", "ugly"}, {"unknown"}} {
+		scores, inx, _ := f.LogScores(doc)
+		want, wantInx, _ := c.Snapshot().LogScores(doc)
+		Assert(t, inx == wantInx, scores, want)
+		for i := range scores {
+			Assert(t, math.Abs(scores[i]-want[i]) < 1e-12, scores, want)
+		}
+	}
+	Assert(t, len(f.dropOOV([]string{"tall", "girl", "poor"})) == 2)
+}
@@ -0,0 +1,579 @@
+package bayesian
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ErrBadSignature is returned when a signed classifier does
+// not match the signature it was stored with.
+var ErrBadSignature = errors.New("classifier signature mismatch")
+
+// serializableClassifier represents a container for
+// Classifier objects whose fields are modifiable by
+// reflection and are therefore writeable by gob.
+type serializableClassifier struct {
+	Classes             []Class
+	Learned             int
+	Seen                int
+	Datas               map[Class]*classData
+	TfIdf               bool
+	DidConvertTfIdf     bool
+	IncrementalTfIdf    bool
+	TfWeighting         TfWeighting
+	TfIdfIdfWeighting   IdfWeighting
+	MaxTermCount        int
+	Smoothing           float64
+	ComplementNB        bool
+	JMLambda            float64
+	DirichletMu         float64
+	MinWordLength       int
+	Stopwords           map[string]bool
+	MaxWordContribution float64
+	NamespaceSep        string
+	NamespaceCaps       map[string]float64
+	Blocklists          map[Class]map[string]bool
+	Allowlists          map[Class]map[string]bool
+	AdaptiveDefaultProb bool
+	MinClassDocs        int
+	IdfWeighting        bool
+	DocFreqs            map[string]int
+	IdfDocs             int
+	WordTimestamps      bool
+	AutoDecay           float64
+	DecayScale          float64
+	PriorCounts         map[Class]int
+	Priors              map[Class]float64
+	UniformPriors       bool
+	DocumentPriors      bool
+	SamplingWeights     map[Class]float64
+	ClassWeights        map[Class]float64
+	Biases              map[Class]float64
+	FeatureWeights      map[string]float64
+	Archived            map[Class]bool
+	AutoCreateClasses   bool
+	MinProbMargin       float64
+	SkipOOV             bool
+	Binarized           bool
+	LengthModel         bool
+	BM25                bool
+	BM25K1              float64
+	BM25B               float64
+	BM25Words           float64
+	BM25Docs            int
+	NGramMin            int
+	NGramMax            int
+	Temperature         float64
+	TieBreak            TieBreak
+	TieBreakSeed        int64
+	TieEpsilon          float64
+	MinDocFreq          int
+	MaxDocFreq          float64
+	ShardVocab          int
+	Float32Counts       bool
+	MaxMistakes         int
+	WindowSize          int
+	WindowTTL           time.Duration
+	Window              []serializableWindowEntry
+}
+
+// legacyClassData is the class data written by releases that
+// counted whole words, whose Total was an int; gob refuses to
+// decode it into the float Total of classData.
+type legacyClassData struct {
+	Freqs   map[string]float64
+	FreqTfs map[string][]float64
+	Total   int
+}
+
+// legacyClassifier is the container written by the same
+// releases.
+type legacyClassifier struct {
+	Classes         []Class
+	Learned         int
+	Seen            int
+	Datas           map[Class]*legacyClassData
+	TfIdf           bool
+	DidConvertTfIdf bool
+}
+
+// classData converts the legacy class data.
+func (d *legacyClassData) classData() *classData {
+	data := newClassData()
+	if d.Freqs != nil {
+		data.Freqs = d.Freqs
+	}
+	if d.FreqTfs != nil {
+		data.FreqTfs = d.FreqTfs
+	}
+	data.Total = float64(d.Total)
+	return data
+}
+
+// decodeCompat decodes a value written by gob into v, falling
+// back to decoding the legacy value into old if the data was
+// written by a release using the legacy format, in which case
+// legacy is true.
+func decodeCompat(r io.Reader, v, old interface{}) (legacy bool, err error) {
+	var buf bytes.Buffer
+	err = gob.NewDecoder(io.TeeReader(r, &buf)).Decode(v)
+	if err == nil || err == io.EOF {
+		return false, err
+	}
+	if gob.NewDecoder(io.MultiReader(&buf, r)).Decode(old) != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// NewClassifierFromFile loads an existing classifier from
+// file. The classifier was previously saved with a call
+// to c.WriteToFile(string).
+func NewClassifierFromFile(name string) (c *Classifier, err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return NewClassifierFromReader(file)
+}
+
+// NewClassifierFromReader: This actually does the deserializing of a Gob encoded classifier
+func NewClassifierFromReader(r io.Reader) (c *Classifier, err error) {
+	w := new(serializableClassifier)
+	old := new(legacyClassifier)
+	legacy, err := decodeCompat(r, w, old)
+	if legacy {
+		w.Classes = old.Classes
+		w.Learned = old.Learned
+		w.Seen = old.Seen
+		w.TfIdf = old.TfIdf
+		w.DidConvertTfIdf = old.DidConvertTfIdf
+		w.Datas = make(map[Class]*classData, len(old.Datas))
+		for class, data := range old.Datas {
+			w.Datas[class] = data.classData()
+		}
+	}
+	return w.classifier(), err
+}
+
+// WriteToFile serializes this classifier to a file.
+func (c *Classifier) WriteToFile(name string) (err error) {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return c.WriteTo(file)
+}
+
+// WriteClassesToFile writes all classes to files. The files are
+// written concurrently to temporary files and flushed to disk,
+// and replace the class files only once all of them were written
+// successfully; if any write fails, the temporary files are
+// removed and the existing class files are left untouched. If
+// replacing a class file fails, the class files already replaced
+// are restored, and the error names those that could not be.
+func (c *Classifier) WriteClassesToFile(rootPath string) (err error) {
+	names := make([]Class, 0, len(c.datas))
+	for name := range c.datas {
+		names = append(names, name)
+	}
+	temps := make([]string, len(names))
+	errs := make([]error, len(names))
+
+	// write the temporary files with a bounded pool of workers
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(names) {
+		workers = len(names)
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				temps[i], errs[i] = c.writeClassTemp(names[i], rootPath)
+			}
+		}()
+	}
+	for i := range names {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err = range errs {
+		if err != nil {
+			break
+		}
+	}
+
+	// replace the class files, moving the replaced ones aside to
+	// restore them if a later one cannot be replaced
+	backups := make([]string, len(names))
+	renamed := make([]bool, len(names))
+	for i := range names {
+		if err != nil {
+			break
+		}
+		target := filepath.Join(rootPath, string(names[i]))
+		if backups[i], err = backupFile(target); err == nil {
+			err = os.Rename(temps[i], target)
+			renamed[i] = err == nil
+		}
+	}
+	var lost []Class
+	for i := range names {
+		if err == nil {
+			if backups[i] != "" {
+				os.Remove(backups[i])
+			}
+			continue
+		}
+		if temps[i] != "" && !renamed[i] {
+			os.Remove(temps[i])
+		}
+		target := filepath.Join(rootPath, string(names[i]))
+		var rerr error
+		switch {
+		case backups[i] != "":
+			rerr = os.Rename(backups[i], target)
+		case renamed[i]:
+			rerr = os.Remove(target)
+		}
+		if rerr != nil {
+			lost = append(lost, names[i])
+		}
+	}
+	if err != nil {
+		if len(lost) > 0 {
+			err = fmt.Errorf("%w; the class files of %v were replaced and could not be restored", err, lost)
+		}
+		return
+	}
+	return syncDir(rootPath)
+}
+
+// backupFile moves the file to a new file next to it and returns
+// the name of the new file, or "" if the file does not exist.
+func backupFile(name string) (backup string, err error) {
+	file, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".bak")
+	if err != nil {
+		return "", err
+	}
+	file.Close()
+	if err = os.Rename(name, file.Name()); err != nil {
+		os.Remove(file.Name())
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// syncDir flushes the entries of the directory to disk, so that
+// the files renamed into it persist.
+func syncDir(name string) error {
+	dir, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// WriteClassToFile writes a single class to file. The class is
+// written to a temporary file first, which then atomically
+// replaces the class file.
+func (c *Classifier) WriteClassToFile(name Class, rootPath string) (err error) {
+	temp, err := c.writeClassTemp(name, rootPath)
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(temp, filepath.Join(rootPath, string(name))); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return syncDir(rootPath)
+}
+
+// writeClassTemp writes a single class to a new temporary file
+// in rootPath and returns its name.
+func (c *Classifier) writeClassTemp(name Class, rootPath string) (temp string, err error) {
+	file, err := os.CreateTemp(rootPath, "."+string(name)+".tmp")
+	if err != nil {
+		return "", err
+	}
+	enc := gob.NewEncoder(file)
+	err = enc.Encode(c.datas[name])
+	if err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0644)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// WriteTo serializes this classifier to GOB and write to Writer.
+func (c *Classifier) WriteTo(w io.Writer) (err error) {
+	enc := gob.NewEncoder(w)
+	err = enc.Encode(c.serializable())
+
+	return
+}
+
+// countingWriter counts the bytes written to w, for the WriteTo
+// methods of io.WriterTo.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	cw.n += int64(n)
+	return
+}
+
+// classifier returns the classifier the container represents.
+func (w *serializableClassifier) classifier() *Classifier {
+	return &Classifier{
+		Classes:             w.Classes,
+		learned:             w.Learned,
+		stats:               &UsageStats{seen: int64(w.Seen)},
+		datas:               w.Datas,
+		tfIdf:               w.TfIdf,
+		DidConvertTfIdf:     w.DidConvertTfIdf,
+		incrementalTfIdf:    w.IncrementalTfIdf,
+		tfIdfTf:             w.TfWeighting,
+		tfIdfIdf:            w.TfIdfIdfWeighting,
+		maxTermCount:        w.MaxTermCount,
+		smoothing:           w.Smoothing,
+		complementNB:        w.ComplementNB,
+		jmLambda:            w.JMLambda,
+		dirichletMu:         w.DirichletMu,
+		minWordLength:       w.MinWordLength,
+		stopwords:           w.Stopwords,
+		maxWordContribution: w.MaxWordContribution,
+		namespaceSep:        w.NamespaceSep,
+		namespaceCaps:       w.NamespaceCaps,
+		blocklists:          w.Blocklists,
+		allowlists:          w.Allowlists,
+		adaptiveDefaultProb: w.AdaptiveDefaultProb,
+		minClassDocs:        w.MinClassDocs,
+		idfWeighting:        w.IdfWeighting,
+		docFreqs:            w.DocFreqs,
+		idfDocs:             w.IdfDocs,
+		wordTimestamps:      w.WordTimestamps,
+		autoDecay:           w.AutoDecay,
+		decayScale:          w.DecayScale,
+		priorCounts:         w.PriorCounts,
+		priors:              w.Priors,
+		uniformPriors:       w.UniformPriors,
+		documentPriors:      w.DocumentPriors,
+		samplingWeights:     w.SamplingWeights,
+		classWeights:        w.ClassWeights,
+		biases:              w.Biases,
+		featureWeights:      w.FeatureWeights,
+		archived:            w.Archived,
+		autoCreateClasses:   w.AutoCreateClasses,
+		minProbMargin:       w.MinProbMargin,
+		skipOOV:             w.SkipOOV,
+		binarized:           w.Binarized,
+		lengthModel:         w.LengthModel,
+		bm25:                w.BM25,
+		bm25K1:              w.BM25K1,
+		bm25B:               w.BM25B,
+		bm25Words:           w.BM25Words,
+		bm25Docs:            w.BM25Docs,
+		ngramMin:            w.NGramMin,
+		ngramMax:            w.NGramMax,
+		temperature:         w.Temperature,
+		tieBreak:            w.TieBreak,
+		tieEpsilon:          w.TieEpsilon,
+		tieBreaker:          restoreTieBreaker(w.TieBreak, w.TieBreakSeed),
+		minDocFreq:          w.MinDocFreq,
+		maxDocFreq:          w.MaxDocFreq,
+		shardVocab:          w.ShardVocab,
+		float32Counts:       w.Float32Counts,
+		maxMistakes:         w.MaxMistakes,
+		windowed:            w.WindowSize > 0 || w.WindowTTL > 0,
+		windowSize:          w.WindowSize,
+		windowTTL:           w.WindowTTL,
+		window:              restoreWindow(w.Window),
+		now:                 time.Now,
+	}
+}
+
+// serializable returns the container for the classifier.
+func (c *Classifier) serializable() *serializableClassifier {
+	return &serializableClassifier{
+		Classes:             c.Classes,
+		Learned:             c.learned,
+		Seen:                c.stats.Seen(),
+		Datas:               c.serializableDatas(),
+		TfIdf:               c.tfIdf,
+		DidConvertTfIdf:     c.DidConvertTfIdf,
+		IncrementalTfIdf:    c.incrementalTfIdf,
+		TfWeighting:         c.tfIdfTf,
+		TfIdfIdfWeighting:   c.tfIdfIdf,
+		MaxTermCount:        c.maxTermCount,
+		Smoothing:           c.smoothing,
+		ComplementNB:        c.complementNB,
+		JMLambda:            c.jmLambda,
+		DirichletMu:         c.dirichletMu,
+		MinWordLength:       c.minWordLength,
+		Stopwords:           c.stopwords,
+		MaxWordContribution: c.maxWordContribution,
+		NamespaceSep:        c.namespaceSep,
+		NamespaceCaps:       c.namespaceCaps,
+		Blocklists:          c.blocklists,
+		Allowlists:          c.allowlists,
+		AdaptiveDefaultProb: c.adaptiveDefaultProb,
+		MinClassDocs:        c.minClassDocs,
+		IdfWeighting:        c.idfWeighting,
+		DocFreqs:            c.docFreqs,
+		IdfDocs:             c.idfDocs,
+		WordTimestamps:      c.wordTimestamps,
+		AutoDecay:           c.autoDecay,
+		DecayScale:          c.decayScale,
+		PriorCounts:         c.priorCounts,
+		Priors:              c.priors,
+		UniformPriors:       c.uniformPriors,
+		DocumentPriors:      c.documentPriors,
+		SamplingWeights:     c.samplingWeights,
+		ClassWeights:        c.classWeights,
+		Biases:              c.biases,
+		FeatureWeights:      c.featureWeights,
+		Archived:            c.archived,
+		AutoCreateClasses:   c.autoCreateClasses,
+		MinProbMargin:       c.minProbMargin,
+		SkipOOV:             c.skipOOV,
+		Binarized:           c.binarized,
+		LengthModel:         c.lengthModel,
+		BM25:                c.bm25,
+		BM25K1:              c.bm25K1,
+		BM25B:               c.bm25B,
+		BM25Words:           c.bm25Words,
+		BM25Docs:            c.bm25Docs,
+		NGramMin:            c.ngramMin,
+		NGramMax:            c.ngramMax,
+		Temperature:         c.temperature,
+		TieBreak:            c.tieBreak,
+		TieEpsilon:          c.tieEpsilon,
+		TieBreakSeed:        c.tieBreaker.getSeed(),
+		MinDocFreq:          c.minDocFreq,
+		MaxDocFreq:          c.maxDocFreq,
+		ShardVocab:          c.shardVocab,
+		Float32Counts:       c.float32Counts,
+		MaxMistakes:         c.maxMistakes,
+		WindowSize:          c.windowSize,
+		WindowTTL:           c.windowTTL,
+		Window:              c.serializableWindow(),
+	}
+}
+
+// serializableDatas returns the class data to serialize, with
+// the word counts rounded to float32 with WithFloat32Counts, for
+// gob to write them in fewer bytes.
+func (c *Classifier) serializableDatas() map[Class]*classData {
+	if !c.float32Counts {
+		return c.datas
+	}
+	datas := make(map[Class]*classData, len(c.datas))
+	for class, data := range c.datas {
+		rounded := *data
+		rounded.Freqs = make(map[string]float64, len(data.Freqs))
+		for word, cnt := range data.Freqs {
+			rounded.Freqs[word] = float64(float32(cnt))
+		}
+		datas[class] = &rounded
+	}
+	return datas
+}
+
+// WriteSigned serializes this classifier to GOB and writes it
+// to the Writer, preceded by an HMAC-SHA256 signature of the
+// serialized data computed with the given key. Use
+// VerifyAndLoad with the same key to read it back.
+func (c *Classifier) WriteSigned(w io.Writer, key []byte) (err error) {
+	var buf bytes.Buffer
+	if err = c.WriteTo(&buf); err != nil {
+		return
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(buf.Bytes())
+	if _, err = w.Write(mac.Sum(nil)); err != nil {
+		return
+	}
+	_, err = buf.WriteTo(w)
+	return
+}
+
+// VerifyAndLoad reads a classifier previously written with
+// c.WriteSigned(io.Writer, []byte). If the data was modified
+// or signed with a different key, ErrBadSignature is returned
+// and nothing is decoded.
+func VerifyAndLoad(r io.Reader, key []byte) (c *Classifier, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < sha256.Size {
+		return nil, ErrBadSignature
+	}
+	sum, payload := data[:sha256.Size], data[sha256.Size:]
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	if !hmac.Equal(sum, mac.Sum(nil)) {
+		return nil, ErrBadSignature
+	}
+	return NewClassifierFromReader(bytes.NewReader(payload))
+}
+
+// ReadClassFromFile loads existing class data from a
+// file.
+func (c *Classifier) ReadClassFromFile(class Class, location string) (err error) {
+	fileName := filepath.Join(location, string(class))
+	file, err := os.Open(fileName)
+
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := new(classData)
+	old := new(legacyClassData)
+	legacy, err := decodeCompat(file, w, old)
+	if legacy {
+		w = old.classData()
+	}
+
+	c.learned++
+	c.datas[class] = w
+	c.invalidate()
+	return
+}
@@ -0,0 +1,205 @@
+package bayesian
+
+import "testing"
+import "fmt"
+import "os"
+import "bytes"
+import "encoding/gob"
+import "path/filepath"
+import "math"
+
+func TestGobs(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	err := c.WriteToFile("test.ser")
+	Assert(t, err == nil, "could not write:", err)
+	d, err := NewClassifierFromFile("test.ser")
+	Assert(t, err == nil, "could not read:", err)
+	fmt.Printf("%v\n", d)
+	scores, _, _ := d.LogScores([]string{"a", "b", "c"})
+	println(scores)
+	data := d.datas[Good]
+	Assert(t, data.Total == 3)
+	Assert(t, data.getWordProb("tall") == float64(1)/float64(3), "tall")
+	Assert(t, data.getWordProb("rich") == float64(1)/float64(3), "rich")
+	Assert(t, d.Learned() == 1)
+	count := d.WordCount()
+	Assert(t, count[0] == 3)
+	Assert(t, count[1] == 0)
+	Assert(t, d.Seen() == 1)
+	// remove the file
+	err = os.Remove("test.ser")
+	Assert(t, err == nil, "could not remove test file:", err)
+}
+
+func TestClassByFile(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	err := c.WriteClassesToFile(".")
+	Assert(t, err == nil, "could not write class:", err)
+
+	d := NewClassifier(Good, Bad)
+	err = d.ReadClassFromFile(Good, ".")
+	Assert(t, err == nil, "could not read:", err)
+	fmt.Printf("%v\n", d)
+	scores, _, _ := d.LogScores([]string{"a", "b", "c"})
+	println(scores)
+	data := d.datas[Good]
+	Assert(t, data.Total == 3)
+	Assert(t, data.getWordProb("tall") == float64(1)/float64(3), "tall")
+	Assert(t, data.getWordProb("rich") == float64(1)/float64(3), "rich")
+	Assert(t, d.Learned() == 1, "learned")
+	count := d.WordCount()
+
+	Assert(t, count[0] == 3)
+	Assert(t, count[1] == 0)
+	Assert(t, d.Seen() == 1)
+	// remove the file
+	err = os.Remove("good")
+	Assert(t, err == nil, "could not remove test file:", err)
+	err = os.Remove("bad")
+	Assert(t, err == nil, "could not remove test file:", err)
+}
+
+func TestSigned(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	key := []byte("secret")
+	var buf bytes.Buffer
+	err := c.WriteSigned(&buf, key)
+	Assert(t, err == nil, "could not write:", err)
+	data := buf.Bytes()
+
+	d, err := VerifyAndLoad(bytes.NewReader(data), key)
+	Assert(t, err == nil, "could not verify:", err)
+	Assert(t, d.datas[Good].Total == 3)
+
+	_, err = VerifyAndLoad(bytes.NewReader(data), []byte("wrong"))
+	Assert(t, err == ErrBadSignature, "wrong key accepted")
+
+	data[len(data)-1] ^= 0xff
+	_, err = VerifyAndLoad(bytes.NewReader(data), key)
+	Assert(t, err == ErrBadSignature, "tampered data accepted")
+}
+
+func TestWriteClassesToFileFailure(t *testing.T) {
+	dir := t.TempDir()
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	err := c.WriteClassesToFile(dir)
+	Assert(t, err == nil, "could not write classes:", err)
+
+	// a class that cannot be written leaves the existing files untouched
+	d := NewClassifier(Good, "bad/class")
+	d.Learn([]string{"bald"}, Good)
+	err = d.WriteClassesToFile(dir)
+	Assert(t, err != nil, "write should fail")
+
+	e := NewClassifier(Good, Bad)
+	err = e.ReadClassFromFile(Good, dir)
+	Assert(t, err == nil, err)
+	Assert(t, e.datas[Good].Total == 3)
+	entries, _ := os.ReadDir(dir)
+	Assert(t, len(entries) == 2, entries)
+}
+
+func TestWriteClassesToFileRollback(t *testing.T) {
+	dir := t.TempDir()
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald"}, Bad)
+	err := c.WriteClassesToFile(dir)
+	Assert(t, err == nil, "could not write classes:", err)
+
+	// a class file that cannot be replaced restores the others
+	Assert(t, os.MkdirAll(filepath.Join(dir, "neutral", "sub"), 0755) == nil)
+	d := NewClassifier(Good, Bad, "neutral")
+	d.Learn([]string{"short"}, Good)
+	d.Learn([]string{"rich", "poor"}, Bad)
+	err = d.WriteClassesToFile(dir)
+	Assert(t, err != nil, "write should fail")
+
+	e := NewClassifier(Good, Bad)
+	Assert(t, e.ReadClassFromFile(Good, dir) == nil && e.ReadClassFromFile(Bad, dir) == nil)
+	Assert(t, e.datas[Good].Total == 3 && e.datas[Bad].Total == 1, e.datas[Good].Total, e.datas[Bad].Total)
+	entries, _ := os.ReadDir(dir)
+	Assert(t, len(entries) == 3, entries)
+}
+
+func TestLegacyFormat(t *testing.T) {
+	// the format of releases counting whole words
+	type classData struct {
+		Freqs   map[string]float64
+		FreqTfs map[string][]float64
+		Total   int
+	}
+	type serializableClassifier struct {
+		Classes         []Class
+		Learned         int
+		Seen            int
+		Datas           map[Class]*classData
+		TfIdf           bool
+		DidConvertTfIdf bool
+	}
+	good := &classData{Freqs: map[string]float64{"tall": 2, "rich": 1}, FreqTfs: map[string][]float64{}, Total: 3}
+	bad := &classData{Freqs: map[string]float64{"poor": 1}, FreqTfs: map[string][]float64{}, Total: 1}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(&serializableClassifier{
+		Classes: []Class{Good, Bad},
+		Learned: 2,
+		Seen:    5,
+		Datas:   map[Class]*classData{Good: good, Bad: bad},
+	})
+	Assert(t, err == nil, "could not encode:", err)
+	c, err := NewClassifierFromReader(&buf)
+	Assert(t, err == nil, "could not read legacy classifier:", err)
+	Assert(t, c.Learned() == 2 && c.Seen() == 5, "counts")
+	Assert(t, c.datas[Good].Total == 3 && c.datas[Bad].Total == 1, "totals")
+	_, inx, _ := c.LogScores([]string{"tall"})
+	Assert(t, inx == 0, "classification")
+
+	err = os.MkdirAll("legacy", 0755)
+	Assert(t, err == nil, "could not create dir:", err)
+	defer os.RemoveAll("legacy")
+	file, err := os.Create("legacy/" + string(Bad))
+	Assert(t, err == nil, "could not create file:", err)
+	err = gob.NewEncoder(file).Encode(bad)
+	file.Close()
+	Assert(t, err == nil, "could not encode class:", err)
+	d := NewClassifier(Good, Bad)
+	err = d.ReadClassFromFile(Bad, "legacy")
+	Assert(t, err == nil, "could not read legacy class:", err)
+	Assert(t, d.datas[Bad].Total == 1 && d.datas[Bad].Freqs["poor"] == 1, "class data")
+}
+
+func TestFloat32Counts(t *testing.T) {
+	c, _ := NewClassifierOpts([]Class{Good, Bad}, WithFloat32Counts())
+	c.LearnWeighted([]string{"tall", "handsome", "rich"}, Good, 0.1)
+	c.LearnWeighted([]string{"bald", "poor", "ugly"}, Bad, 1.0/3)
+	c.Learn([]string{"tall", "tall"}, Good)
+	var full, compact, packed bytes.Buffer
+	d := c.Clone()
+	d.float32Counts = false
+	Assert(t, d.WriteTo(&full) == nil && c.WriteTo(&compact) == nil)
+	Assert(t, compact.Len() < full.Len(), compact.Len(), full.Len())
+	Assert(t, c.WriteMsgpack(&packed) == nil)
+
+	fromGob, err := NewClassifierFromReader(&compact)
+	Assert(t, err == nil, err)
+	fromMsgpack, err := NewClassifierFromMsgpack(&packed)
+	Assert(t, err == nil, err)
+	Assert(t, fromGob.float32Counts, "option should be kept")
+	for _, e := range []*Classifier{fromGob, fromMsgpack} {
+		Assert(t, e.datas[Good].Freqs["tall"] == float64(float32(2.1)) && c.datas[Good].Freqs["rich"] == 0.1, e.datas[Good].Freqs)
+		Assert(t, e.datas[Good].Freqs["rich"] == float64(float32(0.1)), e.datas[Good].Freqs)
+	}
+
+	f := c.SnapshotPerfectHash()
+	Assert(t, f.hashedProbs32 != nil && f.hashedProbs == nil)
+	doc := []string{"tall", "poor", "unseen"}
+	want, _, _ := c.LogScores(doc)
+	got, _, _ := f.LogScores(doc)
+	for i := range want {
+		Assert(t, math.Abs(got[i]-want[i]) < 1e-5, got, want)
+	}
+}
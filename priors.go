@@ -0,0 +1,187 @@
+package bayesian
+
+import "math"
+
+// getPriors returns the prior probabilities for the
+// classes provided -- P(C_j). They are estimated from the
+// word totals of the classes, or their document counts with
+// WithDocumentPriors, corrected for the sampling rates set with
+// WithSamplingWeights, unless they were set with
+// c.SetPriors(map[Class]float64) or WithUniformPriors, or
+// prior counts were set with c.SetPriorCounts(map[Class]int).
+// Archived classes get a prior of 0. With WithEstimator, they
+// are those of the Estimator, normalized.
+//
+// TODO: There is a way to smooth priors, currently
+// not implemented here.
+func (c *Classifier) getPriors() (priors []float64) {
+	if c.estimator == nil {
+		return c.builtinPriors()
+	}
+	n := len(c.Classes)
+	priors = make([]float64, n, n)
+	sum := float64(0)
+	for index, class := range c.Classes {
+		if !c.archived[class] {
+			priors[index] = c.estimator.Prior(class)
+			sum += priors[index]
+		}
+	}
+	if sum != 0 {
+		for i := 0; i < n; i++ {
+			priors[i] /= sum
+		}
+	}
+	return
+}
+
+// builtinPriors returns the prior probabilities of the classes
+// as estimated by the classifier itself, see getPriors.
+func (c *Classifier) builtinPriors() (priors []float64) {
+	n := len(c.Classes)
+	priors = make([]float64, n, n)
+	sum := float64(0)
+	for index, class := range c.Classes {
+		if c.archived[class] {
+			continue
+		}
+		total := c.datas[class].Total
+		switch {
+		case c.priors != nil:
+			total = c.priors[class]
+		case c.uniformPriors:
+			total = 1
+		case c.priorCounts != nil:
+			total = float64(c.priorCounts[class])
+		case c.documentPriors:
+			total = float64(c.datas[class].Docs)
+		}
+		if rate, ok := c.samplingWeights[class]; ok && c.estimatesPriors() {
+			total /= rate
+		}
+		priors[index] = total
+		sum += total
+	}
+	if sum != 0 {
+		for i := 0; i < n; i++ {
+			priors[i] /= sum
+		}
+	}
+	return
+}
+
+// estimatesPriors returns true if the priors are estimated from
+// the training data, rather than given.
+func (c *Classifier) estimatesPriors() bool {
+	return c.priors == nil && !c.uniformPriors && c.priorCounts == nil
+}
+
+// SetPriorCounts sets the number of documents of each class in
+// the population the classifier is applied to, for instance
+// taken from a data warehouse. The prior probabilities are then
+// estimated from these counts, regardless of how many documents
+// of each class were learned; this matters when the training
+// set is deliberately balanced and the production traffic is
+// not. Classes missing from the counts get a prior of 0. Passing
+// nil restores the estimation from the training data.
+func (c *Classifier) SetPriorCounts(counts map[Class]int) {
+	if counts == nil {
+		c.priorCounts = nil
+		return
+	}
+	c.priorCounts = make(map[Class]int, len(counts))
+	for class, cnt := range counts {
+		c.priorCounts[class] = cnt
+	}
+}
+
+// SetPriors sets the prior probabilities of the classes, for
+// instance their known frequencies in the real world, instead
+// of estimating them from the training data. The values are
+// normalized to sum to 1, and take precedence over the counts
+// set with c.SetPriorCounts and over WithUniformPriors. Classes
+// missing from the map get a prior of 0. Passing nil restores
+// the estimation.
+func (c *Classifier) SetPriors(priors map[Class]float64) {
+	if priors == nil {
+		c.priors = nil
+		return
+	}
+	c.priors = make(map[Class]float64, len(priors))
+	for class, prior := range priors {
+		c.priors[class] = prior
+	}
+}
+
+// SetClassWeight sets the weight of a class in classification,
+// 1 by default. The log of the weight is added to the log score
+// of the class in LogScores, ProbScores and SafeProbScores, so
+// a weight below 1 makes the classifier less willing to pick
+// the class, for instance to trade false positives for false
+// negatives when they are more costly; the learned counts are
+// not affected. It returns ErrClassNotFound if the classifier
+// does not have the class, and panics if the weight is not
+// positive.
+func (c *Classifier) SetClassWeight(class Class, weight float64) error {
+	if weight <= 0 {
+		panic("class weight must be positive")
+	}
+	if _, ok := c.datas[class]; !ok {
+		return ErrClassNotFound
+	}
+	if c.classWeights == nil {
+		c.classWeights = make(map[Class]float64)
+	}
+	if weight == 1 {
+		delete(c.classWeights, class)
+	} else {
+		c.classWeights[class] = weight
+	}
+	return nil
+}
+
+// ClassWeight returns the weight of the class set with
+// c.SetClassWeight, 1 by default.
+func (c *Classifier) ClassWeight(class Class) float64 {
+	if weight, ok := c.classWeights[class]; ok {
+		return weight
+	}
+	return 1
+}
+
+// SetBias sets the bias of a class in classification, a value
+// added to its log score, 0 by default, convenient to shift the
+// decision boundary between classes without retraining. It adds
+// up with the log of the weight set with c.SetClassWeight. It
+// returns ErrClassNotFound if the classifier does not have the
+// class.
+func (c *Classifier) SetBias(class Class, logBias float64) error {
+	if _, ok := c.datas[class]; !ok {
+		return ErrClassNotFound
+	}
+	if c.biases == nil {
+		c.biases = make(map[Class]float64)
+	}
+	if logBias == 0 {
+		delete(c.biases, class)
+	} else {
+		c.biases[class] = logBias
+	}
+	return nil
+}
+
+// Bias returns the bias of the class set with c.SetBias, 0 by
+// default.
+func (c *Classifier) Bias(class Class) float64 {
+	return c.biases[class]
+}
+
+// logBias returns the value added to the log score of the
+// class: its bias plus the log of its weight.
+func (c *Classifier) logBias(class Class) float64 {
+	bias := c.biases[class]
+	if weight, ok := c.classWeights[class]; ok {
+		bias += math.Log(weight)
+	}
+	return bias
+}
@@ -0,0 +1,126 @@
+package bayesian
+
+import "testing"
+import "math"
+import "bytes"
+
+func TestSetPriorCounts(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	priors := c.getPriors()
+	Assert(t, priors[0] == 0.5 && priors[1] == 0.5, priors)
+
+	c.SetPriorCounts(map[Class]int{Good: 900, Bad: 100})
+	priors = c.getPriors()
+	Assert(t, priors[0] == 0.9 && priors[1] == 0.1, priors)
+	scores, likely, _ := c.LogScores([]string{"girl"})
+	Assert(t, likely == 0 && scores[1] == math.Log(0.1)+math.Log(defaultProb), scores)
+
+	c.SetPriorCounts(nil)
+	priors = c.getPriors()
+	Assert(t, priors[0] == 0.5, priors)
+}
+
+func TestSetClassWeight(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	before, _, _ := c.LogScores([]string{"girl"})
+	Assert(t, c.SetClassWeight(Bad, 0.2) == nil)
+	Assert(t, c.SetClassWeight("Ugly", 0.2) == ErrClassNotFound)
+	Assert(t, c.ClassWeight(Bad) == 0.2 && c.ClassWeight(Good) == 1)
+	scores, likely, strict := c.LogScores([]string{"girl"})
+	Assert(t, likely == 0 && strict, scores)
+	Assert(t, scores[0] == before[0] && scores[1] == before[1]+math.Log(0.2), scores)
+	Assert(t, c.datas[Bad].Total == 3)
+
+	probs, _, _ := c.ProbScores([]string{"girl"})
+	Assert(t, math.Abs(probs[0]-1/1.2) < 1e-9, probs)
+
+	Assert(t, c.SetClassWeight(Bad, 1) == nil)
+	scores, _, strict = c.LogScores([]string{"girl"})
+	Assert(t, !strict && scores[1] == before[1], scores)
+}
+
+func TestSetBias(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	before, _, _ := c.LogScores([]string{"girl"})
+	Assert(t, c.SetBias(Good, -0.5) == nil)
+	Assert(t, c.SetBias("Ugly", 1) == ErrClassNotFound)
+	Assert(t, math.Abs(c.Bias(Good)+0.5) < 1e-12 && c.Bias(Bad) == 0)
+	scores, likely, _ := c.LogScores([]string{"girl"})
+	Assert(t, likely == 1, scores)
+	Assert(t, math.Abs(scores[0]-(before[0]-0.5)) < 1e-12 && scores[1] == before[1], scores)
+
+	// extreme biases neither underflow nor overflow
+	Assert(t, c.SetBias(Good, -800) == nil && c.SetBias(Bad, 800) == nil)
+	scores, likely, _ = c.LogScores([]string{"girl"})
+	Assert(t, likely == 1 && math.Abs(scores[0]-(before[0]-800)) < 1e-9, scores)
+	d := c.Clone()
+	Assert(t, d.Bias(Good) == -800 && d.Bias(Bad) == 800)
+	var buf bytes.Buffer
+	Assert(t, c.WriteTo(&buf) == nil)
+	d, err := NewClassifierFromReader(&buf)
+	Assert(t, err == nil && d.Bias(Good) == -800, err)
+	Assert(t, c.SetBias(Good, 0) == nil && c.Bias(Good) == 0)
+}
+
+func TestSetPriors(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald"}, Bad)
+	c.SetPriorCounts(map[Class]int{Good: 1, Bad: 3})
+
+	c.SetOptions(WithUniformPriors())
+	priors := c.getPriors()
+	Assert(t, priors[0] == 0.5 && priors[1] == 0.5, priors)
+
+	c.SetPriors(map[Class]float64{Good: 3, Bad: 1})
+	priors = c.getPriors()
+	Assert(t, priors[0] == 0.75 && priors[1] == 0.25, priors)
+
+	c.SetPriors(nil)
+	c.uniformPriors = false
+	priors = c.getPriors()
+	Assert(t, priors[0] == 0.25 && priors[1] == 0.75, priors)
+}
+
+func TestDocumentPriors(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "nice", "blonde", "funny"}, Good)
+	c.Learn([]string{"bald"}, Bad)
+	c.Learn([]string{"poor"}, Bad)
+	priors := c.getPriors()
+	Assert(t, priors[0] == 0.75, priors)
+
+	c.SetOptions(WithDocumentPriors())
+	priors = c.getPriors()
+	Assert(t, math.Abs(priors[0]-1.0/3) < 1e-12 && math.Abs(priors[1]-2.0/3) < 1e-12, priors)
+}
+
+func TestSamplingWeights(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithDocumentPriors(), WithSamplingWeights(map[Class]float64{Good: 0.1}))
+	c.Learn([]string{"tall"}, Good)
+	c.Learn([]string{"bald"}, Bad)
+	priors := c.getPriors()
+	Assert(t, math.Abs(priors[0]-10.0/11) < 1e-12, priors)
+
+	c.SetOptions(WithUniformPriors())
+	priors = c.getPriors()
+	Assert(t, priors[0] == 0.5, "given priors are not corrected", priors)
+}
+
+func TestLogScoresWithPriors(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	scores, likely, _ := c.LogScoresWithPriors([]string{"girl"}, []float64{0.05, 0.95})
+	Assert(t, likely == 1, scores)
+	Assert(t, scores[0] == math.Log(0.05)+math.Log(defaultProb), scores)
+	priors := c.getPriors()
+	Assert(t, priors[0] == 0.5, "priors are not changed")
+}
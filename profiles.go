@@ -0,0 +1,98 @@
+package bayesian
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// WordScore is a word together with a score, such as its count
+// or log-odds in a class.
+type WordScore struct {
+	Word  string  `json:"word"`
+	Score float64 `json:"score"`
+}
+
+// ClassProfile describes a class by its most characteristic
+// words: the most frequent ones, and those with the highest
+// log-odds, log P(W|C_j) - log P(W|not C_j), of appearing in
+// the class rather than in any other.
+type ClassProfile struct {
+	Class        Class       `json:"class"`
+	TopByCount   []WordScore `json:"top_by_count"`
+	TopByLogOdds []WordScore `json:"top_by_log_odds"`
+}
+
+// ClassProfiles returns the profile of each class, listing the
+// top n words by count and by log-odds.
+func (c *Classifier) ClassProfiles(n int) (profiles []ClassProfile) {
+	for _, class := range c.Classes {
+		data := c.datas[class]
+		byCount := make([]WordScore, 0, len(data.Freqs))
+		byLogOdds := make([]WordScore, 0, len(data.Freqs))
+		for word, cnt := range data.Freqs {
+			rest, restTotal := float64(0), float64(0)
+			for _, other := range c.Classes {
+				if other != class {
+					rest += c.datas[other].Freqs[word]
+					restTotal += c.datas[other].Total
+				}
+			}
+			restProb := defaultProb
+			if rest > 0 {
+				restProb = rest / restTotal
+			}
+			byCount = append(byCount, WordScore{word, cnt * c.scale()})
+			byLogOdds = append(byLogOdds, WordScore{word, math.Log(cnt/data.Total) - math.Log(restProb)})
+		}
+		profiles = append(profiles, ClassProfile{class, topWords(byCount, n), topWords(byLogOdds, n)})
+	}
+	return
+}
+
+// topWords returns the n words with the highest scores, breaking
+// ties by word.
+func topWords(scores []WordScore, n int) []WordScore {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Word < scores[j].Word
+	})
+	if len(scores) > n {
+		scores = scores[:n]
+	}
+	return scores
+}
+
+// ExportClassProfiles writes the profile of each class, listing
+// the top n words by count and by log-odds, to the Writer in the
+// given format: "json", or "csv" with the columns class, ranking
+// ("count" or "log_odds"), rank, word and score.
+func (c *Classifier) ExportClassProfiles(w io.Writer, n int, format string) (err error) {
+	profiles := c.ClassProfiles(n)
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(profiles)
+	case "csv":
+		out := csv.NewWriter(w)
+		out.Write([]string{"class", "ranking", "rank", "word", "score"})
+		for _, profile := range profiles {
+			for rank, ws := range profile.TopByCount {
+				out.Write([]string{string(profile.Class), "count", strconv.Itoa(rank + 1), ws.Word, strconv.FormatFloat(ws.Score, 'g', -1, 64)})
+			}
+			for rank, ws := range profile.TopByLogOdds {
+				out.Write([]string{string(profile.Class), "log_odds", strconv.Itoa(rank + 1), ws.Word, strconv.FormatFloat(ws.Score, 'g', -1, 64)})
+			}
+		}
+		out.Flush()
+		return out.Error()
+	}
+	return fmt.Errorf("unknown profile format %q", format)
+}
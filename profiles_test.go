@@ -0,0 +1,35 @@
+package bayesian
+
+import "testing"
+import "bytes"
+import "encoding/json"
+import "strings"
+
+func TestClassProfiles(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "tall", "rich", "man"}, Good)
+	c.Learn([]string{"poor", "man", "man"}, Bad)
+	profiles := c.ClassProfiles(2)
+	Assert(t, len(profiles) == 2)
+	good := profiles[0]
+	Assert(t, good.Class == Good && len(good.TopByCount) == 2)
+	Assert(t, good.TopByCount[0].Word == "tall" && good.TopByCount[0].Score == 2, good.TopByCount)
+	Assert(t, good.TopByCount[1].Word == "man", good.TopByCount)
+	Assert(t, good.TopByLogOdds[0].Word == "tall" && good.TopByLogOdds[1].Word == "rich", good.TopByLogOdds)
+
+	var buf bytes.Buffer
+	err := c.ExportClassProfiles(&buf, 2, "json")
+	Assert(t, err == nil, err)
+	var decoded []ClassProfile
+	err = json.Unmarshal(buf.Bytes(), &decoded)
+	Assert(t, err == nil && len(decoded) == 2 && decoded[1].TopByCount[0].Word == "man", err, decoded)
+
+	buf.Reset()
+	err = c.ExportClassProfiles(&buf, 1, "csv")
+	Assert(t, err == nil, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	Assert(t, len(lines) == 5 && lines[1] == "good,count,1,tall,2", lines)
+
+	err = c.ExportClassProfiles(&buf, 1, "xml")
+	Assert(t, err != nil)
+}
@@ -0,0 +1,68 @@
+package bayesian
+
+import (
+	"math"
+	"sort"
+)
+
+// FeatureScore is the mutual information of a word with the
+// class, see c.FeatureScores.
+type FeatureScore struct {
+	Word  string
+	Score float64 // mutual information, in nats
+	Class Class   // class the word is most indicative of
+}
+
+// FeatureScores returns the mutual information of each word of
+// the vocabulary with the class of the words of the learned
+// documents, from the highest to the lowest, so that the words
+// that drive the model can be reported. It is computed from the
+// word counts: for a word counted n_j times in class j, of N_j
+// words, and N words overall, it is the sum over the classes of
+// P(w,C_j) log(P(w,C_j)/(P(w)P(C_j))) and the same for the
+// other words. The class of each word is the one where it is
+// most frequent relative to the size of the class.
+func (c *Classifier) FeatureScores() []FeatureScore {
+	corpus := c.getCorpus()
+	total := corpus.total
+	scores := make([]FeatureScore, 0, len(corpus.freqs))
+	if total <= 0 {
+		return scores
+	}
+	scale := c.scale()
+	for word, cnt := range corpus.freqs {
+		fs := FeatureScore{Word: word}
+		best := math.Inf(-1)
+		pw := cnt / total
+		for _, class := range c.Classes {
+			classTotal := c.datas[class].Total * scale
+			if classTotal <= 0 {
+				continue
+			}
+			pc := classTotal / total
+			n := c.datas[class].Freqs[word] * scale
+			fs.Score += mutualInfoTerm(n/total, pw, pc)
+			fs.Score += mutualInfoTerm((classTotal-n)/total, 1-pw, pc)
+			if rate := n / classTotal; rate > best {
+				best = rate
+				fs.Class = class
+			}
+		}
+		scores = append(scores, fs)
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Word < scores[j].Word
+	})
+	return scores
+}
+
+// mutualInfoTerm returns p log(p/(px*py)), 0 if p is 0.
+func mutualInfoTerm(p, px, py float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	return p * math.Log(p/(px*py))
+}
@@ -0,0 +1,23 @@
+package bayesian
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFeatureScores(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "rich"}, Good)
+	c.Learn([]string{"tall", "poor"}, Bad)
+	scores := c.FeatureScores()
+	Assert(t, len(scores) == 3, scores)
+	// tall is evenly spread over the classes
+	Assert(t, scores[2].Word == "tall" && math.Abs(scores[2].Score) < 1e-12, scores)
+	// poor: P(w,Bad) = 1/4, P(w) = 1/4, P(Bad) = 1/2, and the
+	// other words: 1/4 and 1/2 for Bad, 1/2 for Good
+	want := 0.25*math.Log(2) + 0.25*math.Log(0.25/(0.75*0.5)) + 0.5*math.Log(0.5/(0.75*0.5))
+	Assert(t, scores[0].Word == "poor" && scores[0].Class == Bad, scores)
+	Assert(t, math.Abs(scores[0].Score-want) < 1e-12, scores[0].Score, want)
+	Assert(t, scores[1].Word == "rich" && scores[1].Class == Good && math.Abs(scores[1].Score-want) < 1e-12, scores)
+	Assert(t, len(NewClassifier(Good, Bad).FeatureScores()) == 0)
+}
@@ -0,0 +1,98 @@
+package bayesian
+
+import "fmt"
+
+// Result is the outcome of classifying a document with
+// c.Classify, gathering the values returned separately by the
+// other scoring methods.
+type Result struct {
+	Class         Class       // most likely class
+	Index         int         // index of Class in c.Classes
+	Strict        bool        // whether no other class is as likely
+	Scores        []float64   // log score of each class
+	Probabilities []float64   // probability of each class
+	Margin        float64     // log score margin over the runner-up
+	OOV           int         // number of words not seen in any class
+	Ties          []Class     // classes tied for the highest score, nil if Strict
+	Meta          interface{} // metadata given to ClassifyWithMeta, if any
+}
+
+// Classify classifies the document and returns the outcome as a
+// Result. The log scores are those of LogScores, and the
+// probabilities are computed from them without underflow, at the
+// temperature set with WithTemperature. The
+// margin is the one reported by Confidence. Ties between classes
+// are broken as configured with WithTieBreak. If no class can
+// be scored, for instance because none is eligible, see
+// WithMinClassDocs, the Result has no Class, an Index of -1 and
+// probabilities of 0.
+func (c *Classifier) Classify(document []string) Result {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling Classify.")
+	}
+	return c.classify(document, nil)
+}
+
+// ClassifyWithMeta works the same as Classify, but attaches the
+// metadata, for instance an ID of the document, to the Result
+// and to the MismatchReport of the document, if any, so that
+// they can be correlated with the document without keeping a
+// map from documents to IDs.
+func (c *Classifier) ClassifyWithMeta(document []string, meta interface{}) Result {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling ClassifyWithMeta.")
+	}
+	return c.classify(document, meta)
+}
+
+// classify implements Classify and ClassifyWithMeta.
+func (c *Classifier) classify(document []string, meta interface{}) Result {
+	freqs := c.getCorpus().freqs
+	oov := 0
+	for _, word := range document {
+		if _, ok := freqs[word]; !ok {
+			oov++
+		}
+	}
+	scores := c.logScoresWithPriors(document, c.getPriors(), meta)
+	if !anyScored(scores) {
+		return Result{
+			Index:         -1,
+			Scores:        scores,
+			Probabilities: make([]float64, len(scores)),
+			OOV:           oov,
+			Meta:          meta,
+		}
+	}
+	inx, strict := c.pickMax(scores)
+	c.record(inx)
+	var tied []Class
+	if !strict {
+		for _, i := range c.ties(scores, inx) {
+			tied = append(tied, c.Classes[i])
+		}
+	}
+	return Result{
+		Class:         c.Classes[inx],
+		Index:         inx,
+		Strict:        strict,
+		Scores:        scores,
+		Probabilities: softmax(c.tempered(scores)),
+		Margin:        scoreMargin(scores),
+		OOV:           oov,
+		Ties:          tied,
+		Meta:          meta,
+	}
+}
+
+// String returns a one-line summary of the result for logging,
+// for instance
+//
+//	Good p=0.667 margin=0.693 strict=true oov=1
+func (r Result) String() string {
+	p := 0.0
+	if r.Index >= 0 && r.Index < len(r.Probabilities) {
+		p = r.Probabilities[r.Index]
+	}
+	return fmt.Sprintf("%s p=%.3f margin=%.3f strict=%t oov=%d", r.Class, p, r.Margin, r.Strict, r.OOV)
+}
@@ -0,0 +1,39 @@
+package bayesian
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	doc := []string{"rich", "poor", "man"}
+	r := c.Classify(doc)
+	scores, inx, strict := c.LogScores(doc)
+	Assert(t, r.Class == Good && r.Index == inx && r.Strict == strict, r)
+	Assert(t, r.Scores[0] == scores[0] && r.Scores[1] == scores[1], r.Scores, scores)
+	Assert(t, math.Abs(r.Probabilities[0]-2.0/3) < 1e-9, r.Probabilities)
+	Assert(t, math.Abs(r.Margin-math.Log(2)) < 1e-9, r.Margin)
+	Assert(t, r.OOV == 1, r.OOV)
+	Assert(t, r.String() == "good p=0.667 margin=0.693 strict=true oov=1", r.String())
+}
+
+func TestClassifyWithMeta(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	var reports []MismatchReport
+	c.SetOptions(WithMismatchMonitor(MismatchMonitor{
+		MaxOOV:    0.5,
+		MaxFolded: 1,
+		Report:    func(r MismatchReport) { reports = append(reports, r) },
+	}))
+	r := c.ClassifyWithMeta([]string{"rich", "man"}, "doc-1")
+	Assert(t, r.Class == Good && r.Meta == "doc-1", r)
+	r = c.ClassifyWithMeta([]string{"some", "man"}, 42)
+	Assert(t, r.Meta == 42, r)
+	Assert(t, len(reports) == 1 && reports[0].Meta == 42, reports)
+	Assert(t, c.Classify([]string{"rich"}).Meta == nil)
+}
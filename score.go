@@ -0,0 +1,213 @@
+package bayesian
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScoreOptions changes how a single call to c.Score scores a
+// document, so that variations of the scoring can be tried out
+// without configuring copies of the classifier. The zero value
+// scores like ProbScores, without counting the document as seen.
+type ScoreOptions struct {
+	// SkipOOV ignores the words of the document that the
+	// classifier has not seen in any class.
+	SkipOOV bool
+	// LengthNormalize divides the contribution of the words
+	// by their number, so that long documents do not have
+	// more extreme scores than short ones.
+	LengthNormalize bool
+	// Temperature divides the log scores before they are
+	// converted to probabilities: above 1 it softens the
+	// probabilities, below 1 it sharpens them. 0 means the
+	// temperature set with WithTemperature.
+	Temperature float64
+	// ClassSubset restricts the classification to the given
+	// classes; the others get a probability of 0. Empty means
+	// all classes.
+	ClassSubset []Class
+	// CountSeen counts the document in the usage statistics,
+	// see c.Seen(), and checks it with the mismatch monitor,
+	// like the other scoring methods do. Leave it unset for
+	// shadow evaluations against a live model.
+	CountSeen bool
+}
+
+// Score returns the probability of the document for each class,
+// and the index of the most likely one, like ProbScores, but
+// scored as configured by opts. The probabilities are computed
+// from the log scores without underflow. It returns
+// ErrNoEligibleClass, and an index of -1, if no class can be
+// scored, for instance because none is eligible, see
+// WithMinClassDocs, or in opts.ClassSubset.
+func (c *Classifier) Score(document []string, opts ScoreOptions) (scores []float64, inx int, strict bool, err error) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling Score.")
+	}
+	if opts.CountSeen {
+		c.monitor(document, nil)
+	}
+	document = c.expand(document)
+	if opts.SkipOOV && !c.skipOOV {
+		document = c.dropOOV(document)
+	}
+	var subset map[Class]bool
+	if len(opts.ClassSubset) > 0 {
+		subset = make(map[Class]bool, len(opts.ClassSubset))
+		for _, class := range opts.ClassSubset {
+			subset[class] = true
+		}
+	}
+	temperature := opts.Temperature
+	if temperature <= 0 {
+		temperature = c.temperature
+	}
+	if temperature <= 0 {
+		temperature = 1
+	}
+
+	n := len(c.Classes)
+	scores = make([]float64, n, n)
+	priors := c.getPriors()
+	for index, class := range c.Classes {
+		if !c.IsEligible(class) || (subset != nil && !subset[class]) {
+			scores[index] = math.Inf(-1)
+			continue
+		}
+		words := c.logScore(class, 1, document) + c.lengthLogProb(class, len(document))
+		if opts.LengthNormalize && len(document) > 0 {
+			words /= float64(len(document))
+		}
+		scores[index] = (math.Log(priors[index]) + c.logBias(class) + words) / temperature
+	}
+	if !anyScored(scores) {
+		return make([]float64, n), -1, false, ErrNoEligibleClass
+	}
+	scores = softmax(scores)
+	inx, strict = c.pickMax(scores)
+	if opts.CountSeen {
+		c.record(inx)
+	}
+	return scores, inx, strict, nil
+}
+
+// ClassifyWithThreshold returns the most likely class of the
+// document, and whether the classifier is confident of it: ok is
+// false if the probability of the class is below minProb, or if
+// it exceeds the probability of the runner-up by less than the
+// margin set with WithMinProbMargin, so that documents the model
+// has no opinion on are not forced into a class, or if no class
+// can be scored, see Score. The
+// probabilities are computed from the log scores without
+// underflow.
+func (c *Classifier) ClassifyWithThreshold(document []string, minProb float64) (class Class, ok bool) {
+	probs, inx, strict, err := c.Score(document, ScoreOptions{CountSeen: true})
+	if err != nil {
+		return "", false
+	}
+	runnerUp := float64(0)
+	for i, p := range probs {
+		if i != inx && p > runnerUp {
+			runnerUp = p
+		}
+	}
+	ok = strict && probs[inx] >= minProb && probs[inx]-runnerUp >= c.minProbMargin
+	return c.Classes[inx], ok
+}
+
+// Confidence returns how confident the classifier is of the
+// classification of the document: the margin between the best
+// and the second best log score, and the entropy, in nats, of
+// the probabilities of the classes, which is 0 when a single
+// class is certain and log(n) when all n classes are equally
+// likely. It returns ErrNoEligibleClass if no class can be
+// scored, for instance because none is eligible, see
+// WithMinClassDocs.
+func (c *Classifier) Confidence(document []string) (margin, entropy float64, err error) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling Confidence.")
+	}
+	scores := c.logScores(document)
+	if !anyScored(scores) {
+		return 0, 0, ErrNoEligibleClass
+	}
+	return scoreMargin(scores), probEntropy(softmax(scores)), nil
+}
+
+// scoreMargin returns the difference between the largest and
+// the second largest of the scores.
+func scoreMargin(scores []float64) float64 {
+	first, second := math.Inf(-1), math.Inf(-1)
+	for _, score := range scores {
+		if score > first {
+			first, second = score, first
+		} else if score > second {
+			second = score
+		}
+	}
+	return first - second
+}
+
+// probEntropy returns the entropy of the probabilities, in nats.
+func probEntropy(probs []float64) (entropy float64) {
+	for _, p := range probs {
+		if p > 0 {
+			entropy -= p * math.Log(p)
+		}
+	}
+	return
+}
+
+// LogOdds returns the log odds of class a against class b for
+// the document, log(P(a|D)/P(b|D)), which is positive if a is
+// the more likely of the two. It panics if the classifier does
+// not have one of the classes.
+func (c *Classifier) LogOdds(document []string, a, b Class) float64 {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling LogOdds.")
+	}
+	ia, ib := c.classIndex(a), c.classIndex(b)
+	scores := c.logScores(document)
+	return scores[ia] - scores[ib]
+}
+
+// classIndex returns the index of the class in c.Classes, and
+// panics if the classifier does not have the class.
+func (c *Classifier) classIndex(class Class) int {
+	for index, cl := range c.Classes {
+		if cl == class {
+			return index
+		}
+	}
+	panic(fmt.Sprintf("%v: %q", ErrClassNotFound, class))
+}
+
+// BinaryScore returns the log odds of the target class against
+// all the other classes pooled together for the document,
+// log(P(target|D)/(1-P(target|D))), so that "is it the target
+// class or not" can be thresholded without training a separate
+// two-class model. It panics if the classifier does not have
+// the class.
+func (c *Classifier) BinaryScore(document []string, target Class) float64 {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling BinaryScore.")
+	}
+	it := c.classIndex(target)
+	scores := c.logScores(document)
+	rest := make([]float64, 0, len(scores)-1)
+	max := math.Inf(-1)
+	for index, score := range scores {
+		if index != it {
+			rest = append(rest, score)
+			max = math.Max(max, score)
+		}
+	}
+	if math.IsInf(max, -1) {
+		return math.Inf(1)
+	}
+	sum := 0.0
+	for _, score := range rest {
+		sum += math.Exp(score - max)
+	}
+	return scores[it] - (max + math.Log(sum))
+}
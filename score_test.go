@@ -0,0 +1,105 @@
+package bayesian
+
+import "testing"
+import "math"
+
+func TestScore(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	doc := []string{"tall", "girl"}
+
+	want, _, _ := c.ProbScores(doc)
+	scores, inx, _, _ := c.Score(doc, ScoreOptions{})
+	Assert(t, inx == 0 && math.Abs(scores[0]-want[0]) < 1e-9, scores, want)
+	Assert(t, c.Seen() == 1, "dry run counted")
+	c.Score(doc, ScoreOptions{CountSeen: true})
+	Assert(t, c.Seen() == 2)
+
+	scores, _, _, _ = c.Score(doc, ScoreOptions{ClassSubset: []Class{Bad}})
+	Assert(t, scores[0] == 0 && scores[1] == 1, scores)
+
+	sharp, _, _, _ := c.Score(doc, ScoreOptions{SkipOOV: true})
+	soft, _, _, _ := c.Score(doc, ScoreOptions{SkipOOV: true, Temperature: 100})
+	Assert(t, soft[0] < sharp[0] && soft[0] > 0.5, soft, sharp)
+
+	long := []string{"tall", "tall", "tall", "tall"}
+	normalized, _, _, _ := c.Score(long, ScoreOptions{LengthNormalize: true})
+	short, _, _, _ := c.Score([]string{"tall"}, ScoreOptions{})
+	Assert(t, math.Abs(normalized[0]-short[0]) < 1e-9, normalized, short)
+}
+
+func TestClassifyWithThreshold(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	class, ok := c.ClassifyWithThreshold([]string{"tall"}, 0.9)
+	Assert(t, class == Good && ok)
+	_, ok = c.ClassifyWithThreshold([]string{"unknown"}, 0.6)
+	Assert(t, !ok, "no opinion")
+
+	// Good is twice as likely as Bad
+	doc := []string{"rich", "poor"}
+	_, ok = c.ClassifyWithThreshold(doc, 0.9)
+	Assert(t, !ok, "below threshold")
+	class, ok = c.ClassifyWithThreshold(doc, 0.6)
+	Assert(t, class == Good && ok)
+	c.SetOptions(WithMinProbMargin(0.4))
+	_, ok = c.ClassifyWithThreshold(doc, 0.6)
+	Assert(t, !ok, "below margin")
+}
+
+func TestConfidence(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	margin, entropy, _ := c.Confidence([]string{"girl"})
+	Assert(t, margin == 0 && math.Abs(entropy-math.Log(2)) < 1e-12, margin, entropy)
+
+	margin, entropy, _ = c.Confidence([]string{"tall"})
+	Assert(t, math.Abs(margin-(math.Log(1.0/3)-math.Log(defaultProb))) < 1e-9, margin)
+	Assert(t, entropy < 1e-6, entropy)
+}
+
+func TestLogOdds(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	doc := []string{"rich", "poor"}
+	odds := c.LogOdds(doc, Good, Bad)
+	Assert(t, math.Abs(odds-math.Log(2)) < 1e-9, odds)
+	Assert(t, c.LogOdds(doc, Bad, Good) == -odds)
+}
+
+func TestTemperature(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	doc := []string{"rich", "poor"}
+	sharp, _, _ := c.ProbScores(doc)
+	Assert(t, math.Abs(sharp[0]-2.0/3) < 1e-9, sharp)
+
+	c.SetOptions(WithTemperature(math.Log(2) / math.Log(1.5)))
+	soft, inx, _ := c.ProbScores(doc)
+	Assert(t, inx == 0 && math.Abs(soft[0]-0.6) < 1e-9, soft)
+	safe, _, _, err := c.SafeProbScores(doc)
+	Assert(t, err == nil && safe[0] == soft[0], safe, err)
+	r := c.Classify(doc)
+	Assert(t, math.Abs(r.Probabilities[0]-0.6) < 1e-9, r.Probabilities)
+	frozen, _, _ := c.Snapshot().ProbScores(doc)
+	Assert(t, math.Abs(frozen[0]-0.6) < 1e-9, frozen)
+	scored, _, _, _ := c.Score(doc, ScoreOptions{})
+	Assert(t, math.Abs(scored[0]-0.6) < 1e-9, scored)
+}
+
+func TestBinaryScore(t *testing.T) {
+	c := NewClassifier(Good, Bad, "Ugly")
+	c.Learn([]string{"tall", "handsome", "rich", "rich"}, Good)
+	c.Learn([]string{"bald", "poor"}, Bad)
+	c.Learn([]string{"warts", "poor"}, "Ugly")
+	doc := []string{"rich", "poor"}
+	probs, _, _ := c.ProbScores(doc)
+	score := c.BinaryScore(doc, Good)
+	Assert(t, math.Abs(score-math.Log(probs[0]/(1-probs[0]))) < 1e-9, score, probs)
+	Assert(t, c.BinaryScore(doc, Bad) < 0)
+}
@@ -0,0 +1,678 @@
+package bayesian
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// logScores returns the log score of the document for each
+// class, after expanding it with the configured Expander.
+// Classes that are not eligible score -Inf.
+func (c *Classifier) logScores(document []string) []float64 {
+	return c.logScoresWithPriors(document, c.getPriors(), nil)
+}
+
+// logScoresWithPriors works the same as logScores, but with the
+// given prior probabilities, and passes the metadata of the
+// document to the MismatchMonitor.
+func (c *Classifier) logScoresWithPriors(document []string, priors []float64, meta interface{}) []float64 {
+	c.monitor(document, meta)
+	return c.logScoresExpanded(c.expand(document), priors)
+}
+
+// logScoresExpanded works the same as logScoresWithPriors, but
+// for a document that was already expanded, see c.expand, and
+// without monitoring it.
+func (c *Classifier) logScoresExpanded(document []string, priors []float64) []float64 {
+	n := len(c.Classes)
+	scores := make([]float64, n, n)
+
+	// calculate the score for each class
+	for index, class := range c.Classes {
+		if !c.IsEligible(class) {
+			scores[index] = math.Inf(-1)
+			continue
+		}
+		scores[index] = c.logScore(class, priors[index], document) + c.lengthLogProb(class, len(document))
+		scores[index] += c.logBias(class)
+	}
+	return scores
+}
+
+// lengthLogProb returns the log probability of a document of n
+// words in the class under a Poisson model of the lengths of its
+// learned documents, if configured with WithLengthModel, or 0.
+func (c *Classifier) lengthLogProb(class Class, n int) float64 {
+	data := c.datas[class]
+	if !c.lengthModel || data.Docs == 0 || data.Length == 0 {
+		return 0
+	}
+	return poissonLogProb(n, data.Length/float64(data.Docs))
+}
+
+// poissonLogProb returns the log probability of k under a
+// Poisson distribution of mean lambda.
+func poissonLogProb(k int, lambda float64) float64 {
+	lgamma, _ := math.Lgamma(float64(k) + 1)
+	return float64(k)*math.Log(lambda) - lambda - lgamma
+}
+
+// IsEligible returns true if the class has enough learned
+// documents to take part in classification, see
+// WithMinClassDocs, and is not archived, see c.ArchiveClass.
+func (c *Classifier) IsEligible(class Class) bool {
+	return c.datas[class].Docs >= c.minClassDocs && !c.archived[class]
+}
+
+// record counts the classification of a document as the class
+// at index inx in the usage statistics, unless no class is
+// eligible, see WithMinClassDocs, and the document was not
+// classified.
+func (c *Classifier) record(inx int) {
+	for _, class := range c.Classes {
+		if c.IsEligible(class) {
+			c.stats.record(c.Classes[inx])
+			return
+		}
+	}
+}
+
+// anyScored returns whether any of the log scores is above -Inf,
+// that is whether any class was scored.
+func anyScored(logScores []float64) bool {
+	for _, s := range logScores {
+		if !math.IsInf(s, -1) {
+			return true
+		}
+	}
+	return false
+}
+
+// tempered returns the log scores divided by the temperature set
+// with WithTemperature, if any.
+func (c *Classifier) tempered(logScores []float64) []float64 {
+	return temper(logScores, c.temperature)
+}
+
+// temper returns the log scores divided by the temperature t,
+// unless it is 0 or 1.
+func temper(logScores []float64, t float64) []float64 {
+	if t <= 0 || t == 1 {
+		return logScores
+	}
+	scores := make([]float64, len(logScores))
+	for i, s := range logScores {
+		scores[i] = s / t
+	}
+	return scores
+}
+
+// probs converts log scores into probabilities by normalizing
+// their exponentials. Note that this is prone to underflow.
+func probs(logScores []float64) []float64 {
+	n := len(logScores)
+	scores := make([]float64, n, n)
+	sum := float64(0)
+	for i, logScore := range logScores {
+		scores[i] = math.Exp(logScore)
+		sum += scores[i]
+	}
+	for i := 0; i < n; i++ {
+		scores[i] /= sum
+	}
+	return scores
+}
+
+// expand forms the n-grams of the document configured with
+// WithNGrams, replaces each of them with the words returned by
+// the configured Expander, if any, and drops the
+// words ignored as configured by WithMinWordLength,
+// WithStopwords and WithSkipOOV, and the repeated words with
+// WithBinarized.
+func (c *Classifier) expand(document []string) []string {
+	return c.expandWords(c.ngrams(document))
+}
+
+// expandWords works the same as expand, but without forming the
+// n-grams configured with WithNGrams.
+func (c *Classifier) expandWords(document []string) []string {
+	document = dropIgnored(expandDocument(c.expander, document), c.minWordLength, c.stopwords)
+	if c.skipOOV {
+		document = c.dropOOV(document)
+	}
+	if c.binarized {
+		document = dedupe(document)
+	}
+	return document
+}
+
+// dedupe returns the words of the document without repetitions,
+// in the order of their first occurrence.
+func dedupe(document []string) []string {
+	seen := make(map[string]bool, len(document))
+	unique := make([]string, 0, len(document))
+	for _, word := range document {
+		if !seen[word] {
+			seen[word] = true
+			unique = append(unique, word)
+		}
+	}
+	return unique
+}
+
+// dropOOV returns the words of the document that the classifier
+// has seen in some class.
+func (c *Classifier) dropOOV(document []string) []string {
+	freqs := c.getCorpus().freqs
+	known := make([]string, 0, len(document))
+	for _, word := range document {
+		if _, ok := freqs[word]; ok {
+			known = append(known, word)
+		}
+	}
+	return known
+}
+
+// ignored returns true if the word is ignored as configured by
+// WithMinWordLength and WithStopwords.
+func (c *Classifier) ignored(word string) bool {
+	return ignored(word, c.minWordLength, c.stopwords)
+}
+
+// ignored returns true if the word is a stopword, or shorter
+// than minLength characters.
+func ignored(word string, minLength int, stopwords map[string]bool) bool {
+	return stopwords[word] || (minLength > 0 && utf8.RuneCountInString(word) < minLength)
+}
+
+// dropIgnored returns the words of the document that are not
+// ignored.
+func dropIgnored(document []string, minLength int, stopwords map[string]bool) []string {
+	if minLength <= 0 && len(stopwords) == 0 {
+		return document
+	}
+	kept := make([]string, 0, len(document))
+	for _, word := range document {
+		if !ignored(word, minLength, stopwords) {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
+
+// expandDocument replaces each word of the document with the
+// words returned by the Expander e, if it is not nil.
+func expandDocument(e Expander, document []string) []string {
+	if e == nil {
+		return document
+	}
+	expanded := make([]string, 0, len(document))
+	for _, word := range document {
+		if words := e(word); len(words) > 0 {
+			expanded = append(expanded, words...)
+		} else {
+			expanded = append(expanded, word)
+		}
+	}
+	return expanded
+}
+
+// wordWeight returns the weight of the log probability of the
+// word in a scored document: its weight set with
+// c.SetFeatureWeights, 1 by default, times its IDF if IDF
+// weighting is enabled.
+func (c *Classifier) wordWeight(word string) float64 {
+	weight := float64(1)
+	if w, ok := c.featureWeights[word]; ok {
+		weight = w
+	}
+	if !c.idfWeighting {
+		return weight
+	}
+	return weight * c.idf(word)
+}
+
+// idf returns the IDF of the word used by WithIdfWeighting.
+func (c *Classifier) idf(word string) float64 {
+	// smoothed IDF, as if an extra document contained every word
+	return math.Log(float64(1+c.idfDocs)/float64(1+c.docFreqs[word])) + 1
+}
+
+// logScore returns the log score of the document for the
+// class with the given prior probability: the sum of
+// the logarithms as outlined in the refresher. If a maximum
+// word contribution is configured, the total contribution of
+// each unique word is saturated at that bound, and if namespace
+// caps are configured, so is the total contribution of the
+// words of each capped namespace.
+func (c *Classifier) logScore(class Class, prior float64, document []string) float64 {
+	score := math.Log(prior)
+	if c.maxWordContribution <= 0 && len(c.namespaceCaps) == 0 {
+		for _, word := range document {
+			score += c.wordWeight(word) * c.wordLogProb(class, word)
+		}
+		return score
+	}
+	contribs := make(map[string]float64)
+	words := make([]string, 0)
+	for _, word := range document {
+		if _, ok := contribs[word]; !ok {
+			words = append(words, word)
+		}
+		contribs[word] += c.wordWeight(word) * c.wordLogProb(class, word)
+	}
+	return c.boundContributions(score, words, contribs)
+}
+
+// logScoreFreqs works the same as logScore, but for a document
+// given as the count, or weight, of each of the words, in a
+// fixed order.
+func (c *Classifier) logScoreFreqs(class Class, prior float64, words []string, freqs map[string]float64) float64 {
+	score := math.Log(prior)
+	contribs := make(map[string]float64, len(words))
+	for _, word := range words {
+		contribs[word] = freqs[word] * c.wordWeight(word) * c.wordLogProb(class, word)
+	}
+	if c.maxWordContribution <= 0 && len(c.namespaceCaps) == 0 {
+		for _, word := range words {
+			score += contribs[word]
+		}
+		return score
+	}
+	return c.boundContributions(score, words, contribs)
+}
+
+// boundContributions adds the contributions of the unique words
+// to the score, bounded as configured by WithMaxWordContribution
+// and WithNamespaceCaps.
+func (c *Classifier) boundContributions(score float64, words []string, contribs map[string]float64) float64 {
+	var namespaces []string
+	nsScores := make(map[string]float64)
+	for _, word := range words {
+		contrib := contribs[word]
+		if c.maxWordContribution > 0 {
+			contrib = math.Max(contrib, -c.maxWordContribution)
+		}
+		if ns, ok := c.cappedNamespace(word); ok {
+			if _, seen := nsScores[ns]; !seen {
+				namespaces = append(namespaces, ns)
+			}
+			nsScores[ns] += contrib
+			continue
+		}
+		score += contrib
+	}
+	for _, ns := range namespaces {
+		score += math.Max(nsScores[ns], -c.namespaceCaps[ns])
+	}
+	return score
+}
+
+// cappedNamespace returns the namespace of the word, the part
+// before the namespace separator, if a cap is configured for it
+// with WithNamespaceCaps.
+func (c *Classifier) cappedNamespace(word string) (string, bool) {
+	if len(c.namespaceCaps) == 0 {
+		return "", false
+	}
+	i := strings.Index(word, c.namespaceSep)
+	if i < 0 {
+		return "", false
+	}
+	ns := word[:i]
+	_, ok := c.namespaceCaps[ns]
+	return ns, ok
+}
+
+// LogScores produces "log-likelihood"-like scores that can
+// be used to classify documents into classes.
+//
+// The value of the score is proportional to the likelihood,
+// as determined by the classifier, that the given document
+// belongs to the given class. This is true even when scores
+// returned are negative, which they will be (since we are
+// taking logs of probabilities).
+//
+// The index j of the score corresponds to the class given
+// by c.Classes[j].
+//
+// Additionally returned are "inx" and "strict" values. The
+// inx corresponds to the maximum score in the array. If more
+// than one of the scores holds the maximum values, then
+// strict is false.
+//
+// Unlike c.Probabilities(), this function is not prone to
+// floating point underflow and is relatively safe to use.
+func (c *Classifier) LogScores(document []string) (scores []float64, inx int, strict bool) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling LogScores.")
+	}
+
+	scores = c.logScores(document)
+	inx, strict = c.pickMax(scores)
+	c.record(inx)
+	return scores, inx, strict
+}
+
+// LogScoresWithPriors works the same as LogScores, but with the
+// given prior probabilities of the classes, indexed like
+// c.Classes, instead of those of the classifier, for instance
+// the class frequencies of the traffic being classified. It
+// panics if there is not one prior for each class.
+func (c *Classifier) LogScoresWithPriors(document []string, priors []float64) (scores []float64, inx int, strict bool) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling LogScoresWithPriors.")
+	}
+	if len(priors) != len(c.Classes) {
+		panic("provide a prior for each class")
+	}
+
+	scores = c.logScoresWithPriors(document, priors, nil)
+	inx, strict = c.pickMax(scores)
+	c.record(inx)
+	return scores, inx, strict
+}
+
+// LogScoresChunked works the same as LogScores, but scores very
+// large documents in chunks of chunkSize words. The word scores
+// of each chunk are normalized to log probabilities before they
+// are summed, so that the scores stay in a reasonable range, and
+// the priors are added once, so that they are not dominated. The
+// most likely class is the same as with LogScores, up to
+// rounding errors, except that the bounds of WithMaxWordContribution
+// and WithNamespaceCaps apply to each chunk. It panics if
+// chunkSize is not positive.
+func (c *Classifier) LogScoresChunked(document []string, chunkSize int) (scores []float64, inx int, strict bool) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling LogScoresChunked.")
+	}
+	if chunkSize <= 0 {
+		panic("chunk size must be positive")
+	}
+	c.monitor(document, nil)
+	document = c.expand(document)
+
+	n := len(c.Classes)
+	scores = make([]float64, n, n)
+	priors := c.getPriors()
+	eligible := false
+	for index, class := range c.Classes {
+		if !c.IsEligible(class) {
+			scores[index] = math.Inf(-1)
+			continue
+		}
+		eligible = true
+		scores[index] = math.Log(priors[index]) + c.lengthLogProb(class, len(document)) + c.logBias(class)
+	}
+	chunk := make([]float64, n, n)
+	for start := 0; eligible && start < len(document); start += chunkSize {
+		end := start + chunkSize
+		if end > len(document) {
+			end = len(document)
+		}
+		for index, class := range c.Classes {
+			chunk[index] = math.Inf(-1)
+			if c.IsEligible(class) {
+				chunk[index] = c.logScore(class, 1, document[start:end])
+			}
+		}
+		for index, s := range logNormalize(chunk) {
+			scores[index] += s
+		}
+	}
+	inx, strict = c.pickMax(scores)
+	c.record(inx)
+	return scores, inx, strict
+}
+
+// LogScoresFreqs works the same as LogScores, but scores a
+// document given as the number of occurrences of each word, for
+// instance counts taken from an inverted index, rather than as
+// the words themselves. Words with a count of 0 or less are
+// ignored. The scores are the same as those of the document up
+// to rounding errors. With WithNGrams, the counts should be those
+// of the n-grams of the document. The MismatchMonitor, if any,
+// checks the document the counts were taken from.
+func (c *Classifier) LogScoresFreqs(freqs map[string]int) (scores []float64, inx int, strict bool) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling LogScoresFreqs.")
+	}
+
+	// iterate in a fixed order so that scores are reproducible
+	keys := make([]string, 0, len(freqs))
+	for word, cnt := range freqs {
+		if cnt > 0 {
+			keys = append(keys, word)
+		}
+	}
+	sort.Strings(keys)
+	if c.mismatchMonitor != nil {
+		// monitor the document the counts were taken from
+		var document []string
+		for _, key := range keys {
+			for i := 0; i < freqs[key]; i++ {
+				document = append(document, key)
+			}
+		}
+		c.monitor(document, nil)
+	}
+	var words []string
+	counts := make(map[string]float64, len(keys))
+	for _, key := range keys {
+		for _, word := range c.expandWords([]string{key}) {
+			if _, ok := counts[word]; !ok {
+				words = append(words, word)
+			}
+			counts[word] += float64(freqs[key])
+			if c.binarized {
+				counts[word] = 1
+			}
+		}
+	}
+	length := 0
+	for _, cnt := range counts {
+		length += int(cnt)
+	}
+
+	n := len(c.Classes)
+	scores = make([]float64, n, n)
+	priors := c.getPriors()
+	for index, class := range c.Classes {
+		if !c.IsEligible(class) {
+			scores[index] = math.Inf(-1)
+			continue
+		}
+		scores[index] = c.logScoreFreqs(class, priors[index], words, counts)
+		scores[index] += c.lengthLogProb(class, length) + c.logBias(class)
+	}
+	inx, strict = c.pickMax(scores)
+	c.record(inx)
+	return scores, inx, strict
+}
+
+// LogScoresVector works the same as LogScores, but scores
+// a document given as a sparse feature vector. The log
+// probability of each feature is multiplied by its weight, and
+// the sum of the weights is the length of the document, see
+// WithLengthModel. Like the words of a document, the features
+// are weighted as configured with c.SetFeatureWeights and
+// WithIdfWeighting, and bounded as configured with
+// WithMaxWordContribution and WithNamespaceCaps.
+func (c *Classifier) LogScoresVector(vec map[string]float64) (scores []float64, inx int, strict bool) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling LogScoresVector.")
+	}
+
+	// iterate in a fixed order so that scores are reproducible
+	words := make([]string, 0, len(vec))
+	length := float64(0)
+	for word, weight := range vec {
+		words = append(words, word)
+		length += weight
+	}
+	sort.Strings(words)
+	c.monitor(words, nil)
+
+	n := len(c.Classes)
+	scores = make([]float64, n, n)
+	priors := c.getPriors()
+	for index, class := range c.Classes {
+		if !c.IsEligible(class) {
+			scores[index] = math.Inf(-1)
+			continue
+		}
+		scores[index] = c.logScoreFreqs(class, priors[index], words, vec)
+		scores[index] += c.lengthLogProb(class, int(math.Round(length))) + c.logBias(class)
+	}
+	inx, strict = c.pickMax(scores)
+	c.record(inx)
+	return scores, inx, strict
+}
+
+// ProbScores works the same as LogScores, but delivers
+// actual probabilities as discussed above. Note that float64
+// underflow is possible if the word list contains too
+// many words that have probabilities very close to 0. The
+// probabilities can be softened with WithTemperature.
+//
+// Notes on underflow: underflow is going to occur when you're
+// trying to assess large numbers of words that you have
+// never seen before. Depending on the application, this
+// may or may not be a concern. Consider using SafeProbScores()
+// instead.
+func (c *Classifier) ProbScores(doc []string) (scores []float64, inx int, strict bool) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling ProbScores.")
+	}
+	scores = probs(c.tempered(c.logScores(doc)))
+	inx, strict = c.pickMax(scores)
+	c.record(inx)
+	return scores, inx, strict
+}
+
+// SafeProbScores works the same as ProbScores, but is
+// able to detect underflow in those cases where underflow
+// results in the reverse classification. If an underflow is detected,
+// this method returns an ErrUnderflow, allowing the user to deal with it as
+// necessary. Note that underflow, under certain rare circumstances,
+// may still result in incorrect probabilities being returned,
+// but this method guarantees that all error-less invokations
+// are properly classified.
+//
+// Underflow detection is more costly because it also
+// has to make additional log score calculations.
+func (c *Classifier) SafeProbScores(doc []string) (scores []float64, inx int, strict bool, err error) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling SafeProbScores.")
+	}
+	return c.safeProbScores(doc)
+}
+
+// SafeProbResult holds the outcome of scoring one document with
+// SafeProbScoresBatch; the fields correspond to the values
+// returned by SafeProbScores.
+type SafeProbResult struct {
+	Scores []float64
+	Inx    int
+	Strict bool
+	Err    error
+}
+
+// UnderflowStats summarizes the underflows detected when
+// scoring a batch of documents.
+type UnderflowStats struct {
+	Documents  int   // number of documents scored
+	Underflows int   // number of documents with an underflow
+	Indices    []int // indices of the documents with an underflow
+}
+
+// SafeProbScoresBatch works the same as SafeProbScores for each
+// of the documents, returning the results in the same order,
+// along with statistics about the underflows detected. This is
+// convenient for offline rescoring jobs.
+func (c *Classifier) SafeProbScoresBatch(docs [][]string) (results []SafeProbResult, stats UnderflowStats) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling SafeProbScoresBatch.")
+	}
+	results = make([]SafeProbResult, len(docs))
+	for i, doc := range docs {
+		r := &results[i]
+		r.Scores, r.Inx, r.Strict, r.Err = c.safeProbScores(doc)
+		if r.Err == ErrUnderflow {
+			stats.Underflows++
+			stats.Indices = append(stats.Indices, i)
+		}
+	}
+	stats.Documents = len(docs)
+	return
+}
+
+// safeProbScores implements SafeProbScores.
+func (c *Classifier) safeProbScores(doc []string) (scores []float64, inx int, strict bool, err error) {
+	logScores := c.logScores(doc)
+	scores = probs(c.tempered(logScores))
+	inx, strict = findMax(scores)
+	logInx, logStrict := findMax(logScores)
+
+	// detect underflow -- the size
+	// relation between scores and logScores
+	// must be preserved or something is wrong
+	if inx != logInx || strict != logStrict {
+		err = ErrUnderflow
+	}
+	inx, strict = c.settleTie(scores, inx, strict)
+	c.record(inx)
+	return scores, inx, strict, err
+}
+
+// SetClassBlocklist sets the words that are ignored when
+// scoring documents against the given class; they are treated
+// as if they had never been seen in the class. This allows
+// unwanted associations to be removed without retraining.
+// Passing no words clears the blocklist.
+func (c *Classifier) SetClassBlocklist(class Class, words ...string) {
+	c.blocklists = setWordList(c.blocklists, class, words)
+}
+
+// SetClassAllowlist restricts the words that are taken into
+// account when scoring documents against the given class to
+// the given words; all other words are treated as if they had
+// never been seen in the class. Passing no words clears the
+// allowlist.
+func (c *Classifier) SetClassAllowlist(class Class, words ...string) {
+	c.allowlists = setWordList(c.allowlists, class, words)
+}
+
+// SetFeatureWeights sets weights that multiply the log
+// probabilities of the words of scored documents, for instance
+// importance weights computed offline to boost the words of
+// subject lines, without retraining. Words missing from weights
+// have a weight of 1, and passing nil clears the weights. The
+// weights are copied.
+func (c *Classifier) SetFeatureWeights(weights map[string]float64) {
+	c.featureWeights = nil
+	if len(weights) > 0 {
+		c.featureWeights = copyFreqs(weights)
+	}
+	c.invalidate()
+}
+
+// setWordList stores the words as the list of the class in
+// lists, allocating lists if necessary.
+func setWordList(lists map[Class]map[string]bool, class Class, words []string) map[Class]map[string]bool {
+	if lists == nil {
+		lists = make(map[Class]map[string]bool)
+	}
+	if len(words) == 0 {
+		delete(lists, class)
+		return lists
+	}
+	list := make(map[string]bool, len(words))
+	for _, word := range words {
+		list[word] = true
+	}
+	lists[class] = list
+	return lists
+}
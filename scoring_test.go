@@ -0,0 +1,345 @@
+package bayesian
+
+import "testing"
+import "math"
+import "strings"
+
+func TestVector(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.LearnVector(map[string]float64{"tall": 1.5, "rich": 0.5}, Good)
+	c.LearnVector(map[string]float64{"poor": 2}, Bad)
+	data := c.datas[Good]
+	Assert(t, data.Total == 2)
+	Assert(t, data.getWordProb("tall") == 0.75, "tall")
+
+	scores, likely, strict := c.LogScoresVector(map[string]float64{"tall": 2, "man": 1})
+	Assert(t, scores[0] > scores[1], "not good")
+	Assert(t, likely == 0 && strict)
+	Assert(t, c.Learned() == 2 && c.Seen() == 1)
+
+	before := scores
+	c.SetClassWeight(Good, 1e-9)
+	scores, _, _ = c.LogScoresVector(map[string]float64{"tall": 2, "man": 1})
+	Assert(t, math.Abs(scores[0]-before[0]-math.Log(1e-9)) < 1e-9, scores, before)
+	c.SetOptions(WithMinClassDocs(2))
+	c.LearnVector(map[string]float64{"poor": 1}, Bad)
+	scores, likely, _ = c.LogScoresVector(map[string]float64{"tall": 2})
+	Assert(t, math.IsInf(scores[0], -1) && likely == 1, scores)
+}
+
+func TestVectorBookkeeping(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	Assert(t, c.LearnVectorErr(map[string]float64{"tall": 1}, "ugly") == ErrClassNotFound)
+	c.SetOptions(WithSlidingWindow(2, 0), WithIdfWeighting(), WithStopwords([]string{"the"}))
+	c.LearnVector(map[string]float64{"tall": 1, "the": 3}, Good)
+	Assert(t, c.datas[Good].Total == 1 && c.idfDocs == 1 && c.docFreqs["tall"] == 1)
+	c.LearnVector(map[string]float64{"poor": 2}, Bad)
+	c.LearnVector(map[string]float64{"rich": 1}, Good)
+	Assert(t, c.datas[Good].Total == 1 && c.datas[Good].Freqs["tall"] == 0, "not expired")
+	Assert(t, c.idfDocs == 2 && c.docFreqs["tall"] == 0 && c.Learned() == 2)
+
+	// vectors of counts score like the documents
+	c = NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly", "ugly"}, Bad)
+	doc := []string{"tall", "tall", "ugly", "man"}
+	vec := map[string]float64{"tall": 2, "ugly": 1, "man": 1}
+	for _, opts := range [][]Option{nil, {WithMaxWordContribution(1)}, {WithIdfWeighting()}} {
+		c.SetOptions(opts...)
+		want, _, _ := c.LogScores(doc)
+		scores, _, _ := c.LogScoresVector(vec)
+		Assert(t, math.Abs(scores[0]-want[0]) < 1e-9 && math.Abs(scores[1]-want[1]) < 1e-9, scores, want)
+	}
+}
+
+func TestLogScoresFreqs(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	doc := []string{"tall", "tall", "poor", "man"}
+	freqs := map[string]int{"tall": 2, "poor": 1, "man": 1, "ugly": 0}
+	for _, opts := range [][]Option{nil, {WithMaxWordContribution(5)}, {WithStopwords([]string{"man"})}} {
+		c.SetOptions(opts...)
+		want, _, _ := c.LogScores(doc)
+		scores, _, _ := c.LogScoresFreqs(freqs)
+		Assert(t, math.Abs(scores[0]-want[0]) < 1e-9 && math.Abs(scores[1]-want[1]) < 1e-9, scores, want)
+	}
+}
+
+func TestMaxWordContribution(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "nice"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly", "spam"}, Bad)
+	doc := []string{"tall", "rich", "nice"}
+	for i := 0; i < 1000; i++ {
+		doc = append(doc, "spam")
+	}
+	_, likely, _ := c.LogScores(doc)
+	Assert(t, likely == 1, "repeated word should win without a bound")
+
+	c.SetOptions(WithMaxWordContribution(10))
+	score, likely, _ := c.LogScores(doc)
+	Assert(t, likely == 0, "repeated word should be saturated", score)
+}
+
+func TestNamespaceCaps(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "nice", "from:alice"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly", "from:bob", "from:carol", "from:dave"}, Bad)
+	doc := []string{"tall", "rich", "from:bob", "from:carol", "from:dave"}
+	_, likely, _ := c.LogScores(doc)
+	Assert(t, likely == 1, "sender should win without a cap")
+
+	c.SetOptions(WithNamespaceCaps(":", map[string]float64{"from": 5}))
+	scores, likely, _ := c.LogScores(doc)
+	Assert(t, likely == 0, "sender should be capped", scores)
+	Assert(t, math.Abs(scores[0]-(math.Log(5.0/11)+2*math.Log(0.2)-5)) < 1e-9, scores)
+}
+
+func TestClassWordLists(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"acme", "poor", "ugly"}, Bad)
+	_, likely, _ := c.LogScores([]string{"acme"})
+	Assert(t, likely == 1)
+
+	c.SetClassBlocklist(Bad, "acme")
+	scores, likely, strict := c.LogScores([]string{"acme"})
+	Assert(t, scores[0] == scores[1] && likely == 0 && !strict, scores)
+
+	c.SetClassBlocklist(Bad)
+	c.SetClassAllowlist(Bad, "poor")
+	scores, _, _ = c.LogScores([]string{"acme", "poor"})
+	Assert(t, scores[1] == math.Log(0.5)+math.Log(defaultProb)+math.Log(float64(1)/3), scores)
+}
+
+func TestExpander(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"new", "york", "city"}, Good)
+	c.Learn([]string{"los", "angeles", "city"}, Bad)
+	scores, _, strict := c.LogScores([]string{"nyc"})
+	Assert(t, !strict, scores)
+
+	c.SetOptions(WithExpander(func(word string) []string {
+		if word == "nyc" {
+			return []string{"new", "york"}
+		}
+		return nil
+	}))
+	scores, likely, strict := c.LogScores([]string{"nyc", "city"})
+	Assert(t, likely == 0 && strict, scores)
+	expected, _, _ := c.LogScores([]string{"new", "york", "city"})
+	Assert(t, scores[0] == expected[0] && scores[1] == expected[1], scores, expected)
+}
+
+func TestSkipOOV(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor"}, Bad)
+	doc := []string{"tall", "xyzzy", "plugh"}
+	c.SetOptions(WithSkipOOV())
+	scores, _, _ := c.LogScores(doc)
+	expected, _, _ := c.LogScores([]string{"tall"})
+	Assert(t, scores[0] == expected[0] && scores[1] == expected[1], scores, expected)
+
+	frozen, _, _ := c.Snapshot().LogScores(doc)
+	Assert(t, frozen[0] == expected[0] && frozen[1] == expected[1], frozen, expected)
+}
+
+func TestMinClassDocs(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithMinClassDocs(2))
+	c.Learn([]string{"tall", "handsome"}, Good)
+	c.Learn([]string{"rich"}, Good)
+	c.Learn([]string{"poor"}, Bad)
+	Assert(t, c.IsEligible(Good) && !c.IsEligible(Bad))
+
+	scores, likely, _ := c.LogScores([]string{"poor"})
+	Assert(t, likely == 0 && math.IsInf(scores[1], -1), scores)
+	probs, _, _ := c.ProbScores([]string{"poor"})
+	Assert(t, probs[0] == 1 && probs[1] == 0, probs)
+
+	c.Learn([]string{"poor", "ugly"}, Bad)
+	_, likely, _ = c.LogScores([]string{"poor"})
+	Assert(t, likely == 1)
+
+	// no eligible class
+	d := NewClassifier(Good, Bad)
+	d.SetOptions(WithMinClassDocs(2))
+	d.Learn([]string{"tall"}, Good)
+	d.ProbScores([]string{"tall"})
+	result := d.Classify([]string{"tall"})
+	Assert(t, result.Index == -1 && result.Class == "" && result.Probabilities[0] == 0, result)
+	Assert(t, result.String() != "")
+	_, inx, _, err := d.Score([]string{"tall"}, ScoreOptions{})
+	Assert(t, err == ErrNoEligibleClass && inx == -1, err)
+	_, _, err = d.Confidence([]string{"tall"})
+	Assert(t, err == ErrNoEligibleClass, err)
+	_, ok := d.ClassifyWithThreshold([]string{"tall"}, 0)
+	Assert(t, !ok)
+	Assert(t, d.Seen() == 0, d.Seen())
+
+	// nor in the subset
+	_, _, _, err = c.Score([]string{"tall"}, ScoreOptions{ClassSubset: []Class{"Ugly"}})
+	Assert(t, err == ErrNoEligibleClass, err)
+}
+
+func TestIdfWeighting(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithIdfWeighting())
+	c.Learn([]string{"the", "tall", "man"}, Good)
+	c.Learn([]string{"the", "rich", "man"}, Good)
+	c.Learn([]string{"the", "poor", "guy"}, Bad)
+	Assert(t, c.docFreqs["the"] == 3 && c.docFreqs["man"] == 2 && c.idfDocs == 3)
+	Assert(t, c.wordWeight("the") == 1)
+	Assert(t, c.wordWeight("poor") == math.Log(2)+1)
+
+	scores, _, _ := c.LogScores([]string{"the", "poor"})
+	expected := math.Log(float64(6)/9) + math.Log(float64(2)/6) + (math.Log(2)+1)*math.Log(defaultProb)
+	Assert(t, scores[0] == expected, scores[0], expected)
+
+	// learning continues to update the weights
+	c.Learn([]string{"poor"}, Bad)
+	Assert(t, c.wordWeight("the") == math.Log(float64(5)/4)+1)
+}
+
+func TestSafeProbScoresBatch(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	long := make([]string, 1000)
+	for i := range long {
+		long[i] = "word"
+	}
+	long[0] = "poor"
+	results, stats := c.SafeProbScoresBatch([][]string{{"tall"}, long, {"poor"}})
+	Assert(t, len(results) == 3 && stats.Documents == 3)
+	Assert(t, stats.Underflows == 1 && stats.Indices[0] == 1, stats)
+	Assert(t, results[0].Inx == 0 && results[0].Err == nil, results[0])
+	Assert(t, results[1].Err == ErrUnderflow, results[1])
+	Assert(t, results[2].Inx == 1 && results[2].Strict, results[2])
+	Assert(t, c.Seen() == 3)
+}
+
+func TestLogScoresChunked(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	var doc []string
+	for i := 0; i < 1000; i++ {
+		doc = append(doc, "rich", "poor", "ugly", "man")
+	}
+	want, wantInx, _ := c.LogScores(doc)
+	scores, inx, strict := c.LogScoresChunked(doc, 7)
+	Assert(t, inx == wantInx && strict, scores, want)
+	// the difference between the scores is preserved
+	Assert(t, math.Abs((scores[0]-scores[1])-(want[0]-want[1])) < 1e-6, scores, want)
+	Assert(t, scores[inx] > -1, scores)
+
+	scores, _, _ = c.LogScoresChunked(nil, 7)
+	Assert(t, math.Abs(scores[0]-math.Log(4.0/7)) < 1e-9, scores)
+}
+
+func TestBinarized(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithBinarized())
+	c.Learn([]string{"cheap", "cheap", "cheap", "meds"}, Bad)
+	c.Learn([]string{"lunch", "today"}, Good)
+	Assert(t, c.datas[Bad].Freqs["cheap"] == 1 && c.datas[Bad].Total == 2)
+
+	want, _, _ := c.LogScores([]string{"cheap", "lunch"})
+	scores, _, _ := c.LogScores([]string{"cheap", "cheap", "lunch", "cheap"})
+	Assert(t, scores[0] == want[0] && scores[1] == want[1], scores, want)
+	frozen, _, _ := c.Snapshot().LogScores([]string{"cheap", "cheap", "lunch"})
+	Assert(t, frozen[0] == want[0] && frozen[1] == want[1], frozen, want)
+	freqs, _, _ := c.LogScoresFreqs(map[string]int{"cheap": 3, "lunch": 1})
+	Assert(t, math.Abs(freqs[0]-want[0]) < 1e-9 && math.Abs(freqs[1]-want[1]) < 1e-9, freqs, want)
+	read, _, _, _ := c.ClassifyReader(strings.NewReader("cheap cheap lunch"), nil)
+	Assert(t, math.Abs(read[0]-want[0]) < 1e-9 && math.Abs(read[1]-want[1]) < 1e-9, read, want)
+
+	// binarization wins over a term count limit set in either order
+	for _, opts := range [][]Option{
+		{WithBinarized(), WithMaxTermCountPerDoc(3)},
+		{WithMaxTermCountPerDoc(3), WithBinarized()},
+	} {
+		d, err := NewClassifierOpts([]Class{Good, Bad}, opts...)
+		Assert(t, err == nil, err)
+		d.Learn([]string{"cheap", "cheap", "cheap", "meds"}, Bad)
+		Assert(t, d.datas[Bad].Freqs["cheap"] == 1 && d.maxTermCount == 3, d.datas[Bad].Freqs)
+	}
+}
+
+func TestFeatureWeights(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	doc := []string{"subject:rich", "poor", "poor"}
+	_, inx, _ := c.LogScores(doc)
+	Assert(t, inx == 1)
+
+	c.Learn([]string{"subject:rich"}, Good)
+	c.SetFeatureWeights(map[string]float64{"subject:rich": 3})
+	scores, inx, _ := c.LogScores(doc)
+	Assert(t, inx == 0, scores)
+	want := math.Log(c.getPriors()[0]) + 3*math.Log(c.wordProb(Good, "subject:rich")) + 2*math.Log(defaultProb)
+	Assert(t, math.Abs(scores[0]-want) < 1e-9, scores, want)
+
+	frozen, _, _ := c.Snapshot().LogScores(doc)
+	Assert(t, frozen[0] == scores[0] && frozen[1] == scores[1], frozen, scores)
+	c.SetFeatureWeights(nil)
+	_, inx, _ = c.LogScores(doc)
+	Assert(t, inx == 1)
+}
+
+func TestLengthModel(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"hello", "there", "how", "are", "you", "doing", "today", "friend"}, Good)
+	c.Learn([]string{"buy", "now"}, Bad)
+	c.Learn([]string{"cheap", "now"}, Bad)
+	doc := []string{"hello", "now"}
+	before, _, _ := c.LogScores(doc)
+
+	c.SetOptions(WithLengthModel())
+	scores, _, _ := c.LogScores(doc)
+	// mean lengths are 8 and 2
+	Assert(t, math.Abs(scores[0]-before[0]-(2*math.Log(8)-8-math.Log(2))) < 1e-9, scores, before)
+	Assert(t, math.Abs(scores[1]-before[1]-(2*math.Log(2)-2-math.Log(2))) < 1e-9, scores, before)
+	frozen, _, _ := c.Snapshot().LogScores(doc)
+	Assert(t, math.Abs(frozen[0]-scores[0]) < 1e-9 && math.Abs(frozen[1]-scores[1]) < 1e-9, frozen, scores)
+
+	Assert(t, c.Unlearn([]string{"cheap", "now"}, Bad) == nil)
+	Assert(t, c.datas[Bad].Length == 2 && c.datas[Bad].Docs == 1)
+}
+
+func TestLengthModelPaths(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithLengthModel())
+	c.Learn([]string{"hello", "there", "how", "are", "you", "doing", "today", "friend"}, Good)
+	c.Learn([]string{"buy", "now"}, Bad)
+	doc := []string{"hello", "now"}
+	want, wantInx, _ := c.LogScores(doc)
+	same := func(scores []float64, inx int) {
+		Assert(t, inx == wantInx, scores, want)
+		for i := range scores {
+			Assert(t, math.Abs(scores[i]-want[i]) < 1e-9, scores, want)
+		}
+	}
+	scores, inx, _, err := c.ClassifyReader(strings.NewReader("hello now"), nil)
+	Assert(t, err == nil, err)
+	same(scores, inx)
+	scores, inx, _ = c.LogScoresFreqs(map[string]int{"hello": 1, "now": 1})
+	same(scores, inx)
+	e := c.Explain(doc)
+	Assert(t, e.Length[0] == c.lengthLogProb(Good, 2) && e.Length[1] == c.lengthLogProb(Bad, 2), e.Length)
+
+	// the lengths follow the documents in and out of the model
+	d := NewClassifier(Good, Bad)
+	d.SetOptions(WithLengthModel(), WithSlidingWindow(1, 0))
+	d.ObserveDocument(map[string]int{"cheap": 2, "now": 2}, 2, Bad)
+	Assert(t, d.datas[Bad].Length == 4)
+	d.Learn([]string{"cheap", "now", "buy"}, Good)
+	d.Learn([]string{"hello"}, Good)
+	Assert(t, d.datas[Good].Length == 1 && d.datas[Good].Docs == 1, d.datas[Good].Length)
+	Assert(t, c.Merge(d) == nil && c.datas[Bad].Length == 6 && c.datas[Good].Length == 9)
+	Assert(t, c.Subtract(d) == nil && c.datas[Bad].Length == 2 && c.datas[Good].Length == 8)
+}
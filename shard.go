@@ -0,0 +1,189 @@
+package bayesian
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// ShardOf returns the shard of n that the word belongs to, by
+// hash of the word.
+func ShardOf(word string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(word))
+	return int(h.Sum32() % uint32(n))
+}
+
+// SplitDocument splits the document into the words of each of
+// n shards, see ShardOf. With WithNGrams, split the n-grams of
+// the document, see NGrams, rather than its words.
+func SplitDocument(document []string, n int) [][]string {
+	parts := make([][]string, n)
+	for _, word := range document {
+		i := ShardOf(word, n)
+		parts[i] = append(parts[i], word)
+	}
+	return parts
+}
+
+// Shard splits the classifier into n classifiers that each hold
+// the counts of the words of one shard, see ShardOf, so that a
+// model too large for one machine can be served by several. The
+// shards keep the class totals, the vocabulary size and the
+// configuration of c, and share its word lists, so that
+// smoothing gives the same probabilities as in c. A document is
+// scored by scoring the words of each shard with
+// PartialLogScores on that shard, and combining the results with
+// CombineLogScores on any of them; ShardedLogScores does both,
+// and also scores the length of the document with
+// WithLengthModel. The result is the same as the scores of c,
+// except for the per-namespace caps and the Expander, which only
+// see the words of each shard. Shard panics if c has an
+// Estimator, which cannot be split.
+func (c *Classifier) Shard(n int) []*Classifier {
+	if n < 1 {
+		panic("provide at least one shard")
+	}
+	if c.estimator != nil {
+		panic("Cannot shard a classifier with an Estimator.")
+	}
+	shards := make([]*Classifier, n)
+	for i := range shards {
+		w := c.serializable()
+		w.ShardVocab = c.getCorpus().vocab
+		w.Datas = make(map[Class]*classData, len(c.datas))
+		for class, data := range c.datas {
+			w.Datas[class] = data.shard(i, n)
+		}
+		if c.docFreqs != nil {
+			w.DocFreqs = make(map[string]int)
+			for word, df := range c.docFreqs {
+				if ShardOf(word, n) == i {
+					w.DocFreqs[word] = df
+				}
+			}
+		}
+		shards[i] = w.classifier()
+		shards[i].stats = new(UsageStats)
+		shards[i].expander = c.expander
+		shards[i].now = c.now
+	}
+	return shards
+}
+
+// shard returns a copy of the class data with the words of
+// shard i of n only.
+func (d *classData) shard(i, n int) *classData {
+	s := newClassData()
+	s.Total = d.Total
+	s.Docs = d.Docs
+	s.Length = d.Length
+	s.Idf = d.Idf
+	for word, freq := range d.Freqs {
+		if ShardOf(word, n) == i {
+			s.Freqs[word] = freq
+		}
+	}
+	for word, tfs := range d.FreqTfs {
+		if ShardOf(word, n) == i {
+			s.FreqTfs[word] = append([]float64(nil), tfs...)
+		}
+	}
+	for word, ts := range d.Updated {
+		if ShardOf(word, n) == i {
+			if s.Updated == nil {
+				s.Updated = make(map[string]int64)
+			}
+			s.Updated[word] = ts
+		}
+	}
+	for word, cnt := range d.Counts {
+		if ShardOf(word, n) == i {
+			if s.Counts == nil {
+				s.Counts = make(map[string]float64)
+			}
+			s.Counts[word] = cnt
+		}
+	}
+	for word, cnt := range d.Dropped {
+		if ShardOf(word, n) == i {
+			if s.Dropped == nil {
+				s.Dropped = make(map[string]float64)
+			}
+			s.Dropped[word] = cnt
+		}
+	}
+	for word, tfs := range d.Tfs {
+		if ShardOf(word, n) == i {
+			if s.Tfs == nil {
+				s.Tfs = make(map[string][]float64)
+			}
+			s.Tfs[word] = append([]float64(nil), tfs...)
+		}
+	}
+	return s
+}
+
+// PartialLogScores returns the contribution of the words of the
+// document to the log score of each class, without the prior
+// probabilities, class weights and document length. Classes
+// that are not eligible score -Inf. With WithNGrams, the
+// document is made of the n-grams of the shard, see
+// SplitDocument.
+func (c *Classifier) PartialLogScores(document []string) []float64 {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling PartialLogScores.")
+	}
+	document = c.expandWords(document)
+	scores := make([]float64, len(c.Classes))
+	for index, class := range c.Classes {
+		if !c.IsEligible(class) {
+			scores[index] = math.Inf(-1)
+			continue
+		}
+		scores[index] = c.logScore(class, 1, document)
+	}
+	return scores
+}
+
+// CombineLogScores adds up the partial scores of the shards of
+// a document, see PartialLogScores, and the prior probabilities
+// and class weights, to give the same results as LogScores,
+// except for the length of the document with WithLengthModel,
+// see ShardedLogScores.
+func (c *Classifier) CombineLogScores(partials ...[]float64) (scores []float64, inx int, strict bool) {
+	return c.combineLogScores(-1, partials)
+}
+
+// combineLogScores implements CombineLogScores, adding the log
+// probability of the document length n, unless it is negative.
+func (c *Classifier) combineLogScores(n int, partials [][]float64) (scores []float64, inx int, strict bool) {
+	priors := c.getPriors()
+	scores = make([]float64, len(c.Classes))
+	for index, class := range c.Classes {
+		scores[index] = math.Log(priors[index]) + c.logBias(class)
+		if n >= 0 {
+			scores[index] += c.lengthLogProb(class, n)
+		}
+		for _, partial := range partials {
+			scores[index] += partial[index]
+		}
+	}
+	inx, strict = c.pickMax(scores)
+	c.record(inx)
+	return scores, inx, strict
+}
+
+// ShardedLogScores scores the document with the shards returned
+// by c.Shard(len(shards)), in the same order.
+func ShardedLogScores(shards []*Classifier, document []string) (scores []float64, inx int, strict bool) {
+	parts := SplitDocument(shards[0].ngrams(document), len(shards))
+	partials := make([][]float64, len(shards))
+	n := 0
+	for i, shard := range shards {
+		partials[i] = shard.PartialLogScores(parts[i])
+		if shard.lengthModel {
+			n += len(shard.expandWords(parts[i]))
+		}
+	}
+	return shards[0].combineLogScores(n, partials)
+}
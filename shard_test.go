@@ -0,0 +1,53 @@
+package bayesian
+
+import "testing"
+import "math"
+
+func TestShard(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "nice"}, Good)
+	c.Learn([]string{"tall", "blonde"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly", "mean"}, Bad)
+	shards := c.Shard(3)
+	Assert(t, len(shards) == 3)
+	words := 0
+	for _, shard := range shards {
+		Assert(t, shard.datas[Good].Total == 6 && shard.datas[Bad].Total == 4)
+		words += len(shard.datas[Good].Freqs)
+	}
+	Assert(t, words == len(c.datas[Good].Freqs), words)
+
+	doc := []string{"tall", "poor", "girl", "nice"}
+	want, wantInx, _ := c.LogScores(doc)
+	scores, inx, _ := ShardedLogScores(shards, doc)
+	Assert(t, inx == wantInx, scores)
+	for i := range scores {
+		Assert(t, math.Abs(scores[i]-want[i]) < 1e-9, scores, want)
+	}
+}
+
+func TestShardOptions(t *testing.T) {
+	options := [][]Option{
+		{WithNGrams(1, 2)},
+		{WithSmoothing(1)},
+		{WithComplementNB()},
+		{WithJelinekMercer(0.5)},
+		{WithDirichletSmoothing(10)},
+		{WithLengthModel()},
+		{WithAdaptiveDefaultProb(), WithSkipOOV(), WithBinarized()},
+	}
+	doc := []string{"tall", "rich", "poor", "girl", "tall", "rich"}
+	for _, opts := range options {
+		c, _ := NewClassifierOpts([]Class{Good, Bad}, opts...)
+		c.Learn([]string{"tall", "handsome", "rich", "nice"}, Good)
+		c.Learn([]string{"rich", "tall", "blonde"}, Good)
+		c.Learn([]string{"bald", "poor", "ugly", "mean", "tall"}, Bad)
+		c.Learn([]string{"poor", "rich"}, Bad)
+		want, wantInx, _ := c.LogScores(doc)
+		scores, inx, _ := ShardedLogScores(c.Shard(3), doc)
+		Assert(t, inx == wantInx, scores, want)
+		for i := range scores {
+			Assert(t, math.Abs(scores[i]-want[i]) < 1e-9, scores, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package bayesian
+
+import "math"
+
+// wordProb returns P(W|C_j) for scoring, taking the allowlist
+// and blocklist of the class into account: words that are
+// blocked, or not allowed, are treated as never seen in the
+// class. It is smoothed as configured with WithSmoothing,
+// WithJelinekMercer or WithDirichletSmoothing, unless it is
+// given by the Estimator set with WithEstimator.
+func (c *Classifier) wordProb(class Class, word string) float64 {
+	if c.estimator != nil {
+		return c.estimator.WordProb(class, word)
+	}
+	return c.builtinWordProb(class, word)
+}
+
+// builtinWordProb returns P(W|C_j) as estimated by the
+// classifier itself, see wordProb.
+func (c *Classifier) builtinWordProb(class Class, word string) float64 {
+	data := c.datas[class]
+	value, ok := data.Freqs[word]
+	value *= c.scale()
+	if c.blocklists[class][word] {
+		ok = false
+	}
+	if allow, listed := c.allowlists[class]; listed && !allow[word] {
+		ok = false
+	}
+	if c.jmLambda > 0 {
+		return c.interpolatedProb(class, word, value, ok)
+	}
+	if c.dirichletMu > 0 {
+		return c.dirichletProb(class, word, value, ok)
+	}
+	if !ok {
+		return c.unseenProb(class)
+	}
+	if c.smoothing > 0 {
+		return (value + c.smoothing) / (c.classTotal(class) + c.smoothing*float64(c.getCorpus().vocab))
+	}
+	return value / c.classTotal(class)
+}
+
+// classTotal returns the total count of the class.
+func (c *Classifier) classTotal(class Class) float64 {
+	return c.datas[class].Total * c.scale()
+}
+
+// unseenProb returns the probability that a word we have not
+// seen before appears in the class: defaultProb, or if an
+// adaptive default probability is configured, the probability
+// an unseen word would get with add-one smoothing, 1/(N_j+V),
+// where N_j is the total count of the class and V is the size
+// of the vocabulary of the model. With additive smoothing, see
+// WithSmoothing, it is alpha/(N_j+alpha*V).
+func (c *Classifier) unseenProb(class Class) float64 {
+	if c.smoothing > 0 {
+		return c.smoothing / (c.classTotal(class) + c.smoothing*float64(c.getCorpus().vocab))
+	}
+	if !c.adaptiveDefaultProb {
+		return defaultProb
+	}
+	denom := c.classTotal(class) + float64(c.getCorpus().vocab)
+	if denom == 0 {
+		return defaultProb
+	}
+	return 1 / denom
+}
+
+// interpolatedProb returns the probability of the word in the
+// class mixed with its probability over all classes, as
+// configured with WithJelinekMercer, given its count in the
+// class, if seen.
+func (c *Classifier) interpolatedProb(class Class, word string, value float64, seen bool) float64 {
+	p := float64(0)
+	if total := c.classTotal(class); seen && total > 0 {
+		p = c.jmLambda * value / total
+	}
+	if corpus := c.getCorpus(); corpus.total > 0 {
+		p += (1 - c.jmLambda) * corpus.freqs[word] / corpus.total
+	}
+	if p == 0 {
+		return c.unseenProb(class)
+	}
+	return p
+}
+
+// dirichletProb returns the probability of the word in the
+// class with Dirichlet prior smoothing, as configured with
+// WithDirichletSmoothing, given its count in the class, if seen.
+func (c *Classifier) dirichletProb(class Class, word string, value float64, seen bool) float64 {
+	corpus := c.getCorpus()
+	background := float64(0)
+	if corpus.total > 0 {
+		background = corpus.freqs[word] / corpus.total
+	}
+	if !seen {
+		value = 0
+	}
+	p := (value + c.dirichletMu*background) / (c.classTotal(class) + c.dirichletMu)
+	if p == 0 {
+		return c.unseenProb(class)
+	}
+	return p
+}
+
+// wordLogProb returns the log probability of the word in the
+// class, log P(W|C_j), or with WithComplementNB, the negated log
+// probability of the word in the complement of the class.
+func (c *Classifier) wordLogProb(class Class, word string) float64 {
+	if c.complementNB {
+		corpus := c.getCorpus()
+		return -math.Log(c.complementProb(class, corpus.freqs[word]-c.datas[class].Freqs[word]*c.scale()))
+	}
+	return math.Log(c.wordProb(class, word))
+}
+
+// complementProb returns the probability of a word counted n
+// times in the classes other than class, in those classes, with
+// additive smoothing: (n+alpha)/(N+alpha*V), where N is the total
+// count of the other classes and V is the size of the
+// vocabulary. Alpha is the one set with WithSmoothing, or 1.
+func (c *Classifier) complementProb(class Class, n float64) float64 {
+	alpha := c.smoothing
+	if alpha <= 0 {
+		alpha = 1
+	}
+	corpus := c.getCorpus()
+	total := corpus.total - c.classTotal(class)
+	return (n + alpha) / (total + alpha*float64(corpus.vocab))
+}
@@ -0,0 +1,89 @@
+package bayesian
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAdaptiveDefaultProb(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithAdaptiveDefaultProb())
+	Assert(t, c.wordProb(Good, "tall") == defaultProb, "empty model")
+
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly", "tall"}, Bad)
+	Assert(t, c.wordProb(Good, "man") == float64(1)/(3+6), c.wordProb(Good, "man"))
+	Assert(t, c.wordProb(Bad, "man") == float64(1)/(4+6))
+
+	// the vocabulary is recomputed after learning
+	c.Learn([]string{"man"}, Bad)
+	Assert(t, c.wordProb(Good, "man") == float64(1)/(3+7))
+}
+
+func TestSmoothing(t *testing.T) {
+	c, _ := NewClassifierOpts([]Class{Good, Bad}, WithSmoothing(1))
+	c.Learn([]string{"tall", "handsome", "rich", "tall"}, Good)
+	c.Learn([]string{"bald", "poor"}, Bad)
+	// V = 5
+	Assert(t, c.wordProb(Good, "tall") == float64(2+1)/(4+5), c.wordProb(Good, "tall"))
+	Assert(t, c.wordProb(Good, "man") == float64(1)/(4+5))
+	Assert(t, c.wordProb(Bad, "tall") == float64(1)/(2+5))
+
+	c.SetClassBlocklist(Good, "tall")
+	Assert(t, c.wordProb(Good, "tall") == float64(1)/(4+5))
+
+	c.SetOptions(WithSmoothing(0.01))
+	Assert(t, c.wordProb(Good, "man") == 0.01/(4+0.05), c.wordProb(Good, "man"))
+}
+
+func TestComplementNB(t *testing.T) {
+	c := NewClassifier("normal", "fraud")
+	for i := 0; i < 20; i++ {
+		c.Learn([]string{"invoice", "meeting", "report", "urgent", "wire"}, "normal")
+	}
+	c.Learn([]string{"wire", "urgent", "now"}, "fraud")
+	doc := []string{"wire", "urgent", "now"}
+
+	c.SetOptions(WithComplementNB())
+	// 20 counts of wire in the complement of fraud, of 100, V = 6
+	Assert(t, math.Abs(c.wordLogProb("fraud", "wire")+math.Log(float64(20+1)/(100+6))) < 1e-12)
+	c.SetOptions(WithUniformPriors())
+	scores, inx, _ := c.LogScores(doc)
+	Assert(t, inx == 1, scores)
+	frozen, _, _ := c.Snapshot().LogScores(append(doc, "unseen"))
+	scores, _, _ = c.LogScores(append(doc, "unseen"))
+	Assert(t, math.Abs(frozen[0]-scores[0]) < 1e-9 && math.Abs(frozen[1]-scores[1]) < 1e-9, frozen, scores)
+}
+
+func TestJelinekMercer(t *testing.T) {
+	c, _ := NewClassifierOpts([]Class{Good, Bad}, WithJelinekMercer(0.75))
+	c.Learn([]string{"tall", "handsome", "rich", "tall"}, Good)
+	c.Learn([]string{"bald", "poor", "tall", "rich"}, Bad)
+	// 3 of 8 words overall are tall
+	Assert(t, c.wordProb(Good, "tall") == 0.75*2/4+0.25*3/8, c.wordProb(Good, "tall"))
+	Assert(t, c.wordProb(Good, "poor") == 0.25*1/8, c.wordProb(Good, "poor"))
+	Assert(t, c.wordProb(Good, "man") == defaultProb)
+
+	c.SetClassBlocklist(Good, "tall")
+	Assert(t, c.wordProb(Good, "tall") == 0.25*3/8)
+
+	doc := []string{"poor", "tall", "man"}
+	frozen, _, _ := c.Snapshot().LogScores(doc)
+	scores, _, _ := c.LogScores(doc)
+	Assert(t, frozen[0] == scores[0] && frozen[1] == scores[1], frozen, scores)
+}
+
+func TestDirichletSmoothing(t *testing.T) {
+	c, _ := NewClassifierOpts([]Class{Good, Bad}, WithDirichletSmoothing(2))
+	c.Learn([]string{"tall", "handsome", "rich", "tall"}, Good)
+	c.Learn([]string{"bald", "poor", "tall", "rich"}, Bad)
+	// 3 of 8 words overall are tall
+	Assert(t, c.wordProb(Good, "tall") == (2+2*3.0/8)/(4+2), c.wordProb(Good, "tall"))
+	Assert(t, c.wordProb(Good, "poor") == (2*1.0/8)/(4+2), c.wordProb(Good, "poor"))
+	Assert(t, c.wordProb(Good, "man") == defaultProb)
+
+	doc := []string{"poor", "tall", "man"}
+	frozen, _, _ := c.Snapshot().LogScores(doc)
+	scores, _, _ := c.LogScores(doc)
+	Assert(t, frozen[0] == scores[0] && frozen[1] == scores[1], frozen, scores)
+}
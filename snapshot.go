@@ -0,0 +1,59 @@
+package bayesian
+
+import "sort"
+
+// ModelSnapshot is an immutable copy of the counts of a
+// classifier, taken with c.ModelSnapshot(), that exporters and
+// monitors can read at length while the classifier goes on
+// learning.
+type ModelSnapshot struct {
+	Classes []Class
+	Priors  []float64 // P(C_j), indexed like Classes
+	Totals  []float64 // total word count of each class
+	Docs    []int     // learned documents of each class
+	terms   []map[string]float64
+}
+
+// ModelSnapshot returns a snapshot of the classes, priors and
+// word counts of the classifier.
+func (c *Classifier) ModelSnapshot() ModelSnapshot {
+	n := len(c.Classes)
+	s := ModelSnapshot{
+		Classes: append([]Class(nil), c.Classes...),
+		Priors:  c.getPriors(),
+		Totals:  make([]float64, n),
+		Docs:    make([]int, n),
+		terms:   make([]map[string]float64, n),
+	}
+	for index, class := range c.Classes {
+		data := c.datas[class]
+		s.Totals[index] = data.Total * c.scale()
+		s.Docs[index] = data.Docs
+		s.terms[index] = c.ClassVocabularyCounts(class)
+	}
+	return s
+}
+
+// Terms calls fn with each word of the class and its count, in
+// alphabetical order, until fn returns false. It returns
+// ErrClassNotFound if the snapshot does not have the class.
+func (s ModelSnapshot) Terms(class Class, fn func(word string, count float64) bool) error {
+	for index, cl := range s.Classes {
+		if cl != class {
+			continue
+		}
+		terms := s.terms[index]
+		words := make([]string, 0, len(terms))
+		for word := range terms {
+			words = append(words, word)
+		}
+		sort.Strings(words)
+		for _, word := range words {
+			if !fn(word, terms[word]) {
+				break
+			}
+		}
+		return nil
+	}
+	return ErrClassNotFound
+}
@@ -0,0 +1,22 @@
+package bayesian
+
+import "testing"
+
+func TestModelSnapshot(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "tall"}, Good)
+	c.Learn([]string{"bald", "poor"}, Bad)
+	s := c.ModelSnapshot()
+	c.Learn([]string{"tall", "blonde"}, Good)
+
+	Assert(t, s.Totals[0] == 4 && s.Docs[0] == 1, s.Totals, s.Docs)
+	Assert(t, s.Priors[0] == 4.0/6, s.Priors)
+	var words []string
+	err := s.Terms(Good, func(word string, count float64) bool {
+		words = append(words, word)
+		return word != "rich"
+	})
+	Assert(t, err == nil)
+	Assert(t, len(words) == 2 && words[0] == "handsome" && words[1] == "rich", words)
+	Assert(t, s.Terms("Ugly", func(string, float64) bool { return true }) == ErrClassNotFound)
+}
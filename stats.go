@@ -0,0 +1,156 @@
+package bayesian
+
+import (
+	"encoding/gob"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats returns the usage statistics of the classifier.
+func (c *Classifier) Stats() *UsageStats {
+	return c.stats
+}
+
+// SetStats replaces the usage statistics of the classifier,
+// for instance to carry them over to a retrained classifier
+// or to restore them with NewUsageStatsFromFile(string).
+func (c *Classifier) SetStats(stats *UsageStats) {
+	c.stats = stats
+}
+
+// UsageStats holds the operational counters of a classifier:
+// the number of documents classified, the number of predictions
+// per class and the feedback received on predictions. They are
+// kept and serialized separately from the model, so that a
+// retrained model does not reset them and updating them does
+// not require saving the model.
+type UsageStats struct {
+	seen        int64    // docs seen
+	predictions sync.Map // Class to *int64, the docs predicted as the class
+	correct     int64
+	incorrect   int64
+}
+
+// serializableUsageStats is the gob representation of
+// UsageStats.
+type serializableUsageStats struct {
+	Seen        int64
+	Predictions map[Class]int
+	Correct     int
+	Incorrect   int
+}
+
+// NewUsageStatsFromFile loads usage statistics previously
+// saved with a call to s.WriteToFile(string).
+func NewUsageStatsFromFile(name string) (s *UsageStats, err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return NewUsageStatsFromReader(file)
+}
+
+// NewUsageStatsFromReader decodes GOB encoded usage statistics.
+func NewUsageStatsFromReader(r io.Reader) (s *UsageStats, err error) {
+	dec := gob.NewDecoder(r)
+	w := new(serializableUsageStats)
+	err = dec.Decode(w)
+
+	s = &UsageStats{
+		seen:      w.Seen,
+		correct:   int64(w.Correct),
+		incorrect: int64(w.Incorrect),
+	}
+	for class, cnt := range w.Predictions {
+		n := int64(cnt)
+		s.predictions.Store(class, &n)
+	}
+	return s, err
+}
+
+// WriteToFile serializes the usage statistics to a file.
+func (s *UsageStats) WriteToFile(name string) (err error) {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = s.WriteTo(file)
+	return
+}
+
+// WriteTo serializes the usage statistics to GOB and writes
+// them to the Writer.
+func (s *UsageStats) WriteTo(w io.Writer) (n int64, err error) {
+	correct, incorrect := s.Feedback()
+	cw := &countingWriter{w: w}
+	enc := gob.NewEncoder(cw)
+	err = enc.Encode(&serializableUsageStats{
+		Seen:        atomic.LoadInt64(&s.seen),
+		Predictions: s.Predictions(),
+		Correct:     correct,
+		Incorrect:   incorrect,
+	})
+	return cw.n, err
+}
+
+// Seen returns the number of documents classified.
+func (s *UsageStats) Seen() int {
+	return int(atomic.LoadInt64(&s.seen))
+}
+
+// Predictions returns the number of documents classified into
+// each class.
+func (s *UsageStats) Predictions() map[Class]int {
+	result := make(map[Class]int)
+	s.predictions.Range(func(class, cnt interface{}) bool {
+		result[class.(Class)] = int(atomic.LoadInt64(cnt.(*int64)))
+		return true
+	})
+	return result
+}
+
+// RecordFeedback records whether a prediction of the classifier
+// turned out to be correct once the actual class became known.
+func (s *UsageStats) RecordFeedback(predicted, actual Class) {
+	if predicted == actual {
+		atomic.AddInt64(&s.correct, 1)
+	} else {
+		atomic.AddInt64(&s.incorrect, 1)
+	}
+}
+
+// Feedback returns the number of correct and incorrect
+// predictions reported with RecordFeedback.
+func (s *UsageStats) Feedback() (correct, incorrect int) {
+	return int(atomic.LoadInt64(&s.correct)), int(atomic.LoadInt64(&s.incorrect))
+}
+
+// renameClass moves the predictions counted for the class old
+// to the class new.
+func (s *UsageStats) renameClass(old, new Class) {
+	cnt, ok := s.predictions.LoadAndDelete(old)
+	if !ok {
+		return
+	}
+	if have, loaded := s.predictions.LoadOrStore(new, cnt); loaded {
+		atomic.AddInt64(have.(*int64), atomic.LoadInt64(cnt.(*int64)))
+	}
+}
+
+// record counts a classified document and its prediction. It
+// only takes atomic counters, since it is called on every
+// classification.
+func (s *UsageStats) record(predicted Class) {
+	atomic.AddInt64(&s.seen, 1)
+	cnt, ok := s.predictions.Load(predicted)
+	if !ok {
+		cnt, _ = s.predictions.LoadOrStore(predicted, new(int64))
+	}
+	atomic.AddInt64(cnt.(*int64), 1)
+}
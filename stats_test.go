@@ -0,0 +1,60 @@
+package bayesian
+
+import "testing"
+import "os"
+import "bytes"
+import "sync"
+
+func TestUsageStats(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.LogScores([]string{"tall"})
+	c.LogScores([]string{"poor"})
+	c.LogScores([]string{"rich"})
+	c.Stats().RecordFeedback(Good, Bad)
+	preds := c.Stats().Predictions()
+	Assert(t, preds[Good] == 2 && preds[Bad] == 1, preds)
+
+	err := c.Stats().WriteToFile("test.stats")
+	Assert(t, err == nil, "could not write:", err)
+	s, err := NewUsageStatsFromFile("test.stats")
+	Assert(t, err == nil, "could not read:", err)
+	Assert(t, s.Seen() == 3)
+	correct, incorrect := s.Feedback()
+	Assert(t, correct == 0 && incorrect == 1)
+
+	// retrained classifiers keep the stats
+	d := NewClassifier(Good, Bad)
+	d.SetStats(s)
+	d.LogScores([]string{"tall"})
+	Assert(t, d.Seen() == 4)
+	err = os.Remove("test.stats")
+	Assert(t, err == nil, "could not remove test file:", err)
+}
+
+func TestUsageStatsConcurrent(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 100; k++ {
+				c.LogScores([]string{"tall"})
+				c.LogScores([]string{"poor"})
+				c.Stats().RecordFeedback(Good, Good)
+			}
+		}()
+	}
+	wg.Wait()
+	preds := c.Stats().Predictions()
+	correct, _ := c.Stats().Feedback()
+	Assert(t, c.Seen() == 1600 && preds[Good] == 800 && preds[Bad] == 800 && correct == 800, preds)
+
+	var buf bytes.Buffer
+	n, err := c.Stats().WriteTo(&buf)
+	Assert(t, err == nil && n == int64(buf.Len()), err, n)
+}
@@ -0,0 +1,37 @@
+package bayesian
+
+import "context"
+
+// LabeledDoc is a training document with its class.
+type LabeledDoc struct {
+	Document []string
+	Class    Class
+}
+
+// LearnStream learns the documents received from docs until
+// the channel is closed, in which case it returns nil, or the
+// context is done, in which case it returns the error of the
+// context. The statistics cached from the counts are refreshed
+// once per burst of documents rather than for each of them. It
+// returns the error of c.LearnErr, without learning the
+// document, if a document cannot be learned.
+func (c *Classifier) LearnStream(ctx context.Context, docs <-chan LabeledDoc) error {
+	defer c.invalidate()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case doc, ok := <-docs:
+			if !ok {
+				return nil
+			}
+			if err := c.checkLearnable(doc.Class); err != nil {
+				return err
+			}
+			c.learn(doc.Document, doc.Class)
+			if len(docs) == 0 {
+				c.invalidate()
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package bayesian
+
+import "testing"
+import "context"
+
+func TestLearnStream(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	docs := make(chan LabeledDoc, 3)
+	docs <- LabeledDoc{[]string{"tall", "rich"}, Good}
+	docs <- LabeledDoc{[]string{"poor"}, Bad}
+	close(docs)
+	Assert(t, c.LearnStream(context.Background(), docs) == nil)
+	Assert(t, c.Learned() == 2 && c.datas[Good].Total == 2)
+	Assert(t, c.getCorpus().freqs["poor"] == 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	Assert(t, c.LearnStream(ctx, make(chan LabeledDoc)) == context.Canceled)
+
+	docs = make(chan LabeledDoc, 1)
+	docs <- LabeledDoc{[]string{"ugly"}, "Ugly"}
+	Assert(t, c.LearnStream(context.Background(), docs) == ErrClassNotFound)
+
+	d := NewClassifierTfIdf(Good, Bad)
+	d.Learn([]string{"tall"}, Good)
+	d.ConvertTermsFreqToTfIdf()
+	docs = make(chan LabeledDoc, 1)
+	docs <- LabeledDoc{[]string{"ugly"}, Bad}
+	Assert(t, d.LearnStream(context.Background(), docs) == ErrConverted)
+}
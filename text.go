@@ -0,0 +1,215 @@
+package bayesian
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Tokenizer splits text into words. It works like a
+// bufio.SplitFunc, and any bufio.SplitFunc can be converted
+// to a Tokenizer.
+type Tokenizer bufio.SplitFunc
+
+// WhitespaceTokenizer splits text into the words separated
+// by white space.
+var WhitespaceTokenizer = Tokenizer(bufio.ScanWords)
+
+// LearnReader learns the text read from r as a document of the
+// class, split into words by the Tokenizer, or by
+// WhitespaceTokenizer if tok is nil. Only the counts of the
+// distinct words of the document are held in memory, so large
+// files and request bodies can be learned, and remembered with
+// WithSlidingWindow. It returns the errors of LearnErr, and the
+// error of r, if any, in which case nothing is learned.
+func (c *Classifier) LearnReader(r io.Reader, class Class, tok Tokenizer) error {
+	if err := c.checkLearnable(class); err != nil {
+		return err
+	}
+	if tok == nil {
+		tok = WhitespaceTokenizer
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.SplitFunc(tok))
+	counts := make(map[string]int)
+	var document []string
+	for scanner.Scan() {
+		if c.ngramMax > 0 {
+			document = append(document, scanner.Text())
+		} else {
+			counts[scanner.Text()]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	for _, word := range c.ngrams(document) {
+		counts[word]++
+	}
+	c.learnCounts(counts, class, 1)
+	c.invalidate()
+	return nil
+}
+
+// NGrams returns the n-grams of the document for each n from min
+// to max, the words of each joined by a space, for instance the
+// unigrams and bigrams "not", "good" and "not good" of
+// []string{"not", "good"} with min 1 and max 2.
+func NGrams(document []string, min, max int) []string {
+	var grams []string
+	for n := min; n <= max; n++ {
+		for i := 0; i+n <= len(document); i++ {
+			if n == 1 {
+				grams = append(grams, document[i])
+			} else {
+				grams = append(grams, strings.Join(document[i:i+n], " "))
+			}
+		}
+	}
+	return grams
+}
+
+// ngrams returns the n-grams of the document configured with
+// WithNGrams, or the document itself.
+func (c *Classifier) ngrams(document []string) []string {
+	if c.ngramMax == 0 {
+		return document
+	}
+	return NGrams(document, c.ngramMin, c.ngramMax)
+}
+
+// Preprocessor transforms the text of a document before it is
+// tokenized, for instance to strip boilerplate.
+type Preprocessor func(text string) string
+
+// Chain returns a Preprocessor that applies the given ones in
+// order.
+func Chain(steps ...Preprocessor) Preprocessor {
+	return func(text string) string {
+		for _, step := range steps {
+			text = step(text)
+		}
+		return text
+	}
+}
+
+var (
+	htmlBlocks = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)\s*>`)
+	htmlTags   = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>`)
+	replyLine  = regexp.MustCompile(`^(On .+ wrote:|-+ ?Original Message ?-+)\s*$`)
+)
+
+// StripHTML is a Preprocessor that removes the HTML tags, and
+// the contents of scripts, style sheets and heads, from the
+// text, and unescapes HTML entities.
+func StripHTML(text string) string {
+	text = htmlBlocks.ReplaceAllString(text, " ")
+	text = htmlTags.ReplaceAllString(text, " ")
+	return html.UnescapeString(text)
+}
+
+// StripQuotedReplies is a Preprocessor that removes the quoted
+// lines of an email reply, the ones starting with ">", and
+// everything from a reply header such as "On ... wrote:" or
+// "-----Original Message-----" on.
+func StripQuotedReplies(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if replyLine.MatchString(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// StripSignature is a Preprocessor that removes the signature
+// block of an email, everything from the "-- " delimiter line on.
+func StripSignature(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimRight(line, " \r") == "--" {
+			return strings.Join(lines[:i], "\n")
+		}
+	}
+	return text
+}
+
+// ClassifyReader works the same as LogScores, but scores the
+// text read from r, split into words by the Tokenizer, or by
+// WhitespaceTokenizer if tok is nil. The words are folded into
+// the scores as they are read, so large documents are not held
+// in memory, except with WithMaxWordContribution,
+// WithNamespaceCaps or WithNGrams, which need the whole
+// document. Documents read this way are not checked by the
+// MismatchMonitor. It returns the error of r, if any.
+func (c *Classifier) ClassifyReader(r io.Reader, tok Tokenizer) (scores []float64, inx int, strict bool, err error) {
+	if c.tfIdf && !c.DidConvertTfIdf {
+		panic("Using a TF-IDF classifier. Please call ConvertTermsFreqToTfIdf before calling ClassifyReader.")
+	}
+	if tok == nil {
+		tok = WhitespaceTokenizer
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.SplitFunc(tok))
+
+	n := len(c.Classes)
+	scores = make([]float64, n, n)
+	if c.maxWordContribution > 0 || len(c.namespaceCaps) > 0 || c.ngramMax > 0 {
+		var document []string
+		for scanner.Scan() {
+			document = append(document, scanner.Text())
+		}
+		if err = scanner.Err(); err != nil {
+			return nil, 0, false, err
+		}
+		scores, inx, strict = c.LogScores(document)
+		return scores, inx, strict, nil
+	}
+
+	for index, prior := range c.getPriors() {
+		scores[index] = math.Log(prior)
+	}
+	var seen map[string]bool
+	if c.binarized {
+		seen = make(map[string]bool)
+	}
+	word := make([]string, 1)
+	length := 0
+	for scanner.Scan() {
+		word[0] = scanner.Text()
+		for _, w := range c.expand(word) {
+			if seen != nil {
+				if seen[w] {
+					continue
+				}
+				seen[w] = true
+			}
+			length++
+			for index, class := range c.Classes {
+				scores[index] += c.wordWeight(w) * c.wordLogProb(class, w)
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, 0, false, err
+	}
+	for index, class := range c.Classes {
+		if !c.IsEligible(class) {
+			scores[index] = math.Inf(-1)
+		} else {
+			scores[index] += c.lengthLogProb(class, length) + c.logBias(class)
+		}
+	}
+	inx, strict = c.pickMax(scores)
+	c.record(inx)
+	return scores, inx, strict, nil
+}
@@ -0,0 +1,104 @@
+package bayesian
+
+import "testing"
+import "bufio"
+import "errors"
+import "io"
+import "strings"
+
+func TestLearnReader(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	err := c.LearnReader(strings.NewReader("tall handsome\n rich\ttall"), Good, nil)
+	Assert(t, err == nil, err)
+	Assert(t, c.datas[Good].Freqs["tall"] == 2 && c.datas[Good].Total == 4)
+	Assert(t, c.Learned() == 1 && c.datas[Good].Docs == 1)
+
+	err = c.LearnReader(strings.NewReader("bald"), "Ugly", nil)
+	Assert(t, err == ErrClassNotFound)
+
+	err = c.LearnReader(strings.NewReader("p o o r"), Bad, Tokenizer(bufio.ScanRunes))
+	Assert(t, err == nil && c.datas[Bad].Freqs["o"] == 2 && c.datas[Bad].Freqs[" "] == 3)
+
+	broken := io.MultiReader(strings.NewReader("ugly"), errReader{})
+	err = c.LearnReader(broken, Bad, nil)
+	Assert(t, err != nil && c.Learned() == 2, err)
+
+	c.SetOptions(WithSlidingWindow(1, 0))
+	Assert(t, c.LearnReader(strings.NewReader("ugly"), Bad, nil) == nil)
+	Assert(t, c.LearnReader(strings.NewReader("bald"), Bad, nil) == nil)
+	Assert(t, c.datas[Bad].Freqs["ugly"] == 0 && c.datas[Bad].Freqs["bald"] == 1, "not expired")
+
+	tf := NewClassifierTfIdf(Good, Bad)
+	tf.Learn([]string{"tall"}, Good)
+	tf.ConvertTermsFreqToTfIdf()
+	err = tf.LearnReader(strings.NewReader("rich"), Good, nil)
+	Assert(t, err == ErrConverted, err)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("broken")
+}
+
+func TestPreprocessors(t *testing.T) {
+	email := "<html><head><title>x</title></head><body><p>Cheap&nbsp;pills &amp; more</p>" +
+		"<script>track()</script></body></html>\n" +
+		"> you asked for it\n" +
+		"thanks\n" +
+		"-- \n" +
+		"Bob, CEO\n"
+	text := StripHTML(email)
+	Assert(t, !strings.Contains(text, "<") && !strings.Contains(text, "track"), text)
+	Assert(t, strings.Contains(text, "Cheap\u00a0pills & more"), text)
+
+	text = Chain(StripHTML, StripQuotedReplies, StripSignature)(email)
+	words := strings.Fields(text)
+	Assert(t, strings.Join(words, " ") == "Cheap pills & more thanks", words)
+
+	reply := "sounds good\nOn Mon, Jan 1, 2024 at 10:00 AM Alice <a@b.c> wrote:\nold text\n"
+	Assert(t, StripQuotedReplies(reply) == "sounds good", StripQuotedReplies(reply))
+}
+
+func TestClassifyReader(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.SetClassWeight(Bad, 0.5)
+	doc := "tall rich\npoor girl"
+	want, wantInx, _ := c.LogScores(strings.Fields(doc))
+	scores, inx, strict, err := c.ClassifyReader(strings.NewReader(doc), nil)
+	Assert(t, err == nil && inx == wantInx && strict, err)
+	Assert(t, scores[0] == want[0] && scores[1] == want[1], scores, want)
+
+	c.SetOptions(WithMaxWordContribution(10))
+	want, _, _ = c.LogScores(strings.Fields(doc))
+	scores, _, _, _ = c.ClassifyReader(strings.NewReader(doc), nil)
+	Assert(t, scores[0] == want[0] && scores[1] == want[1], scores, want)
+
+	_, _, _, err = c.ClassifyReader(io.MultiReader(strings.NewReader(doc), errReader{}), nil)
+	Assert(t, err != nil)
+}
+
+func TestNGrams(t *testing.T) {
+	grams := NGrams([]string{"not", "good", "at", "all"}, 1, 2)
+	Assert(t, len(grams) == 7 && grams[4] == "not good" && grams[6] == "at all", grams)
+	Assert(t, len(NGrams([]string{"good"}, 2, 3)) == 0)
+
+	c, _ := NewClassifierOpts([]Class{Good, Bad}, WithNGrams(1, 2))
+	c.Learn([]string{"very", "good"}, Good)
+	c.Learn([]string{"not", "good"}, Bad)
+	Assert(t, c.datas[Bad].Freqs["not good"] == 1 && c.datas[Bad].Total == 3)
+	Assert(t, c.LearnReader(strings.NewReader("not good"), Bad, nil) == nil)
+	Assert(t, c.datas[Bad].Freqs["not good"] == 2)
+	Assert(t, c.Unlearn([]string{"not", "good"}, Bad) == nil)
+	Assert(t, c.datas[Bad].Freqs["not good"] == 1)
+
+	doc := []string{"not", "good"}
+	scores, inx, _ := c.LogScores(doc)
+	Assert(t, inx == 1, scores)
+	read, _, _, _ := c.ClassifyReader(strings.NewReader("not good"), nil)
+	Assert(t, read[0] == scores[0] && read[1] == scores[1], read, scores)
+	frozen, _, _ := c.Snapshot().LogScores(doc)
+	Assert(t, frozen[0] == scores[0] && frozen[1] == scores[1], frozen, scores)
+}
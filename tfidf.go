@@ -0,0 +1,255 @@
+package bayesian
+
+import "math"
+
+// ConvertTermsFreqToTfIdf uses all the TF samples for the class and converts
+// them to TF-IDF https://en.wikipedia.org/wiki/Tf%E2%80%93idf
+// once we have finished learning all the classes and have the totals.
+func (c *Classifier) ConvertTermsFreqToTfIdf() {
+	c.applyDecay()
+	if c.DidConvertTfIdf {
+		panic("Cannot call ConvertTermsFreqToTfIdf more than once. Reset and relearn to reconvert.")
+	}
+	for className := range c.datas {
+		data := c.datas[className]
+		data.Tfs = copySamples(data.FreqTfs)
+		data.Counts = copyFreqs(data.Freqs)
+	}
+	c.dropByDocFreq()
+	c.weighTfIdf()
+
+	// sanity check
+	c.DidConvertTfIdf = true
+	c.invalidate()
+
+}
+
+// RefreshTfIdf recomputes the TF-IDF weights of a converted
+// TF-IDF classifier from the raw TF samples, so that documents
+// learned after ConvertTermsFreqToTfIdf with
+// WithIncrementalTfIdf are taken into account. The document
+// frequency bounds of WithMinDocFreq and WithMaxDocFreq are
+// only applied by the conversion.
+func (c *Classifier) RefreshTfIdf() {
+	c.applyDecay()
+	if !c.tfIdf || !c.DidConvertTfIdf {
+		panic("Please call ConvertTermsFreqToTfIdf before calling RefreshTfIdf.")
+	}
+	c.weighTfIdf()
+	c.invalidate()
+}
+
+// RevertTfIdfConversion undoes ConvertTermsFreqToTfIdf, restoring
+// the raw counts and TF samples, so that more documents can be
+// learned before converting again. It panics if the classifier
+// was not converted, or was converted by a version of this
+// package that did not keep the raw counts.
+func (c *Classifier) RevertTfIdfConversion() {
+	c.applyDecay()
+	if !c.tfIdf || !c.DidConvertTfIdf {
+		panic("Cannot revert a TF-IDF classifier that was not converted.")
+	}
+	for _, data := range c.datas {
+		if data.Counts == nil && len(data.Freqs) > 0 {
+			panic("Cannot revert a TF-IDF classifier without raw counts. Reset and relearn.")
+		}
+	}
+	for _, data := range c.datas {
+		for _, cnt := range data.Dropped {
+			data.Total += cnt
+		}
+		data.Dropped = nil
+		data.Freqs = data.Counts
+		if data.Freqs == nil {
+			data.Freqs = make(map[string]float64)
+		}
+		data.FreqTfs = data.Tfs
+		if data.FreqTfs == nil {
+			data.FreqTfs = make(map[string][]float64)
+		}
+		data.Counts = nil
+		data.Tfs = nil
+		data.Idf = 0
+	}
+	c.DidConvertTfIdf = false
+	c.invalidate()
+}
+
+// TfWeighting is a formula for the TF part of the TF-IDF
+// weights, see WithTfIdfWeighting.
+type TfWeighting int
+
+const (
+	// TfSublinear weighs a term frequency tf as log(1+tf).
+	TfSublinear TfWeighting = iota
+	// TfRaw weighs a term frequency as itself.
+	TfRaw
+)
+
+// IdfWeighting is a formula for the IDF part of the TF-IDF
+// weights, see WithTfIdfWeighting. N is the number of learned
+// documents, and df the number of them containing the term.
+type IdfWeighting int
+
+const (
+	// IdfClass weighs all the terms of a class by
+	// log(1+N/total), where total is the total count of the
+	// class, as this package always did.
+	IdfClass IdfWeighting = iota
+	// IdfStandard weighs a term by log(N/df)+1, rather than
+	// log(N/df), so that the terms of all documents keep a
+	// positive weight, like common TF-IDF implementations
+	// without smoothing.
+	IdfStandard
+	// IdfSmooth weighs a term by log((1+N)/(1+df))+1, like
+	// common TF-IDF implementations do by default.
+	IdfSmooth
+)
+
+// tfWeight returns the weight of a term frequency.
+func (c *Classifier) tfWeight(tf float64) float64 {
+	if c.tfIdfTf == TfRaw {
+		return tf
+	}
+	return math.Log1p(tf)
+}
+
+// idfWeight returns the IDF weight of a term in the class, given
+// its document frequency over all classes.
+func (c *Classifier) idfWeight(data *classData, df int) float64 {
+	n := float64(c.learned)
+	switch c.tfIdfIdf {
+	case IdfStandard:
+		return math.Log(n/float64(df)) + 1
+	case IdfSmooth:
+		return math.Log((1+n)/(1+float64(df))) + 1
+	}
+	return data.Idf
+}
+
+// tfIdfDocFreqs returns the document frequency of each term, the
+// number of its raw TF samples over all classes.
+func (c *Classifier) tfIdfDocFreqs() map[string]int {
+	docFreqs := make(map[string]int)
+	for _, data := range c.datas {
+		for word, tfs := range data.Tfs {
+			docFreqs[word] += len(tfs)
+		}
+	}
+	return docFreqs
+}
+
+// weighTfIdf sets the TF-IDF weights of each class from the raw
+// TF samples kept in Tfs, with the formulas set with
+// WithTfIdfWeighting.
+func (c *Classifier) weighTfIdf() {
+	var docFreqs map[string]int
+	if c.tfIdfIdf != IdfClass {
+		docFreqs = c.tfIdfDocFreqs()
+	}
+	for className := range c.datas {
+		data := c.datas[className]
+		data.Idf = math.Log1p(float64(c.learned) / data.Total)
+
+		for wIndex, tfs := range data.Tfs {
+			if _, ok := data.Dropped[wIndex]; ok {
+				continue
+			}
+			tfIdfAdder := float64(0)
+			weights := make([]float64, len(tfs))
+			idf := c.idfWeight(data, docFreqs[wIndex])
+
+			for tfSampleIndex, tf := range tfs {
+
+				// we always want a possitive TF-IDF score.
+				weights[tfSampleIndex] = c.tfWeight(tf) * idf
+				tfIdfAdder += weights[tfSampleIndex]
+			}
+			data.FreqTfs[wIndex] = weights
+			// convert the 'counts' to TF-IDF's
+			data.Freqs[wIndex] = tfIdfAdder
+		}
+	}
+}
+
+// dropByDocFreq removes the terms whose document frequency is
+// out of the bounds set with WithMinDocFreq and WithMaxDocFreq
+// from the counts, as if they had never been learned. Their raw
+// counts and TF samples are kept, so that the conversion can be
+// reverted. The document frequency of a term is the number of
+// its TF samples over all classes.
+func (c *Classifier) dropByDocFreq() {
+	if c.minDocFreq <= 0 && c.maxDocFreq <= 0 {
+		return
+	}
+	docFreqs := make(map[string]int)
+	for _, data := range c.datas {
+		for word, tfs := range data.FreqTfs {
+			docFreqs[word] += len(tfs)
+		}
+	}
+	for word, df := range docFreqs {
+		if df >= c.minDocFreq &&
+			(c.maxDocFreq <= 0 || float64(df) <= c.maxDocFreq*float64(c.learned)) {
+			continue
+		}
+		for _, data := range c.datas {
+			cnt, ok := data.Freqs[word]
+			if !ok {
+				continue
+			}
+			if data.Dropped == nil {
+				data.Dropped = make(map[string]float64)
+			}
+			data.Dropped[word] = cnt
+			data.Total -= cnt
+			delete(data.Freqs, word)
+			delete(data.FreqTfs, word)
+			delete(data.Updated, word)
+		}
+	}
+}
+
+// TermFrequencies returns the raw TF samples of the word in the
+// class of a TF-IDF classifier: the term frequency of the word
+// in each learned document of the class that contains it. The
+// samples remain available after ConvertTermsFreqToTfIdf.
+func (c *Classifier) TermFrequencies(class Class, word string) []float64 {
+	data := c.datas[class]
+	tfs := data.FreqTfs[word]
+	if _, dropped := data.Dropped[word]; dropped {
+		return nil
+	}
+	if c.DidConvertTfIdf {
+		tfs = data.Tfs[word]
+	}
+	return append([]float64(nil), tfs...)
+}
+
+// IDF returns the inverse document frequency of the word over
+// the documents learned by a converted TF-IDF classifier, so
+// that the table can be reused for search or ranking. It is
+// computed from the document frequency of the word as with
+// IdfStandard if that is the configured weighting, and as with
+// IdfSmooth otherwise, since IdfClass does not depend on the
+// word. It returns 0 before the conversion, and for words that
+// were not learned or were dropped by the conversion.
+func (c *Classifier) IDF(word string) float64 {
+	if !c.tfIdf || !c.DidConvertTfIdf {
+		return 0
+	}
+	df := 0
+	for _, data := range c.datas {
+		if _, dropped := data.Dropped[word]; !dropped {
+			df += len(data.Tfs[word])
+		}
+	}
+	if df == 0 {
+		return 0
+	}
+	n := float64(c.learned)
+	if c.tfIdfIdf == IdfStandard {
+		return math.Log(n/float64(df)) + 1
+	}
+	return math.Log((1+n)/(1+float64(df))) + 1
+}
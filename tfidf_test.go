@@ -0,0 +1,230 @@
+package bayesian
+
+import "testing"
+import "fmt"
+import "math"
+
+func TestTfIdClassifier_SanityChecks(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	Assert(t, c.IsTfIdf() == true)
+
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+
+	defer func() {
+		if err := recover(); err != nil {
+			// we are good
+		}
+	}()
+	c.LogScores([]string{"a", "b", "c"})
+	Assert(t, false, "Should have panicked:Need to run ConvertTermsFreqToTfIdf() first..", c)
+
+}
+
+func TestTfIdClassifier_Tf_Checks(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	Assert(t, c.IsTfIdf() == true)
+
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"tall", "blonde"}, Good)
+	c.Learn([]string{"tall"}, Good)
+
+	data := c.datas[Good]
+	// Total words seen in training.
+	Assert(t, data.Total == 6)
+
+	// Plain old counts for words.
+	Assert(t, data.Freqs["tall"] == 3)
+	Assert(t, data.Freqs["blonde"] == 1)
+
+	// Check for term frequency's per 'document' (tall)
+	Assert(t, data.FreqTfs["tall"][0] == float64(0.3333333333333333))
+	Assert(t, data.FreqTfs["tall"][1] == float64(0.5))
+	Assert(t, data.FreqTfs["tall"][2] == float64(1))
+
+	// Check for term frequency's per 'document' (blonde)
+	Assert(t, data.FreqTfs["blonde"][0] == float64(0.5))
+
+}
+
+func TestTfIdClassifier_ConvertToTfIdf(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	Assert(t, c.IsTfIdf() == true)
+
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"tall", "blonde"}, Good)
+	c.Learn([]string{"tall"}, Good)
+
+	// Now we convert the TF's to Tf/Idf
+	// We can only this after we have learned all the documents and classes.
+	// We can add more learning afterwards but need to call ConvertToTfIdf() again before
+	// we can predict classes.
+	c.ConvertTermsFreqToTfIdf()
+
+	data := c.datas[Good]
+
+	// Tf-Idf after we have converted the tf's
+	Assert(t, data.Freqs["tall"] == float64(0.5620939930012151))
+	Assert(t, data.Freqs["blonde"] == float64(0.16440195389316542))
+	Assert(t, data.Freqs["notseen"] == float64(0))
+	Assert(t, data.FreqTfs["tall"][0] == float64(0.11664504260744213))
+	Assert(t, data.FreqTfs["tall"][1] == float64(0.16440195389316542))
+	Assert(t, data.FreqTfs["tall"][2] == float64(0.28104699650060755))
+
+}
+
+func TestTfIdClassifier_CheckForDoubleConvert(t *testing.T) {
+
+	c := NewClassifierTfIdf(Good, Bad)
+	Assert(t, c.IsTfIdf() == true)
+
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"tall", "blonde"}, Good)
+	c.Learn([]string{"tall"}, Good)
+
+	// We can only call ConverToTdfIdf once per learning cycle (cumulative counts).
+	c.ConvertTermsFreqToTfIdf()
+
+	defer func() {
+		if err := recover(); err != nil {
+			// we are good
+		}
+	}()
+	c.ConvertTermsFreqToTfIdf()
+	Assert(t, false, "Should have panicked:Can only run ConvertTermsFreqToTfIdf() once after a learning cycle.", c)
+
+}
+
+func TestTfIdClassifier_LogScore(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	Assert(t, c.IsTfIdf() == true)
+
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"tall", "blonde"}, Good)
+	c.Learn([]string{"tall"}, Good)
+	c.Learn([]string{"fat"}, Bad)
+	c.Learn([]string{"short", "poor"}, Bad)
+
+	c.ConvertTermsFreqToTfIdf()
+
+	score, likely, strict := c.LogScores([]string{"the", "tall", "man"})
+
+	Assert(t, score[0] == float64(-53.028113582945196))
+	Assert(t, score[0] > score[1], "Class 'Good' should be closer to 0 than Class 'Bad' - both will be negative") // this is good
+	Assert(t, likely == 0, "Class should be 'Good'")
+	Assert(t, strict == true, "No tie's")
+	fmt.Printf("%#v", score)
+
+}
+
+func TestTfIdClassifier_SubScores(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"tall", "blonde"}, Good)
+	c.Learn([]string{"tall"}, Good)
+	c.Learn([]string{"rich"}, Bad)
+	Assert(t, c.IDF("tall") == 0, "no IDF before conversion")
+	tfs := c.TermFrequencies(Good, "tall")
+	Assert(t, len(tfs) == 3 && tfs[1] == 0.5, tfs)
+
+	c.ConvertTermsFreqToTfIdf()
+	// tall is in 3 of 4 documents, rich in 2
+	Assert(t, c.IDF("tall") == math.Log(5.0/4)+1, c.IDF("tall"))
+	Assert(t, c.IDF("rich") == math.Log(5.0/3)+1, c.IDF("rich"))
+	Assert(t, c.IDF("notseen") == 0)
+	tfs = c.TermFrequencies(Good, "tall")
+	Assert(t, len(tfs) == 3 && tfs[1] == 0.5, tfs)
+	idf := math.Log1p(float64(4) / 6)
+	Assert(t, c.datas[Good].FreqTfs["tall"][1] == math.Log1p(0.5)*idf)
+}
+
+func TestTfIdfDocFreqBounds(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	c.SetOptions(WithMinDocFreq(2), WithMaxDocFreq(0.75))
+	c.Learn([]string{"the", "tall", "rich"}, Good)
+	c.Learn([]string{"the", "tall", "handsome"}, Good)
+	c.Learn([]string{"the", "poor", "bald"}, Bad)
+	c.Learn([]string{"the", "poor", "ugly"}, Bad)
+	c.ConvertTermsFreqToTfIdf()
+
+	good, bad := c.datas[Good], c.datas[Bad]
+	Assert(t, len(good.Freqs) == 1 && len(bad.Freqs) == 1, good.Freqs, bad.Freqs)
+	Assert(t, good.Freqs["tall"] > 0 && bad.Freqs["poor"] > 0)
+	Assert(t, good.Total == 2 && bad.Total == 2, good.Total, bad.Total)
+	Assert(t, len(c.TermFrequencies(Good, "the")) == 0)
+
+	_, likely, _ := c.LogScores([]string{"the", "tall"})
+	Assert(t, likely == 0)
+
+	c.RevertTfIdfConversion()
+	Assert(t, len(good.Freqs) == 4 && good.Freqs["the"] == 2 && good.Total == 6, good.Freqs)
+	Assert(t, len(good.FreqTfs["rich"]) == 1 && bad.Total == 6)
+	c.ConvertTermsFreqToTfIdf()
+	Assert(t, len(good.Freqs) == 1 && good.Total == 2, good.Freqs)
+}
+
+func TestIncrementalTfIdf(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	c.SetOptions(WithIncrementalTfIdf())
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.ConvertTermsFreqToTfIdf()
+	rich := c.datas[Good].Freqs["rich"]
+
+	c.Learn([]string{"rich", "rich", "blonde"}, Good)
+	Assert(t, c.Learned() == 3 && c.datas[Good].Total == 6)
+	Assert(t, c.datas[Good].Freqs["rich"] == rich, "weights change only on refresh")
+	Assert(t, len(c.TermFrequencies(Good, "rich")) == 2)
+
+	c.RefreshTfIdf()
+	idf := math.Log1p(3.0 / 6)
+	Assert(t, c.datas[Good].Idf == idf)
+	want := math.Log1p(1.0/3)*idf + math.Log1p(2.0/3)*idf
+	Assert(t, math.Abs(c.datas[Good].Freqs["rich"]-want) < 1e-12, c.datas[Good].Freqs["rich"], want)
+	_, likely, _ := c.LogScores([]string{"blonde"})
+	Assert(t, likely == 0)
+
+	// decayed documents count the same as before the conversion
+	c.Decay(0.5)
+	c.Learn([]string{"rich"}, Good)
+	Assert(t, c.datas[Good].Counts["rich"]*c.scale() == 2.5, c.datas[Good].Counts)
+	c.RevertTfIdfConversion()
+	Assert(t, c.datas[Good].Freqs["rich"] == 2.5 && c.datas[Good].Total == 4, c.datas[Good].Freqs, c.datas[Good].Total)
+}
+
+func TestRevertTfIdfConversion(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	c.Learn([]string{"tall", "handsome", "rich", "tall"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	c.ConvertTermsFreqToTfIdf()
+	converted := c.datas[Good].Freqs["tall"]
+
+	c.RevertTfIdfConversion()
+	Assert(t, !c.DidConvertTfIdf && c.datas[Good].Freqs["tall"] == 2)
+	Assert(t, len(c.datas[Good].FreqTfs["tall"]) == 1 && c.datas[Good].FreqTfs["tall"][0] == 0.5)
+	Assert(t, c.IDF("tall") == 0)
+
+	c.ConvertTermsFreqToTfIdf()
+	Assert(t, c.datas[Good].Freqs["tall"] == converted)
+	c.RevertTfIdfConversion()
+	c.Learn([]string{"tall"}, Good)
+	Assert(t, c.datas[Good].Freqs["tall"] == 3 && c.datas[Good].Total == 5)
+}
+
+func TestTfIdfWeighting(t *testing.T) {
+	c, _ := NewClassifierOpts([]Class{Good, Bad}, WithTfIdf(), WithTfIdfWeighting(TfRaw, IdfSmooth))
+	c.Learn([]string{"tall", "handsome", "rich", "rich"}, Good)
+	c.Learn([]string{"tall", "rich"}, Good)
+	c.Learn([]string{"bald", "poor"}, Bad)
+	c.ConvertTermsFreqToTfIdf()
+	// N = 3, rich occurs in 2 documents with tf 0.5 each
+	idf := math.Log(float64(1+3)/(1+2)) + 1
+	Assert(t, math.Abs(c.IDF("rich")-idf) < 1e-12, c.IDF("rich"))
+	Assert(t, math.Abs(c.datas[Good].Freqs["rich"]-(0.5+0.5)*idf) < 1e-12, c.datas[Good].Freqs["rich"])
+
+	d, _ := NewClassifierOpts([]Class{Good, Bad}, WithTfIdf(), WithTfIdfWeighting(TfSublinear, IdfStandard))
+	d.Learn([]string{"tall", "rich"}, Good)
+	d.Learn([]string{"tall", "poor"}, Bad)
+	d.ConvertTermsFreqToTfIdf()
+	Assert(t, d.IDF("tall") == 1 && d.IDF("rich") == math.Log(2)+1)
+	Assert(t, d.datas[Good].Freqs["rich"] == math.Log1p(0.5)*(math.Log(2)+1))
+}
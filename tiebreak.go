@@ -0,0 +1,149 @@
+package bayesian
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// TieBreak is a policy to pick the most likely class among the
+// classes tied for the highest score, see WithTieBreak.
+type TieBreak int
+
+const (
+	// TieBreakFirst picks the class declared first.
+	TieBreakFirst TieBreak = iota
+	// TieBreakRandom picks one of the classes at random, see
+	// WithRandomTieBreak.
+	TieBreakRandom
+	// TieBreakPrior picks the class with the highest prior
+	// probability, then the class declared first.
+	TieBreakPrior
+	// TieBreakName picks the class whose name sorts first.
+	TieBreakName
+)
+
+// tieBreaker holds the state of the random tie-break policy.
+type tieBreaker struct {
+	mu   sync.Mutex
+	seed int64
+	rand *rand.Rand
+}
+
+// newTieBreaker returns a tieBreaker drawing from a source with
+// the seed.
+func newTieBreaker(seed int64) *tieBreaker {
+	return &tieBreaker{seed: seed, rand: rand.New(rand.NewSource(seed))}
+}
+
+// restoreTieBreaker returns the tieBreaker of a classifier read
+// with the policy and seed, nil unless the policy is random.
+func restoreTieBreaker(policy TieBreak, seed int64) *tieBreaker {
+	if policy != TieBreakRandom {
+		return nil
+	}
+	return newTieBreaker(seed)
+}
+
+// getSeed returns the seed of the tieBreaker, 0 if it is nil.
+func (t *tieBreaker) getSeed() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.seed
+}
+
+// intn returns a random int in [0, n).
+func (t *tieBreaker) intn(n int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rand.Intn(n)
+}
+
+// tiePolicy is how the maximum of a set of scores is picked, as
+// configured with WithTieBreak and WithTieEpsilon.
+type tiePolicy struct {
+	tieBreak   TieBreak
+	tieEpsilon float64
+	tieBreaker *tieBreaker
+	classes    []Class
+	priors     func() []float64 // for TieBreakPrior
+}
+
+// tiePolicy returns the tie policy of the classifier.
+func (c *Classifier) tiePolicy() tiePolicy {
+	return tiePolicy{c.tieBreak, c.tieEpsilon, c.tieBreaker, c.Classes, c.getPriors}
+}
+
+// pickMax finds the maximum of the scores like findMax, but
+// detects ties within the tolerance set with WithTieEpsilon and
+// breaks them as configured with WithTieBreak.
+func (c *Classifier) pickMax(scores []float64) (inx int, strict bool) {
+	return c.tiePolicy().pickMax(scores)
+}
+
+// settleTie applies the tie tolerance and policy to the maximum
+// at index inx found by findMax.
+func (c *Classifier) settleTie(scores []float64, inx int, strict bool) (int, bool) {
+	return c.tiePolicy().settleTie(scores, inx, strict)
+}
+
+// ties returns the indices of the scores tied with the maximum
+// at index inx, that is within the tolerance set with
+// WithTieEpsilon of it.
+func (c *Classifier) ties(scores []float64, inx int) []int {
+	return c.tiePolicy().ties(scores, inx)
+}
+
+// pickMax works the same as Classifier.pickMax.
+func (p tiePolicy) pickMax(scores []float64) (inx int, strict bool) {
+	inx, strict = findMax(scores)
+	return p.settleTie(scores, inx, strict)
+}
+
+// settleTie works the same as Classifier.settleTie.
+func (p tiePolicy) settleTie(scores []float64, inx int, strict bool) (int, bool) {
+	if strict && p.tieEpsilon > 0 {
+		strict = len(p.ties(scores, inx)) == 1
+	}
+	if !strict {
+		inx = p.breakTie(scores, inx)
+	}
+	return inx, strict
+}
+
+// ties works the same as Classifier.ties.
+func (p tiePolicy) ties(scores []float64, inx int) (indices []int) {
+	for i, score := range scores {
+		if score == scores[inx] || scores[inx]-score <= p.tieEpsilon {
+			indices = append(indices, i)
+		}
+	}
+	return
+}
+
+// breakTie returns the index of the class picked among those
+// tied with the maximum score at index inx.
+func (p tiePolicy) breakTie(scores []float64, inx int) int {
+	tied := p.ties(scores, inx)
+	switch p.tieBreak {
+	case TieBreakRandom:
+		return tied[p.tieBreaker.intn(len(tied))]
+	case TieBreakPrior:
+		priors := p.priors()
+		best := tied[0]
+		for _, i := range tied[1:] {
+			if priors[i] > priors[best] {
+				best = i
+			}
+		}
+		return best
+	case TieBreakName:
+		for _, i := range tied {
+			if p.classes[i] < p.classes[inx] {
+				inx = i
+			}
+		}
+		return inx
+	}
+	return tied[0]
+}
@@ -0,0 +1,101 @@
+package bayesian
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTieBreak(t *testing.T) {
+	c := NewClassifier("b", "c", "a")
+	c.Learn([]string{"tall"}, "b")
+	c.Learn([]string{"tall"}, "a")
+	c.Learn([]string{"short", "dark"}, "c")
+	doc := []string{"handsome"}
+
+	_, inx, strict := c.LogScores([]string{"tall"})
+	Assert(t, inx == 0 && !strict)
+	r := c.Classify([]string{"tall"})
+	Assert(t, r.Class == "b" && len(r.Ties) == 2 && r.Ties[0] == "b" && r.Ties[1] == "a", r.Ties)
+
+	c.SetOptions(WithTieBreak(TieBreakName))
+	_, inx, strict = c.LogScores([]string{"tall"})
+	Assert(t, inx == 2 && !strict)
+
+	// 0.25*1 for b is the same as 0.5*0.5 for a
+	p := NewClassifier("b", "c", "a")
+	p.Learn([]string{"tall"}, "b")
+	p.Learn([]string{"tall", "dark"}, "a")
+	p.SetPriors(map[Class]float64{"b": 0.25, "c": 0.25, "a": 0.5})
+	_, inx, strict = p.LogScores([]string{"tall"})
+	Assert(t, inx == 0 && !strict)
+	p.SetOptions(WithTieBreak(TieBreakPrior))
+	_, inx, _ = p.LogScores([]string{"tall"})
+	Assert(t, inx == 2, inx)
+
+	c.SetOptions(WithRandomTieBreak(42), WithUniformPriors())
+	picked := make(map[int]int)
+	var first []int
+	for i := 0; i < 100; i++ {
+		_, inx, _ := c.LogScores(doc)
+		picked[inx]++
+		first = append(first, inx)
+	}
+	Assert(t, len(picked) == 3, picked)
+
+	var buf bytes.Buffer
+	Assert(t, c.WriteTo(&buf) == nil)
+	d, err := NewClassifierFromReader(&buf)
+	Assert(t, err == nil, err)
+	for i := 0; i < 100; i++ {
+		_, inx, _ := d.LogScores(doc)
+		Assert(t, inx == first[i], "not reproducible at", i)
+	}
+}
+
+func TestTieEpsilon(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.Learn([]string{"tall", "rich"}, Good)
+	c.Learn([]string{"tall", "poor", "ugly"}, Bad)
+	c.SetOptions(WithUniformPriors())
+	scores, inx, strict := c.LogScores([]string{"tall"})
+	Assert(t, inx == 0 && strict, scores)
+
+	c.SetOptions(WithTieEpsilon(0.5), WithTieBreak(TieBreakName))
+	_, inx, strict = c.LogScores([]string{"tall"})
+	Assert(t, inx == 1 && !strict, "bad sorts first")
+	r := c.Classify([]string{"tall"})
+	Assert(t, !r.Strict && len(r.Ties) == 2, r)
+	_, _, strict = c.LogScores([]string{"rich"})
+	Assert(t, strict)
+
+	// snapshots and ensembles follow the policy
+	f := c.Snapshot()
+	_, inx, strict = f.LogScores([]string{"tall"})
+	Assert(t, inx == 1 && !strict)
+	_, inx, _ = f.ProbScores([]string{"tall"})
+	Assert(t, inx == 1)
+	e, err := NewEnsemble(Member{Classifier: c, Weight: 1})
+	Assert(t, err == nil, err)
+	_, inx, strict = e.LogScores([]string{"tall"})
+	Assert(t, inx == 1 && !strict)
+}
+
+func TestTieEpsilonDeclaredFirst(t *testing.T) {
+	// the near-tied class at index 0 is declared first
+	scores := []float64{-1.001, -1.0}
+	p := tiePolicy{tieEpsilon: 0.01, classes: []Class{"b", "a"}}
+	inx, strict := p.pickMax(scores)
+	Assert(t, inx == 0 && !strict, inx)
+
+	p.tieBreak = TieBreakPrior
+	p.priors = func() []float64 { return []float64{0.5, 0.5} }
+	inx, _ = p.pickMax(scores)
+	Assert(t, inx == 0, inx)
+	p.priors = func() []float64 { return []float64{0.4, 0.6} }
+	inx, _ = p.pickMax(scores)
+	Assert(t, inx == 1, inx)
+
+	p.tieBreak = TieBreakName
+	inx, _ = p.pickMax(scores)
+	Assert(t, inx == 1, inx)
+}
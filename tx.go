@@ -0,0 +1,63 @@
+package bayesian
+
+import "errors"
+
+// ErrTxDone is returned when using a Tx that was already
+// committed or rolled back.
+var ErrTxDone = errors.New("transaction already committed or rolled back")
+
+// Tx is a training transaction, started with c.Begin(). The
+// documents learned in it are buffered, and only learned by the
+// classifier on Commit, so that a failed bulk import does not
+// leave the classifier half trained.
+type Tx struct {
+	c    *Classifier
+	docs []LabeledDoc
+	done bool
+}
+
+// Begin starts a training transaction.
+func (c *Classifier) Begin() *Tx {
+	return &Tx{c: c}
+}
+
+// Learn buffers the document to be learned as a document of the
+// class on Commit. It returns ErrClassNotFound if the classifier
+// does not have the class, in which case the import would
+// usually be rolled back.
+func (tx *Tx) Learn(document []string, class Class) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	if _, ok := tx.c.datas[class]; !ok && !tx.c.autoCreateClasses {
+		return ErrClassNotFound
+	}
+	tx.docs = append(tx.docs, LabeledDoc{document, class})
+	return nil
+}
+
+// Commit learns the buffered documents and ends the transaction.
+// It returns ErrConverted, without learning anything, for a
+// TF-IDF classifier that was already converted.
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	if tx.c.tfIdf && tx.c.DidConvertTfIdf && !tx.c.incrementalTfIdf {
+		return ErrConverted
+	}
+	tx.done = true
+	for _, doc := range tx.docs {
+		tx.c.learn(doc.Document, doc.Class)
+	}
+	tx.docs = nil
+	tx.c.invalidate()
+	return nil
+}
+
+// Rollback discards the buffered documents and ends the
+// transaction.
+func (tx *Tx) Rollback() {
+	tx.done = true
+	tx.docs = nil
+}
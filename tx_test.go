@@ -0,0 +1,21 @@
+package bayesian
+
+import "testing"
+
+func TestTx(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	tx := c.Begin()
+	Assert(t, tx.Learn([]string{"tall", "rich"}, Good) == nil)
+	Assert(t, tx.Learn([]string{"ugly"}, "Ugly") == ErrClassNotFound)
+	tx.Rollback()
+	Assert(t, c.Learned() == 0 && c.datas[Good].Total == 0)
+	Assert(t, tx.Learn([]string{"tall"}, Good) == ErrTxDone)
+
+	tx = c.Begin()
+	tx.Learn([]string{"tall", "rich"}, Good)
+	tx.Learn([]string{"poor"}, Bad)
+	Assert(t, c.Learned() == 0)
+	Assert(t, tx.Commit() == nil)
+	Assert(t, c.Learned() == 2 && c.datas[Good].Total == 2)
+	Assert(t, tx.Commit() == ErrTxDone)
+}
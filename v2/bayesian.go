@@ -0,0 +1,126 @@
+// Package bayesian is the second version of the API of the
+// Naive Bayesian Classifier of github.com/jbrukh/bayesian. It
+// returns errors instead of panicking, configures classifiers
+// with options, and returns the outcome of a classification as
+// a Result rather than as several values. Classifiers wrap the
+// ones of the first version, so both APIs can be used on the
+// same model during a migration.
+package bayesian
+
+import (
+	"errors"
+	"io"
+
+	v1 "github.com/jbrukh/bayesian"
+)
+
+// Class is a class of documents, see v1.Class.
+type Class = v1.Class
+
+// Option configures optional behavior of a Classifier, see
+// v1.Option.
+type Option = v1.Option
+
+// ErrNotConverted is returned when classifying with a TF-IDF
+// classifier before calling ConvertTermsFreqToTfIdf.
+var ErrNotConverted = errors.New("TF-IDF classifier not converted")
+
+// ErrNoEligibleClass is returned when classifying while no class
+// can be scored, see v1.ErrNoEligibleClass.
+var ErrNoEligibleClass = v1.ErrNoEligibleClass
+
+// Classifier is a Naive Bayesian Classifier.
+type Classifier struct {
+	c *v1.Classifier
+}
+
+// Result is the outcome of a classification, see v1.Result.
+type Result = v1.Result
+
+// New returns a classifier of the given classes, configured with
+// the options. It returns an error if there are fewer than two
+// classes, or if they are not unique, see v1.NewClassifierOpts.
+func New(classes []Class, opts ...Option) (*Classifier, error) {
+	c, err := v1.NewClassifierOpts(classes, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Classifier{c}, nil
+}
+
+// NewTfIdf works the same as New, but returns a TF-IDF
+// classifier.
+func NewTfIdf(classes []Class, opts ...Option) (*Classifier, error) {
+	c, err := v1.NewClassifierOpts(classes, append([]Option{v1.WithTfIdf()}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &Classifier{c}, nil
+}
+
+// Wrap returns a classifier for the first version classifier c,
+// which remains usable.
+func Wrap(c *v1.Classifier) *Classifier {
+	return &Classifier{c}
+}
+
+// Load reads a classifier written with Save, or with WriteTo
+// of the first version.
+func Load(r io.Reader) (*Classifier, error) {
+	c, err := v1.NewClassifierFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Classifier{c}, nil
+}
+
+// Unwrap returns the first version classifier c wraps.
+func (c *Classifier) Unwrap() *v1.Classifier {
+	return c.c
+}
+
+// Classes returns the classes of the classifier.
+func (c *Classifier) Classes() []Class {
+	return append([]Class(nil), c.c.Classes...)
+}
+
+// Learn learns the document as a document of the class. It
+// returns the errors of v1.Classifier.LearnErr.
+func (c *Classifier) Learn(document []string, class Class) error {
+	return c.c.LearnErr(document, class)
+}
+
+// Unlearn reverses a call to Learn, see v1.Classifier.Unlearn.
+func (c *Classifier) Unlearn(document []string, class Class) error {
+	return c.c.Unlearn(document, class)
+}
+
+// ConvertTermsFreqToTfIdf converts the counts of a TF-IDF
+// classifier once it has learned its documents. It returns
+// v1.ErrConverted if it was already converted.
+func (c *Classifier) ConvertTermsFreqToTfIdf() error {
+	if c.c.DidConvertTfIdf {
+		return v1.ErrConverted
+	}
+	c.c.ConvertTermsFreqToTfIdf()
+	return nil
+}
+
+// Classify classifies the document. It returns ErrNotConverted
+// for a TF-IDF classifier that was not converted yet, and
+// ErrNoEligibleClass if no class can be scored.
+func (c *Classifier) Classify(document []string) (Result, error) {
+	if c.c.IsTfIdf() && !c.c.DidConvertTfIdf {
+		return Result{}, ErrNotConverted
+	}
+	result := c.c.Classify(document)
+	if result.Index < 0 {
+		return result, ErrNoEligibleClass
+	}
+	return result, nil
+}
+
+// Save writes the classifier to w, see v1.Classifier.WriteTo.
+func (c *Classifier) Save(w io.Writer) error {
+	return c.c.WriteTo(w)
+}
@@ -0,0 +1,91 @@
+package bayesian
+
+import "testing"
+import "bytes"
+import "errors"
+import "math"
+
+import v1 "github.com/jbrukh/bayesian"
+
+const (
+	Good Class = "good"
+	Bad  Class = "bad"
+)
+
+func TestClassify(t *testing.T) {
+	_, err := New([]Class{Good})
+	if !errors.Is(err, v1.ErrTooFewClasses) {
+		t.Fatal(err)
+	}
+	c, err := New([]Class{Good, Bad}, v1.WithAdaptiveDefaultProb())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Learn([]string{"tall", "handsome", "rich"}, Good); err != nil {
+		t.Fatal(err)
+	}
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	if err := c.Learn([]string{"blonde"}, "Ugly"); err != v1.ErrClassNotFound {
+		t.Fatal(err)
+	}
+	result, err := c.Classify([]string{"tall", "girl"})
+	if err != nil || result.Class != Good || result.Index != 0 || !result.Strict {
+		t.Fatal(result, err)
+	}
+	if math.Abs(result.Probabilities[0]+result.Probabilities[1]-1) > 1e-12 {
+		t.Fatal(result.Probabilities)
+	}
+
+	untrained, _ := New([]Class{Good, Bad}, v1.WithMinClassDocs(1))
+	if _, err := untrained.Classify([]string{"tall"}); err != ErrNoEligibleClass {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil || loaded.Unwrap().Learned() != 2 {
+		t.Fatal(err)
+	}
+}
+
+func TestTfIdf(t *testing.T) {
+	c, _ := NewTfIdf([]Class{Good, Bad})
+	c.Learn([]string{"tall", "handsome", "rich"}, Good)
+	c.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	if _, err := c.Classify([]string{"tall"}); err != ErrNotConverted {
+		t.Fatal(err)
+	}
+	if err := c.ConvertTermsFreqToTfIdf(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Learn([]string{"tall"}, Good); err != v1.ErrConverted {
+		t.Fatal(err)
+	}
+	if result, err := c.Classify([]string{"tall"}); err != nil || result.Class != Good {
+		t.Fatal(result, err)
+	}
+}
+
+func TestLearnOptions(t *testing.T) {
+	c, _ := New([]Class{Good, Bad}, v1.WithAutoCreateClasses())
+	if err := c.Learn([]string{"blonde"}, "Ugly"); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Classes()) != 3 {
+		t.Fatal(c.Classes())
+	}
+	if err := c.Unlearn([]string{"blonde"}, "Pretty"); err != v1.ErrClassNotFound {
+		t.Fatal(err)
+	}
+
+	d, _ := NewTfIdf([]Class{Good, Bad}, v1.WithIncrementalTfIdf())
+	d.Learn([]string{"tall", "handsome", "rich"}, Good)
+	d.Learn([]string{"bald", "poor", "ugly"}, Bad)
+	d.ConvertTermsFreqToTfIdf()
+	if err := d.Learn([]string{"tall"}, Good); err != nil {
+		t.Fatal(err)
+	}
+}
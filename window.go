@@ -0,0 +1,259 @@
+package bayesian
+
+import (
+	"math"
+	"time"
+)
+
+// windowEntry is a learned document remembered by a classifier
+// in sliding-window mode.
+type windowEntry struct {
+	counts   map[string]int     // occurrences of each word, after capping
+	amounts  map[string]float64 // added to each word count per unit of weight, if not counts
+	length   float64
+	class    Class
+	weight   float64
+	learned  time.Time
+	features *Features // side features, see LearnWithFeatures
+}
+
+// serializableWindowEntry is the gob representation of a
+// windowEntry.
+type serializableWindowEntry struct {
+	Counts   map[string]int
+	Amounts  map[string]float64
+	Length   float64
+	Class    Class
+	Weight   float64
+	Learned  time.Time
+	Features *Features
+}
+
+// serializableWindow returns the gob representation of the
+// remembered documents.
+func (c *Classifier) serializableWindow() []serializableWindowEntry {
+	if len(c.window) == 0 {
+		return nil
+	}
+	entries := make([]serializableWindowEntry, len(c.window))
+	for i, entry := range c.window {
+		entries[i] = serializableWindowEntry{
+			Counts:   entry.counts,
+			Amounts:  entry.amounts,
+			Length:   entry.length,
+			Class:    entry.class,
+			Weight:   entry.weight,
+			Learned:  entry.learned,
+			Features: entry.features,
+		}
+	}
+	return entries
+}
+
+// restoreWindow returns the remembered documents of their gob
+// representation.
+func restoreWindow(entries []serializableWindowEntry) []windowEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	window := make([]windowEntry, len(entries))
+	for i, entry := range entries {
+		window[i] = windowEntry{
+			counts:   entry.Counts,
+			amounts:  entry.Amounts,
+			length:   entry.Length,
+			class:    entry.Class,
+			weight:   entry.Weight,
+			learned:  entry.Learned,
+			features: entry.Features,
+		}
+	}
+	return window
+}
+
+// WithSlidingWindow makes the classifier remember the documents
+// it learns, and remove the contribution of a document again
+// once more than size newer documents were learned, or once it
+// was learned longer than ttl ago. A size or ttl of 0 means no
+// limit. Expired documents are removed when documents are
+// learned and when c.Expire() is called. Documents expired from
+// a converted TF-IDF classifier are removed from its raw counts
+// and TF samples, and its terms are weighed again as by
+// c.RefreshTfIdf(). The window and the remembered documents are
+// serialized with the classifier, so that they go on expiring
+// once it is loaded.
+func WithSlidingWindow(size int, ttl time.Duration) Option {
+	return func(c *Classifier) {
+		c.windowSize = size
+		c.windowTTL = ttl
+		c.windowed = size > 0 || ttl > 0
+	}
+}
+
+// remember adds a learned document to the window and removes
+// the documents that fall out of it.
+func (c *Classifier) remember(entry windowEntry) {
+	if !c.windowed {
+		return
+	}
+	entry.learned = c.now()
+	c.window = append(c.window, entry)
+	c.expire()
+}
+
+// Expire removes the contribution of the documents that fell
+// out of the sliding window; see WithSlidingWindow.
+func (c *Classifier) Expire() {
+	c.expire()
+	c.invalidate()
+}
+
+// expire implements Expire without invalidating the statistics
+// cached from the counts.
+func (c *Classifier) expire() {
+	cutoff := c.now().Add(-c.windowTTL)
+	n := 0
+	for n < len(c.window) {
+		entry := c.window[n]
+		if (c.windowSize <= 0 || len(c.window)-n <= c.windowSize) &&
+			(c.windowTTL <= 0 || !entry.learned.Before(cutoff)) {
+			break
+		}
+		c.forget(entry)
+		n++
+	}
+	if n > 0 {
+		c.window = append(c.window[:0], c.window[n:]...)
+		if c.tfIdf && c.DidConvertTfIdf {
+			c.weighTfIdf()
+		}
+	}
+}
+
+// unremember drops the oldest remembered document of the class
+// with the counts, for when it was unlearned.
+func (c *Classifier) unremember(counts map[string]int, class Class) {
+	if i := c.remembered(counts, class); i >= 0 {
+		c.window = append(c.window[:i], c.window[i+1:]...)
+	}
+}
+
+// remembered returns the index in the window of the oldest
+// remembered document of the class with the counts, or -1.
+func (c *Classifier) remembered(counts map[string]int, class Class) int {
+	for i, entry := range c.window {
+		if entry.class == class && entry.counts != nil && sameCounts(entry.counts, counts) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameCounts returns whether the word counts are the same.
+func sameCounts(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for word, cnt := range a {
+		if b[word] != cnt {
+			return false
+		}
+	}
+	return true
+}
+
+// forgetClass drops the remembered documents of a class without
+// subtracting them, for when its counts were discarded.
+func (c *Classifier) forgetClass(class Class) {
+	window := c.window[:0]
+	for _, entry := range c.window {
+		if entry.class != class {
+			window = append(window, entry)
+		}
+	}
+	c.window = window
+}
+
+// forget subtracts a remembered document from the counts. Counts
+// are not allowed to become negative, since they may have been
+// changed since the document was learned.
+func (c *Classifier) forget(entry windowEntry) {
+	data := c.datas[entry.class]
+	amounts := entry.amounts
+	if amounts == nil {
+		amounts = make(map[string]float64, len(entry.counts))
+		for word, cnt := range entry.counts {
+			amounts[word] = float64(cnt)
+		}
+	}
+	for word, amount := range amounts {
+		if c.tfIdf && c.DidConvertTfIdf {
+			c.forgetRaw(data, word, entry)
+			continue
+		}
+		if c.tfIdf && entry.counts != nil {
+			data.FreqTfs[word] = removeSample(data.FreqTfs[word], float64(entry.counts[word])/entry.length)
+			if len(data.FreqTfs[word]) == 0 {
+				delete(data.FreqTfs, word)
+			}
+		}
+		delta := amount * entry.weight
+		if have := data.Freqs[word]; have <= delta {
+			delta = have
+			delete(data.Freqs, word)
+			delete(data.Updated, word)
+		} else {
+			data.Freqs[word] -= delta
+			c.touch(data, word)
+		}
+		data.Total -= delta
+		c.forgetDocFreq(word)
+	}
+	if c.idfWeighting && c.idfDocs > 0 {
+		c.idfDocs--
+	}
+	if data.Docs > 0 {
+		data.Docs--
+	}
+	data.Length = math.Max(data.Length-entry.length, 0)
+	if entry.features != nil {
+		removeFeatures(data, *entry.features)
+	}
+	if entry.counts != nil && entry.amounts != nil {
+		// learned with BM25
+		c.unlearnBM25(entry.length)
+	}
+	c.learned--
+}
+
+// forgetRaw subtracts a word of a remembered document from the
+// raw counts and TF samples of a converted TF-IDF classifier,
+// which hold the documents learned before and after the
+// conversion. The TF-IDF weights are recomputed by expire.
+func (c *Classifier) forgetRaw(data *classData, word string, entry windowEntry) {
+	cnt := float64(entry.counts[word])
+	data.Tfs[word] = removeSample(data.Tfs[word], cnt/entry.length)
+	if _, dropped := data.Dropped[word]; dropped {
+		data.Dropped[word] = math.Max(data.Dropped[word]-cnt*entry.weight, 0)
+	} else {
+		data.Total -= math.Min(cnt*entry.weight, data.Total)
+	}
+	data.Counts[word] -= cnt * entry.weight
+	if len(data.Tfs[word]) == 0 || data.Counts[word] <= cnt*entry.weight*unlearnTolerance {
+		delete(data.Tfs, word)
+		delete(data.Counts, word)
+		delete(data.Dropped, word)
+		delete(data.Freqs, word)
+		delete(data.FreqTfs, word)
+		delete(data.Updated, word)
+	}
+	c.forgetDocFreq(word)
+}
+
+// forgetDocFreq removes a forgotten document from the document
+// frequency of the word, if IDF weighting is enabled.
+func (c *Classifier) forgetDocFreq(word string) {
+	if c.idfWeighting && c.docFreqs[word] > 0 {
+		c.docFreqs[word]--
+	}
+}
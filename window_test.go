@@ -0,0 +1,124 @@
+package bayesian
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithSlidingWindow(2, 0))
+	c.Learn([]string{"tall", "rich"}, Good)
+	c.Learn([]string{"poor"}, Bad)
+	c.Learn([]string{"tall"}, Good)
+	Assert(t, c.Learned() == 2 && len(c.window) == 2)
+	Assert(t, c.datas[Good].Freqs["tall"] == 1 && c.datas[Good].Total == 1 && c.datas[Good].Docs == 1)
+	_, ok := c.datas[Good].Freqs["rich"]
+	Assert(t, !ok, "rich should be forgotten")
+}
+
+func TestSlidingWindowUnlearn(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithSlidingWindow(2, 0))
+	c.Learn([]string{"tall", "rich"}, Good)
+	Assert(t, c.Unlearn([]string{"rich", "tall"}, Good) == nil)
+	Assert(t, len(c.window) == 0)
+	c.Learn([]string{"tall", "rich"}, Good)
+	c.Learn([]string{"tall"}, Good)
+	c.Learn([]string{"rich"}, Good)
+	Assert(t, c.Learned() == 2 && c.datas[Good].Total == 2)
+	Assert(t, c.datas[Good].Freqs["tall"] == 1 && c.datas[Good].Freqs["rich"] == 1)
+}
+
+func TestSlidingWindowTTL(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClassifierTfIdf(Good, Bad)
+	c.now = func() time.Time { return now }
+	c.SetOptions(WithSlidingWindow(0, time.Hour))
+	c.Learn([]string{"tall", "rich"}, Good)
+	now = now.Add(30 * time.Minute)
+	c.Learn([]string{"tall"}, Good)
+	now = now.Add(45 * time.Minute)
+	c.Expire()
+	Assert(t, c.Learned() == 1 && c.datas[Good].Total == 1)
+	tfs := c.datas[Good].FreqTfs["tall"]
+	Assert(t, len(tfs) == 1 && tfs[0] == 1, tfs)
+	_, ok := c.datas[Good].FreqTfs["rich"]
+	Assert(t, !ok)
+}
+
+func TestSlidingWindowClasses(t *testing.T) {
+	const Ugly Class = "ugly"
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithSlidingWindow(2, 0))
+	c.Learn([]string{"tall"}, Good)
+	c.Learn([]string{"poor"}, Bad)
+	err := c.RenameClass(Good, Ugly)
+	Assert(t, err == nil, err)
+	c.Learn([]string{"rich"}, Bad)
+	_, ok := c.datas[Ugly].Freqs["tall"]
+	Assert(t, !ok && c.datas[Ugly].Total == 0, "renamed class should forget")
+
+	c.Reset()
+	c.Learn([]string{"tall"}, Ugly)
+	Assert(t, c.Learned() == 1 && c.datas[Ugly].Total == 1, "reset should clear the window")
+
+	c.Learn([]string{"poor"}, Bad)
+	err = c.ResetClass(Ugly)
+	Assert(t, err == nil, err)
+	c.Learn([]string{"rich"}, Bad)
+	Assert(t, c.Learned() == 2 && c.datas[Bad].Total == 2, "reset class should clear its documents")
+}
+
+func TestSlidingWindowTfIdf(t *testing.T) {
+	c := NewClassifierTfIdf(Good, Bad)
+	c.SetOptions(WithSlidingWindow(2, 0))
+	c.Learn([]string{"tall", "rich"}, Good)
+	c.Learn([]string{"tall", "blonde"}, Good)
+	c.ConvertTermsFreqToTfIdf()
+	c.SetOptions(WithSlidingWindow(1, 0))
+	c.Expire()
+	data := c.datas[Good]
+	Assert(t, c.Learned() == 1 && data.Total == 2, data.Total)
+	Assert(t, len(data.Tfs["tall"]) == 1 && len(data.FreqTfs["tall"]) == 1, data.FreqTfs)
+	Assert(t, data.Counts["tall"] == 1 && data.Freqs["tall"] > 0 && data.Freqs["blonde"] > 0, data.Freqs)
+	_, ok := data.Freqs["rich"]
+	Assert(t, !ok, "rich should be forgotten")
+	Assert(t, data.Freqs["tall"] == data.Freqs["blonde"], data.Freqs)
+
+	// documents learned after the conversion expire as well
+	d := NewClassifierTfIdf(Good, Bad)
+	d.SetOptions(WithSlidingWindow(1, 0), WithIncrementalTfIdf())
+	d.Learn([]string{"tall", "rich"}, Good)
+	d.ConvertTermsFreqToTfIdf()
+	d.Learn([]string{"poor"}, Bad)
+	d.Learn([]string{"ugly"}, Bad)
+	Assert(t, d.Learned() == 1 && len(d.window) == 1)
+	Assert(t, len(d.datas[Good].Freqs) == 0 && d.datas[Good].Total == 0, d.datas[Good].Freqs)
+	bad := d.datas[Bad]
+	_, ok = bad.Counts["poor"]
+	Assert(t, !ok && bad.Counts["ugly"] == 1 && bad.Freqs["ugly"] > 0, bad.Counts)
+}
+
+func TestSlidingWindowPersistence(t *testing.T) {
+	c := NewClassifier(Good, Bad)
+	c.SetOptions(WithSlidingWindow(2, time.Hour))
+	c.Learn([]string{"tall", "rich"}, Good)
+	c.Learn([]string{"poor"}, Bad)
+	var gobBuf, msgpackBuf bytes.Buffer
+	Assert(t, c.WriteTo(&gobBuf) == nil)
+	Assert(t, c.WriteMsgpack(&msgpackBuf) == nil)
+	fromGob, err := NewClassifierFromReader(&gobBuf)
+	Assert(t, err == nil, err)
+	fromMsgpack, err := NewClassifierFromMsgpack(&msgpackBuf)
+	Assert(t, err == nil, err)
+	for _, d := range []*Classifier{fromGob, fromMsgpack} {
+		Assert(t, d.windowSize == 2 && d.windowTTL == time.Hour && len(d.window) == 2, d.windowSize, d.windowTTL)
+		Assert(t, d.window[0].learned.Equal(c.window[0].learned))
+		d.Learn([]string{"tall"}, Good)
+		Assert(t, d.Learned() == 2 && d.datas[Good].Total == 1, d.datas[Good].Total)
+		_, ok := d.datas[Good].Freqs["rich"]
+		Assert(t, !ok, "rich should be forgotten")
+	}
+}
@@ -0,0 +1,125 @@
+package bayesian
+
+import (
+	"errors"
+	"math"
+)
+
+// WindowedClassifier is a classifier for streams of documents
+// whose vocabulary changes over time. It keeps a separate model
+// for each of the most recent time windows (e.g., the last 7
+// days), learns into the newest one, and classifies against a
+// weighted combination of their counts. Call Rotate at the end
+// of each window to start a new one and discard the oldest.
+type WindowedClassifier struct {
+	Classes []Class
+	windows []*Classifier // oldest first
+	weights []float64     // newest first
+	opts    []Option      // options of new windows
+}
+
+// NewWindowedClassifier returns a new windowed classifier that
+// keeps the given number of windows. The classes provided
+// should be at least 2 in number and unique, or this method
+// will panic. All windows are weighted equally until
+// SetWeights is called.
+func NewWindowedClassifier(size int, classes ...Class) (w *WindowedClassifier) {
+	w, err := NewWindowedClassifierOpts(size, classes)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// NewWindowedClassifierOpts works the same as
+// NewWindowedClassifier, but configures the model of each
+// window, including those started by Rotate, with the options,
+// and returns the errors of NewClassifierOpts instead of
+// panicking.
+func NewWindowedClassifierOpts(size int, classes []Class, opts ...Option) (w *WindowedClassifier, err error) {
+	if size < 1 {
+		return nil, errors.New("provide at least one window")
+	}
+	w = &WindowedClassifier{
+		Classes: classes,
+		windows: make([]*Classifier, size),
+		weights: make([]float64, size),
+		opts:    opts,
+	}
+	for i := range w.windows {
+		if w.windows[i], err = NewClassifierOpts(classes, opts...); err != nil {
+			return nil, err
+		}
+		w.weights[i] = 1
+	}
+	return w, nil
+}
+
+// SetWeights sets the weight of the counts of each window,
+// starting with the newest window; windows without a weight
+// get weight 0.
+func (w *WindowedClassifier) SetWeights(weights ...float64) {
+	for i := range w.weights {
+		w.weights[i] = 0
+		if i < len(weights) {
+			w.weights[i] = weights[i]
+		}
+	}
+}
+
+// Learn learns the document into the newest window.
+func (w *WindowedClassifier) Learn(document []string, which Class) {
+	w.windows[len(w.windows)-1].Learn(document, which)
+}
+
+// Rotate discards the oldest window and starts a new, empty
+// one, which becomes the window that documents are learned to.
+func (w *WindowedClassifier) Rotate() {
+	copy(w.windows, w.windows[1:])
+	// the options were accepted by the constructor
+	w.windows[len(w.windows)-1], _ = NewClassifierOpts(w.Classes, w.opts...)
+}
+
+// Windows returns the models of the windows, oldest first.
+func (w *WindowedClassifier) Windows() []*Classifier {
+	return append([]*Classifier(nil), w.windows...)
+}
+
+// LogScores works the same as c.LogScores, with the word counts
+// and totals of each class being the weighted sums of those in
+// the windows. The classes are equally likely a priori while
+// the windows have no weighted counts, for instance after
+// construction or once all weights are 0.
+func (w *WindowedClassifier) LogScores(document []string) (scores []float64, inx int, strict bool) {
+	n, size := len(w.Classes), len(w.windows)
+	scores = make([]float64, n, n)
+	totals := make([]float64, n, n)
+	sum := float64(0)
+	for index, class := range w.Classes {
+		for k, window := range w.windows {
+			totals[index] += w.weights[size-1-k] * window.classTotal(class)
+		}
+		sum += totals[index]
+	}
+	for index, class := range w.Classes {
+		prior := 1 / float64(n)
+		if sum > 0 {
+			prior = totals[index] / sum
+		}
+		score := math.Log(prior)
+		for _, word := range document {
+			freq := float64(0)
+			for k, window := range w.windows {
+				freq += w.weights[size-1-k] * window.datas[class].Freqs[word] * window.scale()
+			}
+			p := defaultProb
+			if freq > 0 {
+				p = freq / totals[index]
+			}
+			score += math.Log(p)
+		}
+		scores[index] = score
+	}
+	inx, strict = findMax(scores)
+	return scores, inx, strict
+}
@@ -0,0 +1,57 @@
+package bayesian
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWindowedClassifier(t *testing.T) {
+	w := NewWindowedClassifier(2, Good, Bad)
+	w.Learn([]string{"tall", "rich"}, Good)
+	w.Learn([]string{"poor", "ugly"}, Bad)
+	w.Rotate()
+	w.Learn([]string{"poor", "nice"}, Good)
+	w.Learn([]string{"tall", "mean"}, Bad)
+
+	// both windows count equally
+	scores, _, strict := w.LogScores([]string{"tall", "poor"})
+	Assert(t, scores[0] == scores[1] && !strict, scores)
+
+	// the newest window dominates
+	w.SetWeights(1, 0.1)
+	_, likely, strict := w.LogScores([]string{"poor"})
+	Assert(t, likely == 0 && strict)
+
+	// the oldest window is forgotten
+	w.SetWeights(1, 1)
+	w.Rotate()
+	w.Learn([]string{"happy"}, Good)
+	_, likely, _ = w.LogScores([]string{"ugly", "poor"})
+	Assert(t, likely == 0)
+	Assert(t, len(w.Windows()) == 2)
+}
+
+func TestWindowedClassifierEmpty(t *testing.T) {
+	w, err := NewWindowedClassifierOpts(2, []Class{Good, Bad}, WithSlidingWindow(1, 0))
+	Assert(t, err == nil, err)
+	scores, _, strict := w.LogScores([]string{"tall"})
+	Assert(t, !math.IsNaN(scores[0]) && scores[0] == scores[1] && !strict, scores)
+
+	w.Learn([]string{"tall"}, Good)
+	w.SetWeights(0, 0)
+	scores, _, _ = w.LogScores([]string{"tall"})
+	Assert(t, !math.IsNaN(scores[0]) && !math.IsNaN(scores[1]), scores)
+
+	// new windows get the options
+	w.Rotate()
+	Assert(t, w.Windows()[1].windowSize == 1)
+	w.Rotate()
+	scores, _, _ = w.LogScores([]string{"tall"})
+	Assert(t, !math.IsNaN(scores[0]), scores)
+
+	_, err = NewWindowedClassifierOpts(0, []Class{Good, Bad})
+	Assert(t, err != nil)
+	_, err = NewWindowedClassifierOpts(1, []Class{Good})
+	Assert(t, errors.Is(err, ErrTooFewClasses), err)
+}